@@ -55,7 +55,7 @@ type Account struct {
 	NTx                int                 `json:"n_tx"`
 	NDelegation        int                 `json:"n_delegation"`
 	NOrigination       int                 `json:"n_origination"`
-	NConstants         int                 `json:"n_contstants"`
+	NConstants         int                 `json:"n_contstants" brief:"n_constants"`
 	TokenGenMin        int64               `json:"token_gen_min"`
 	TokenGenMax        int64               `json:"token_gen_max"`
 	LifetimeRewards    float64             `json:"lifetime_rewards,omitempty"`
@@ -114,8 +114,8 @@ func (a *Account) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return a.UnmarshalJSONBrief(data)
 	}
-	type Alias *Account
-	return json.Unmarshal(data, Alias(a))
+	type Alias Account
+	return json.Unmarshal(data, (*Alias)(a))
 }
 
 func (a *Account) UnmarshalJSONBrief(data []byte) error {
@@ -127,136 +127,15 @@ func (a *Account) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, a.columns); err != nil {
+		return err
+	}
 	for i, v := range a.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			acc.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "address":
-			acc.Address, err = tezos.ParseAddress(f.(string))
-		case "address_type":
-			acc.AddressType = tezos.ParseAddressType(f.(string))
-		case "pubkey":
-			acc.Pubkey, err = tezos.ParseKey(f.(string))
-		case "counter":
-			acc.Counter, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "baker_id":
-			acc.BakerId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "baker":
-			var a tezos.Address
-			a, err = tezos.ParseAddress(f.(string))
-			if err == nil {
-				acc.Baker = &a
-			}
-		case "creator_id":
-			acc.CreatorId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "creator":
-			var a tezos.Address
-			a, err = tezos.ParseAddress(f.(string))
-			if err == nil {
-				acc.Creator = &a
-			}
-		case "first_in":
-			acc.FirstIn, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "first_out":
-			acc.FirstOut, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "first_seen":
-			acc.FirstSeen, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "last_in":
-			acc.LastIn, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "last_out":
-			acc.LastOut, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "last_seen":
-			acc.LastSeen, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "delegated_since":
-			acc.DelegatedSince, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_received":
-			acc.TotalReceived, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "total_sent":
-			acc.TotalSent, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "total_burned":
-			acc.TotalBurned, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "total_fees_paid":
-			acc.TotalFeesPaid, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "unclaimed_balance":
-			acc.UnclaimedBalance, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "spendable_balance":
-			acc.SpendableBalance, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "is_funded":
-			acc.IsFunded, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_activated":
-			acc.IsActivated, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_delegated":
-			acc.IsDelegated, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_revealed":
-			acc.IsRevealed, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_baker":
-			acc.IsBaker, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_contract":
-			acc.IsContract, err = strconv.ParseBool(f.(json.Number).String())
-		case "n_ops":
-			acc.NOps, err = strconv.Atoi(f.(json.Number).String())
-		case "n_ops_failed":
-			acc.NOpsFailed, err = strconv.Atoi(f.(json.Number).String())
-		case "n_tx":
-			acc.NTx, err = strconv.Atoi(f.(json.Number).String())
-		case "n_delegation":
-			acc.NDelegation, err = strconv.Atoi(f.(json.Number).String())
-		case "n_origination":
-			acc.NOrigination, err = strconv.Atoi(f.(json.Number).String())
-		case "n_constants":
-			acc.NConstants, err = strconv.Atoi(f.(json.Number).String())
-		case "token_gen_min":
-			acc.TokenGenMin, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "token_gen_max":
-			acc.TokenGenMax, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "first_seen_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.FirstSeenTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "last_seen_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.LastSeenTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "first_in_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.FirstInTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "last_in_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.LastInTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "first_out_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.FirstOutTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "last_out_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.LastOutTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "delegated_since_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				acc.DelegatedSinceTime = time.Unix(0, ts*1000000).UTC()
-			}
-		}
-		if err != nil {
+		if _, err := acc.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
@@ -273,27 +152,27 @@ func NewAccountParams() AccountParams {
 }
 
 func (p AccountParams) WithLimit(v uint) AccountParams {
-	p.Query.Set("limit", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p AccountParams) WithOffset(v uint) AccountParams {
-	p.Query.Set("offset", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p AccountParams) WithCursor(v uint64) AccountParams {
-	p.Query.Set("cursor", strconv.FormatUint(v, 10))
+	p.Params = p.Params.With("cursor", strconv.FormatUint(v, 10))
 	return p
 }
 
 func (p AccountParams) WithOrder(v OrderType) AccountParams {
-	p.Query.Set("order", string(v))
+	p.Params = p.Params.With("order", string(v))
 	return p
 }
 
 func (p AccountParams) WithMeta() AccountParams {
-	p.Query.Set("meta", "1")
+	p.Params = p.Params.With("meta", "1")
 	return p
 }
 
@@ -349,6 +228,43 @@ func (c *Client) GetAccount(ctx context.Context, addr tezos.Address, params Acco
 	return a, nil
 }
 
+// GetPublicKey returns the revealed public key of an implicit account.
+// Revealed keys never change and are cached by the client separately from
+// contract scripts. Returns an invalid key when the account has not been
+// revealed.
+func (c *Client) GetPublicKey(ctx context.Context, addr tezos.Address) (tezos.Key, error) {
+	ckey := addr.String()
+	if c.keys != nil {
+		if k, ok := c.keys.Get(ckey); ok {
+			c.logDebug("cache hit", "cache", "pubkey", "key", addr.String())
+			return k.(tezos.Key), nil
+		}
+	}
+	a, err := c.GetAccount(ctx, addr, NewAccountParams())
+	if err != nil {
+		if ErrorStatus(err) == 404 {
+			return tezos.Key{}, nil
+		}
+		return tezos.Key{}, err
+	}
+	if !a.IsRevealed || !a.Pubkey.IsValid() {
+		return tezos.Key{}, nil
+	}
+	if c.keys != nil {
+		c.keys.Add(ckey, a.Pubkey)
+	}
+	return a.Pubkey, nil
+}
+
+// IsRevealed reports whether an account has revealed its public key on chain.
+func (c *Client) IsRevealed(ctx context.Context, addr tezos.Address) (bool, error) {
+	k, err := c.GetPublicKey(ctx, addr)
+	if err != nil {
+		return false, err
+	}
+	return k.IsValid(), nil
+}
+
 func (c *Client) GetAccountContracts(ctx context.Context, addr tezos.Address, params AccountParams) ([]*Account, error) {
 	cc := make([]*Account, 0)
 	u := params.AppendQuery(fmt.Sprintf("/explorer/account/%s/contracts", addr))
@@ -364,5 +280,55 @@ func (c *Client) GetAccountOps(ctx context.Context, addr tezos.Address, params O
 	if err := c.get(ctx, u, nil, &ops); err != nil {
 		return nil, err
 	}
+	if params.dedup {
+		ops = Deduplicate(ops)
+	}
 	return ops, nil
 }
+
+// Genesis fundraiser commitments are held by blinded accounts until they
+// are claimed with an activation operation.
+var CommitmentColumns = []string{
+	"row_id",
+	"address",
+	"address_type",
+	"is_activated",
+	"unclaimed_balance",
+	"first_seen",
+	"first_seen_time",
+	"last_seen",
+	"last_seen_time",
+}
+
+func (c *Client) NewCommitmentQuery() AccountQuery {
+	q := c.NewAccountQuery()
+	q.WithColumns(CommitmentColumns...).
+		WithFilter(FilterModeEqual, "address_type", "blinded")
+	return q
+}
+
+// ListUnclaimedCommitments returns all blinded commitment accounts that
+// have not been activated yet.
+func (c *Client) ListUnclaimedCommitments(ctx context.Context) ([]*Account, error) {
+	q := c.NewCommitmentQuery()
+	q.WithFilter(FilterModeGt, "unclaimed_balance", 0)
+	res := make([]*Account, 0)
+	err := q.Each(ctx, func(list *AccountList) error {
+		res = append(res, list.Rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetUnclaimedBalance returns the unclaimed commitment balance for a blinded
+// address or for the implicit account that activated it.
+func (c *Client) GetUnclaimedBalance(ctx context.Context, addr tezos.Address) (float64, error) {
+	a, err := c.GetAccount(ctx, addr, NewAccountParams())
+	if err != nil {
+		return 0, err
+	}
+	return a.UnclaimedBalance, nil
+}
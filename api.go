@@ -0,0 +1,46 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// API is the commonly used read surface of Client. Applications can depend
+// on API instead of *Client and use a fake like tzstatsmock.Client in unit
+// tests.
+type API interface {
+	GetStatus(ctx context.Context) (*Status, error)
+	GetTip(ctx context.Context) (*Tip, error)
+	GetConfig(ctx context.Context) (*BlockchainConfig, error)
+
+	GetHead(ctx context.Context, params BlockParams) (*Block, error)
+	GetBlock(ctx context.Context, hash tezos.BlockHash, params BlockParams) (*Block, error)
+	GetBlockHeight(ctx context.Context, height int64, params BlockParams) (*Block, error)
+	GetBlockOps(ctx context.Context, hash tezos.BlockHash, params OpParams) ([]*Op, error)
+	GetOp(ctx context.Context, hash tezos.OpHash, params OpParams) ([]*Op, error)
+
+	GetAccount(ctx context.Context, addr tezos.Address, params AccountParams) (*Account, error)
+	GetAccountOps(ctx context.Context, addr tezos.Address, params OpParams) ([]*Op, error)
+	GetBaker(ctx context.Context, addr tezos.Address, params BakerParams) (*Baker, error)
+	ListBakers(ctx context.Context, params BakerParams) ([]*Baker, error)
+
+	GetContract(ctx context.Context, addr tezos.Address, params ContractParams) (*Contract, error)
+	GetContractScript(ctx context.Context, addr tezos.Address, params ContractParams) (*ContractScript, error)
+	GetContractStorage(ctx context.Context, addr tezos.Address, params ContractParams) (*ContractValue, error)
+	GetContractCalls(ctx context.Context, addr tezos.Address, params ContractParams) ([]*Op, error)
+	GetBigmap(ctx context.Context, id int64, params ContractParams) (*Bigmap, error)
+	GetBigmapValue(ctx context.Context, id int64, key string, params ContractParams) (*BigmapValue, error)
+	ListBigmapValues(ctx context.Context, id int64, params ContractParams) ([]BigmapValue, error)
+
+	QueryOps(ctx context.Context, filter FilterList, cols []string) (*OpList, error)
+	QueryBlocks(ctx context.Context, filter FilterList, cols []string) (*BlockList, error)
+	QueryAccounts(ctx context.Context, filter FilterList, cols []string) (*AccountList, error)
+	QueryContracts(ctx context.Context, filter FilterList, cols []string) (*ContractList, error)
+	QueryTable(ctx context.Context, q TableQuery, result interface{}) error
+}
+
+var _ API = (*Client)(nil)
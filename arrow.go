@@ -0,0 +1,301 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build arrow
+// +build arrow
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v9/arrow"
+	"github.com/apache/arrow/go/v9/arrow/array"
+	"github.com/apache/arrow/go/v9/arrow/memory"
+	"github.com/apache/arrow/go/v9/parquet"
+	"github.com/apache/arrow/go/v9/parquet/compress"
+	"github.com/apache/arrow/go/v9/parquet/pqarrow"
+)
+
+// DefaultParquetRowGroup is the number of rows ParquetWriter buffers
+// before writing a row group.
+var DefaultParquetRowGroup = 64 * 1024
+
+// ArrowBuilder converts decoded table rows into Arrow record batches.
+// Hashes, addresses and enums become strings, times millisecond UTC
+// timestamps and contract data JSON text.
+//
+//	b, _ := tzstats.NewArrowBuilder(memory.DefaultAllocator, "op", cols...)
+//	defer b.Release()
+//	err := q.Each(ctx, func(l *tzstats.OpList) error {
+//		return b.Append(l)
+//	})
+//	rec := b.NewRecord()
+type ArrowBuilder struct {
+	cols   []exportColumn
+	schema *arrow.Schema
+	b      *array.RecordBuilder
+	n      int
+}
+
+// NewArrowBuilder returns a builder for rows of table with columns cols, or
+// all table columns when cols is empty.
+func NewArrowBuilder(mem memory.Allocator, table string, cols ...string) (*ArrowBuilder, error) {
+	ecols, err := exportColumns(table, cols)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]arrow.Field, len(ecols))
+	for i, c := range ecols {
+		fields[i] = arrow.Field{
+			Name:     c.Name,
+			Type:     arrowType(c.Kind),
+			Nullable: c.Nullable,
+		}
+	}
+	schema := arrow.NewSchema(fields, nil)
+	return &ArrowBuilder{
+		cols:   ecols,
+		schema: schema,
+		b:      array.NewRecordBuilder(mem, schema),
+	}, nil
+}
+
+func arrowType(k exportKind) arrow.DataType {
+	switch k {
+	case exportInt:
+		return arrow.PrimitiveTypes.Int64
+	case exportUint:
+		return arrow.PrimitiveTypes.Uint64
+	case exportFloat:
+		return arrow.PrimitiveTypes.Float64
+	case exportBool:
+		return arrow.FixedWidthTypes.Boolean
+	case exportTime:
+		return arrow.FixedWidthTypes.Timestamp_ms
+	case exportBytes:
+		return arrow.BinaryTypes.Binary
+	case exportStrings:
+		return arrow.ListOf(arrow.BinaryTypes.String)
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+func (b *ArrowBuilder) Schema() *arrow.Schema {
+	return b.schema
+}
+
+// Len returns the number of rows appended since the last record.
+func (b *ArrowBuilder) Len() int {
+	return b.n
+}
+
+// Append adds rows, which may be a list like OpList, a slice of row
+// pointers or a single row.
+func (b *ArrowBuilder) Append(rows interface{}) error {
+	vals, err := exportRows(rows)
+	if err != nil {
+		return err
+	}
+	// convert a full row first, so errors don't leave columns of
+	// different length
+	row := make([]interface{}, len(b.cols))
+	for _, v := range vals {
+		for i, c := range b.cols {
+			if row[i], err = c.value(v); err != nil {
+				return err
+			}
+		}
+		for i, v := range row {
+			appendArrow(b.b.Field(i), v)
+		}
+		b.n++
+	}
+	return nil
+}
+
+func appendArrow(fb array.Builder, v interface{}) {
+	if v == nil {
+		fb.AppendNull()
+		return
+	}
+	switch val := v.(type) {
+	case int64:
+		fb.(*array.Int64Builder).Append(val)
+	case uint64:
+		fb.(*array.Uint64Builder).Append(val)
+	case float64:
+		fb.(*array.Float64Builder).Append(val)
+	case bool:
+		fb.(*array.BooleanBuilder).Append(val)
+	case string:
+		fb.(*array.StringBuilder).Append(val)
+	case time.Time:
+		fb.(*array.TimestampBuilder).Append(arrow.Timestamp(val.UnixNano() / int64(time.Millisecond)))
+	case []byte:
+		fb.(*array.BinaryBuilder).Append(val)
+	case []string:
+		lb := fb.(*array.ListBuilder)
+		lb.Append(true)
+		sb := lb.ValueBuilder().(*array.StringBuilder)
+		for _, s := range val {
+			sb.Append(s)
+		}
+	}
+}
+
+// NewRecord returns a record batch with all rows appended so far and
+// resets the builder. The caller must release the record.
+func (b *ArrowBuilder) NewRecord() arrow.Record {
+	b.n = 0
+	return b.b.NewRecord()
+}
+
+func (b *ArrowBuilder) Release() {
+	b.b.Release()
+}
+
+// ArrowRecord converts the list into an Arrow record batch with the
+// list's query columns.
+func (l *OpList) ArrowRecord(mem memory.Allocator) (arrow.Record, error) {
+	return newArrowRecord(mem, "op", l.columns, l)
+}
+
+// ArrowRecord converts the list into an Arrow record batch with the
+// list's query columns.
+func (l *BlockList) ArrowRecord(mem memory.Allocator) (arrow.Record, error) {
+	return newArrowRecord(mem, "block", l.columns, l)
+}
+
+func newArrowRecord(mem memory.Allocator, table string, cols []string, rows interface{}) (arrow.Record, error) {
+	b, err := NewArrowBuilder(mem, table, cols...)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Release()
+	if err := b.Append(rows); err != nil {
+		return nil, err
+	}
+	return b.NewRecord(), nil
+}
+
+// ParquetWriter writes rows of one table to a Snappy compressed Parquet
+// file. Rows are buffered and written in row groups of RowGroup rows.
+type ParquetWriter struct {
+	RowGroup int
+	b        *ArrowBuilder
+	w        *pqarrow.FileWriter
+}
+
+// NewParquetWriter returns a writer for rows of table with columns cols, or
+// all table columns when cols is empty. Close must be called to write the
+// file footer.
+func NewParquetWriter(w io.Writer, table string, cols ...string) (*ParquetWriter, error) {
+	mem := memory.DefaultAllocator
+	b, err := NewArrowBuilder(mem, table, cols...)
+	if err != nil {
+		return nil, err
+	}
+	props := parquet.NewWriterProperties(
+		parquet.WithAllocator(mem),
+		parquet.WithCompression(compress.Codecs.Snappy),
+	)
+	fw, err := pqarrow.NewFileWriter(b.Schema(), w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		b.Release()
+		return nil, fmt.Errorf("parquet %s: %w", table, err)
+	}
+	return &ParquetWriter{
+		RowGroup: DefaultParquetRowGroup,
+		b:        b,
+		w:        fw,
+	}, nil
+}
+
+// Write adds rows, which may be a list like OpList, a slice of row
+// pointers or a single row.
+func (p *ParquetWriter) Write(rows interface{}) error {
+	if err := p.b.Append(rows); err != nil {
+		return err
+	}
+	if p.b.Len() >= p.RowGroup {
+		return p.Flush()
+	}
+	return nil
+}
+
+// Flush writes buffered rows as a row group.
+func (p *ParquetWriter) Flush() error {
+	if p.b.Len() == 0 {
+		return nil
+	}
+	rec := p.b.NewRecord()
+	defer rec.Release()
+	return p.w.Write(rec)
+}
+
+// Close flushes buffered rows and writes the file footer.
+func (p *ParquetWriter) Close() error {
+	defer p.b.Release()
+	if err := p.Flush(); err != nil {
+		p.w.Close()
+		return err
+	}
+	return p.w.Close()
+}
+
+// ParquetSink is a Sink which writes ops and blocks to Parquet files,
+// e.g. as target of ExportOps or a Follower. Rows of a nil writer are
+// dropped.
+type ParquetSink struct {
+	ops    *ParquetWriter
+	blocks *ParquetWriter
+}
+
+func NewParquetSink(ops, blocks *ParquetWriter) *ParquetSink {
+	return &ParquetSink{ops: ops, blocks: blocks}
+}
+
+func (s *ParquetSink) WriteOps(_ context.Context, ops []*Op) error {
+	if s.ops == nil {
+		return nil
+	}
+	return s.ops.Write(ops)
+}
+
+func (s *ParquetSink) WriteBlocks(_ context.Context, blocks []*Block) error {
+	if s.blocks == nil {
+		return nil
+	}
+	return s.blocks.Write(blocks)
+}
+
+func (s *ParquetSink) Flush(_ context.Context) error {
+	for _, w := range []*ParquetWriter{s.ops, s.blocks} {
+		if w == nil {
+			continue
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes both files.
+func (s *ParquetSink) Close() error {
+	var err error
+	for _, w := range []*ParquetWriter{s.ops, s.blocks} {
+		if w == nil {
+			continue
+		}
+		if e := w.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a single API request and the response received.
+// Hashes are hex encoded SHA-256 digests of the raw request and response
+// bodies.
+type AuditRecord struct {
+	Time         time.Time           `json:"time"`
+	Method       string              `json:"method"`
+	Url          string              `json:"url"`
+	Params       map[string][]string `json:"params,omitempty"`
+	RequestHash  string              `json:"request_sha256,omitempty"`
+	Status       int                 `json:"status"`
+	ResponseHash string              `json:"response_sha256,omitempty"`
+	ResponseSize int64               `json:"response_size"`
+	RequestId    string              `json:"request_id,omitempty"`
+	Duration     int64               `json:"duration_ms"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// AuditLog appends one JSON line per request to a writer. It is safe for
+// concurrent use.
+type AuditLog struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+// OpenAuditLog opens or creates an append-only audit log file.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuditLog(f), nil
+}
+
+func (l *AuditLog) Record(r AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(r)
+}
+
+// Close syncs and closes the underlying writer if possible.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f, ok := l.w.(*os.File); ok {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// UseAuditLog records every request made through the client.
+func (c *Client) UseAuditLog(l *AuditLog) {
+	c.audit = l
+}
+
+// auditEntry collects data for one request. It wraps the response body to
+// hash all bytes as they are consumed.
+type auditEntry struct {
+	log   *AuditLog
+	rec   AuditRecord
+	start time.Time
+	hash  hash.Hash
+	body  io.ReadCloser
+}
+
+func (l *AuditLog) begin(r *http.Request) *auditEntry {
+	e := &auditEntry{
+		log:   l,
+		start: time.Now(),
+		hash:  sha256.New(),
+		rec: AuditRecord{
+			Method: r.Method,
+			Url:    redactAuth(r.URL).String(),
+			Params: redactAuth(r.URL).Query(),
+		},
+	}
+	e.rec.Time = e.start.UTC()
+	if r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			h := sha256.New()
+			io.Copy(h, body)
+			body.Close()
+			e.rec.RequestHash = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	return e
+}
+
+func (e *auditEntry) wrap(resp *http.Response) {
+	if e == nil {
+		return
+	}
+	e.rec.Status = resp.StatusCode
+	e.rec.RequestId = resp.Header.Get("X-Request-Id")
+	e.body = resp.Body
+	resp.Body = e
+}
+
+func (e *auditEntry) Read(p []byte) (int, error) {
+	n, err := e.body.Read(p)
+	e.hash.Write(p[:n])
+	e.rec.ResponseSize += int64(n)
+	return n, err
+}
+
+func (e *auditEntry) Close() error {
+	// drain to hash the full response even when the consumer stops early
+	io.Copy(ioutil.Discard, e)
+	return e.body.Close()
+}
+
+func (e *auditEntry) fail(err error) {
+	if e == nil || err == nil {
+		return
+	}
+	e.rec.Error = err.Error()
+}
+
+func (e *auditEntry) finish() {
+	if e.body != nil {
+		e.rec.ResponseHash = hex.EncodeToString(e.hash.Sum(nil))
+	}
+	e.rec.Duration = time.Since(e.start).Milliseconds()
+	if err := e.log.Record(e.rec); err != nil {
+		log.Errorf("audit log: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+const headerApiKey = "X-Api-Key"
+
+// DefaultApiKeyParam is the query argument used to send API keys when
+// header authentication is not possible.
+var DefaultApiKeyParam = "api_key"
+
+type authContextKey struct{}
+
+// UseApiKey authenticates all requests with key sent in the X-Api-Key header.
+func (c *Client) UseApiKey(key string) {
+	c.apiKey = key
+	c.apiKeyQuery = false
+}
+
+// UseApiKeyParam authenticates all requests with key sent as query argument.
+// Use only when a proxy strips the API key header.
+func (c *Client) UseApiKeyParam(key string) {
+	c.apiKey = key
+	c.apiKeyQuery = true
+}
+
+// WithAuth returns a context which overrides the client's API key for all
+// requests made with it.
+func WithAuth(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, authContextKey{}, key)
+}
+
+// WithAuth overrides the client's API key for this query.
+func (q *tableQuery) WithAuth(key string) TableQuery {
+	q.auth = key
+	return q
+}
+
+func (q *tableQuery) authContext(ctx context.Context) context.Context {
+	if q.auth == "" {
+		return ctx
+	}
+	return WithAuth(ctx, q.auth)
+}
+
+// applyAuth adds the API key from context or client to a request. Keys
+// are only sent to the API server and its failover endpoints, never to
+// other hosts like IPFS gateways or token metadata URIs.
+func (c *Client) applyAuth(ctx context.Context, path string, headers http.Header) string {
+	key := c.apiKey
+	if v, ok := ctx.Value(authContextKey{}).(string); ok {
+		key = v
+	}
+	if key == "" || !c.isApiUrl(path) {
+		return path
+	}
+	if !c.apiKeyQuery {
+		headers.Set(headerApiKey, key)
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + DefaultApiKeyParam + "=" + url.QueryEscape(key)
+}
+
+// isApiUrl returns true when u points to the API server or one of the
+// failover endpoints.
+func (c *Client) isApiUrl(u string) bool {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	if sameOrigin(pu, c.params.Server) {
+		return true
+	}
+	if f := c.failover; f != nil {
+		for _, base := range f.bases {
+			if sameOrigin(pu, base) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameOrigin(u *url.URL, base string) bool {
+	bu, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, bu.Scheme) && strings.EqualFold(u.Host, bu.Host)
+}
+
+// redactAuth removes API keys from URLs before they are logged.
+func redactAuth(u *url.URL) *url.URL {
+	q := u.Query()
+	if _, ok := q[DefaultApiKeyParam]; !ok {
+		return u
+	}
+	q.Set(DefaultApiKeyParam, "redacted")
+	nu := *u
+	nu.RawQuery = q.Encode()
+	return &nu
+}
+
+// dumpRequest formats req for trace logs with API keys redacted.
+func dumpRequest(req *http.Request, body bool) string {
+	r := *req
+	r.URL = redactAuth(req.URL)
+	if r.Header.Get(headerApiKey) != "" {
+		r.Header = req.Header.Clone()
+		r.Header.Set(headerApiKey, "redacted")
+	}
+	buf, _ := httputil.DumpRequestOut(&r, body)
+	// the dump replaces a consumed body with a copy
+	req.Body = r.Body
+	return string(buf)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthOtherHost(t *testing.T) {
+	var apiKey, otherKey string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get(headerApiKey) + r.URL.Query().Get(DefaultApiKeyParam)
+		w.Write([]byte(`{}`))
+	})
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherKey = r.Header.Get(headerApiKey) + r.URL.Query().Get(DefaultApiKeyParam)
+		w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+
+	for _, param := range []bool{false, true} {
+		if param {
+			c.UseApiKeyParam("secret")
+		} else {
+			c.UseApiKey("secret")
+		}
+		ctx := WithAuth(context.Background(), "secret")
+		var v struct{}
+		if err := c.GetJSON(ctx, "/explorer/tip", nil, &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.GetJSON(ctx, other.URL+"/ipfs/x", nil, &v); err != nil {
+			t.Fatal(err)
+		}
+		if apiKey != "secret" {
+			t.Errorf("param=%t: API server got key %q", param, apiKey)
+		}
+		if otherKey != "" {
+			t.Errorf("param=%t: other host got key %q", param, otherKey)
+		}
+	}
+}
@@ -128,22 +128,22 @@ func NewBakerParams() BakerParams {
 }
 
 func (p BakerParams) WithLimit(v uint) BakerParams {
-    p.Query.Set("limit", strconv.Itoa(int(v)))
+    p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
     return p
 }
 
 func (p BakerParams) WithOffset(v uint) BakerParams {
-    p.Query.Set("offset", strconv.Itoa(int(v)))
+    p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
     return p
 }
 
 func (p BakerParams) WithCursor(v uint) BakerParams {
-    p.Query.Set("cursor", strconv.Itoa(int(v)))
+    p.Params = p.Params.With("cursor", strconv.Itoa(int(v)))
     return p
 }
 
 func (p BakerParams) WithMeta() BakerParams {
-    p.Query.Set("meta", "1")
+    p.Params = p.Params.With("meta", "1")
     return p
 }
 
@@ -180,6 +180,9 @@ func (c *Client) ListBakerEndorsements(ctx context.Context, addr tezos.Address,
     if err := c.get(ctx, u, nil, &ops); err != nil {
         return nil, err
     }
+    if params.dedup {
+        ops = Deduplicate(ops)
+    }
     return ops, nil
 }
 
@@ -189,6 +192,9 @@ func (c *Client) ListBakerDelegations(ctx context.Context, addr tezos.Address, p
     if err := c.get(ctx, u, nil, &ops); err != nil {
         return nil, err
     }
+    if params.dedup {
+        ops = Deduplicate(ops)
+    }
     return ops, nil
 }
 
@@ -218,3 +224,51 @@ func (c *Client) GetBakerSnapshot(ctx context.Context, addr tezos.Address, cycle
     }
     return &r, nil
 }
+
+// BakerDeactivation flags an active baker approaching its grace period
+// deadline, i.e. the cycle at which the protocol will deactivate it unless
+// it shows activity again.
+type BakerDeactivation struct {
+    Baker         *Baker `json:"baker"`
+    DeadlineCycle int64  `json:"deadline_cycle"`
+    CyclesLeft    int64  `json:"cycles_left"`
+}
+
+// Number of cycles before the grace period ends when a baker is
+// considered close to deactivation.
+var DeactivationWarningCycles int64 = 2
+
+func (c *Client) ListBakersNearDeactivation(ctx context.Context) ([]*BakerDeactivation, error) {
+    tip, err := c.GetTip(ctx)
+    if err != nil {
+        return nil, err
+    }
+    res := make([]*BakerDeactivation, 0)
+    params := NewBakerParams().WithLimit(500)
+    var offset uint
+    for {
+        bakers, err := c.ListBakers(ctx, params.WithOffset(offset))
+        if err != nil {
+            return nil, err
+        }
+        if len(bakers) == 0 {
+            break
+        }
+        for _, b := range bakers {
+            if !b.IsActive {
+                continue
+            }
+            left := b.GracePeriod - tip.Cycle
+            if left > DeactivationWarningCycles {
+                continue
+            }
+            res = append(res, &BakerDeactivation{
+                Baker:         b,
+                DeadlineCycle: b.GracePeriod,
+                CyclesLeft:    left,
+            })
+        }
+        offset += uint(len(bakers))
+    }
+    return res, nil
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// Batch sends several table queries in one request to servers which
+// support multiplexed queries and splits the response into results by
+// name.
+//
+// /tables/batch is not a public TzStats endpoint. The protocol below is
+// defined by this package, so it only works with proxies or servers which
+// implement it, and clients must enable it with UseBatch. The request
+// lists query urls, the response has one entry per query with its status
+// and rows or error.
+//
+//	POST /tables/batch
+//	{"queries":[{"name":"ops","url":"/tables/op.json?..."}, ...]}
+//
+//	[{"name":"ops","status":200,"rows":[...]},
+//	 {"name":"blocks","status":400,"error":{"errors":[...]}}]
+//
+// Without UseBatch, or when the batch request fails with an HTTP error,
+// queries run concurrently like a QueryGroup. After a 404, 405 or 501
+// response later batches of the client skip the batch endpoint. Queries
+// with several filter branches, CSV format or their own API key always
+// run separately.
+//
+//	res, err := c.Batch(ctx,
+//		tzstats.BatchOps("ops", opQuery),
+//		tzstats.BatchBlocks("blocks", blockQuery),
+//	)
+//	ops := res.Ops("ops")
+
+// UseBatch enables or disables sending Batch queries to the batch
+// endpoint. It is disabled by default.
+func (c *Client) UseBatch(enable bool) {
+	c.batch = enable
+	atomic.StoreInt32(&c.noBatch, 0)
+}
+
+// BatchItem is a named query of a Batch.
+type BatchItem struct {
+	Name   string
+	query  tableQuery
+	run    func(context.Context) (interface{}, error)
+	decode func(context.Context, json.RawMessage) (interface{}, error)
+}
+
+func BatchOps(name string, q OpQuery) BatchItem {
+	return BatchItem{
+		Name:  name,
+		query: q.tableQuery,
+		run:   func(ctx context.Context) (interface{}, error) { return q.Run(ctx) },
+		decode: func(ctx context.Context, buf json.RawMessage) (interface{}, error) {
+			l := &OpList{
+				columns:  q.Columns,
+				ctx:      ctx,
+				client:   q.client,
+				withPrim: q.Prim,
+			}
+			if err := json.Unmarshal(buf, l); err != nil {
+				return nil, err
+			}
+			if r := q.client.resolver; r != nil {
+				if err := r.ResolveOps(ctx, l.Rows); err != nil {
+					return nil, err
+				}
+			}
+			return l, nil
+		},
+	}
+}
+
+func BatchBlocks(name string, q BlockQuery) BatchItem {
+	return BatchItem{
+		Name:  name,
+		query: q.tableQuery,
+		run:   func(ctx context.Context) (interface{}, error) { return q.Run(ctx) },
+		decode: func(_ context.Context, buf json.RawMessage) (interface{}, error) {
+			l := &BlockList{columns: q.Columns}
+			return l, json.Unmarshal(buf, l)
+		},
+	}
+}
+
+func BatchAccounts(name string, q AccountQuery) BatchItem {
+	return BatchItem{
+		Name:  name,
+		query: q.tableQuery,
+		run:   func(ctx context.Context) (interface{}, error) { return q.Run(ctx) },
+		decode: func(_ context.Context, buf json.RawMessage) (interface{}, error) {
+			l := &AccountList{columns: q.Columns}
+			return l, json.Unmarshal(buf, l)
+		},
+	}
+}
+
+func BatchContracts(name string, q ContractQuery) BatchItem {
+	return BatchItem{
+		Name:  name,
+		query: q.tableQuery,
+		run:   func(ctx context.Context) (interface{}, error) { return q.Run(ctx) },
+		decode: func(_ context.Context, buf json.RawMessage) (interface{}, error) {
+			l := &ContractList{columns: q.Columns}
+			return l, json.Unmarshal(buf, l)
+		},
+	}
+}
+
+// BatchRaw adds a query on any table, e.g. market data.
+func BatchRaw(name string, q RawQuery) BatchItem {
+	return BatchItem{
+		Name:  name,
+		query: q.tableQuery,
+		run:   func(ctx context.Context) (interface{}, error) { return q.Run(ctx) },
+		decode: func(_ context.Context, buf json.RawMessage) (interface{}, error) {
+			l := &RawList{Columns: q.Columns}
+			return l, json.Unmarshal(buf, l)
+		},
+	}
+}
+
+func (r QueryResults) Raw(name string) *RawList {
+	v, _ := r[name].(*RawList)
+	return v
+}
+
+type batchRequest struct {
+	Queries []batchQuery `json:"queries"`
+}
+
+type batchQuery struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type batchResult struct {
+	Name   string          `json:"name"`
+	Status int             `json:"status"`
+	Rows   json.RawMessage `json:"rows"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// Batch runs all queries and returns results by name. Results of
+// successful queries are returned even when others failed, failed queries
+// are reported in a QueryGroupError.
+func (c *Client) Batch(ctx context.Context, items ...BatchItem) (QueryResults, error) {
+	var (
+		req    batchRequest
+		byName = make(map[string]BatchItem)
+	)
+	for _, v := range items {
+		if v.Name == "" {
+			return nil, fmt.Errorf("batch: empty query name")
+		}
+		if _, ok := byName[v.Name]; ok {
+			return nil, fmt.Errorf("batch: duplicate query name %q", v.Name)
+		}
+		if err := v.query.Check(); err != nil {
+			return nil, fmt.Errorf("batch %s: %w", v.Name, err)
+		}
+		if c.batch && atomic.LoadInt32(&c.noBatch) == 0 && v.query.batchable() {
+			u, err := url.Parse(v.query.Url())
+			if err != nil {
+				return nil, fmt.Errorf("batch %s: %w", v.Name, err)
+			}
+			req.Queries = append(req.Queries, batchQuery{v.Name, u.RequestURI()})
+		}
+		byName[v.Name] = v
+	}
+	if len(req.Queries) > 0 {
+		res, err := c.runBatch(ctx, req, byName)
+		if res != nil {
+			return res, err
+		}
+		switch status := ErrorStatus(err); status {
+		case 0:
+			return nil, err
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			atomic.StoreInt32(&c.noBatch, 1)
+			log.Debugf("batch: endpoint unavailable, running queries separately")
+		default:
+			log.Debugf("batch: request failed with status %d, running queries separately", status)
+		}
+	}
+	g := c.NewQueryGroup(ctx).WithPolicy(GroupCollectErrors)
+	for _, v := range items {
+		g.Go(v.Name, v.run)
+	}
+	return g.Wait()
+}
+
+// batchable returns true when the query can be sent in a batch request.
+func (q tableQuery) batchable() bool {
+	if q.auth != "" || (q.Format != "" && q.Format != FormatJSON) {
+		return false
+	}
+	bs, err := q.filterBranches()
+	return err == nil && len(bs) == 1
+}
+
+// runBatch sends a batch request, then runs queries which are not part of
+// it concurrently. Results are nil when the batch request failed.
+func (c *Client) runBatch(ctx context.Context, req batchRequest, items map[string]BatchItem) (QueryResults, error) {
+	var resp []batchResult
+	if err := c.post(ctx, "tables/batch", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	var (
+		res  = make(QueryResults)
+		errs = make(map[string]error)
+		sent = make(map[string]bool, len(req.Queries))
+	)
+	for _, v := range req.Queries {
+		sent[v.Name] = true
+		errs[v.Name] = fmt.Errorf("batch: missing result")
+	}
+	for _, r := range resp {
+		item, ok := items[r.Name]
+		if !ok || !sent[r.Name] {
+			continue
+		}
+		delete(errs, r.Name)
+		if r.Status != http.StatusOK {
+			errs[r.Name] = batchError(r)
+			continue
+		}
+		v, err := item.decode(ctx, r.Rows)
+		if err != nil {
+			errs[r.Name] = err
+			continue
+		}
+		c.observeRows(&item.query, v)
+		res[r.Name] = v
+	}
+
+	// remaining queries
+	g := c.NewQueryGroup(ctx).WithPolicy(GroupCollectErrors)
+	for name, item := range items {
+		if !sent[name] {
+			g.Go(name, item.run)
+		}
+	}
+	more, err := g.Wait()
+	for name, v := range more {
+		res[name] = v
+	}
+	if e, ok := IsQueryGroupError(err); ok {
+		for name, err := range e.Errors {
+			errs[name] = err
+		}
+	}
+	if len(errs) > 0 {
+		return res, QueryGroupError{Errors: errs}
+	}
+	return res, nil
+}
+
+func batchError(r batchResult) error {
+	var e ApiErrors
+	if len(r.Error) > 0 && json.Unmarshal(r.Error, &e) == nil && len(e.Errors) > 0 {
+		for i := range e.Errors {
+			if e.Errors[i].Status == 0 {
+				e.Errors[i].Status = r.Status
+			}
+		}
+		return e
+	}
+	return HttpError{
+		Status:  r.Status,
+		Data:    string(r.Error),
+		Request: "batch " + r.Name,
+	}
+}
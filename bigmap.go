@@ -10,7 +10,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"strconv"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -70,7 +69,7 @@ func (r BigmapRow) DecodeKeyType() (micheline.Type, error) {
 	if len(buf) == 0 {
 		return t, io.ErrShortBuffer
 	}
-	err = t.UnmarshalBinary(buf)
+	err = safeDecode("type", func() error { return t.UnmarshalBinary(buf) })
 	return t, err
 }
 
@@ -83,7 +82,7 @@ func (r BigmapRow) DecodeValueType() (micheline.Type, error) {
 	if len(buf) == 0 {
 		return t, io.ErrShortBuffer
 	}
-	err = t.UnmarshalBinary(buf)
+	err = safeDecode("type", func() error { return t.UnmarshalBinary(buf) })
 	return t, err
 }
 
@@ -137,8 +136,8 @@ func (b *BigmapRow) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return b.UnmarshalJSONBrief(data)
 	}
-	type Alias *BigmapRow
-	return json.Unmarshal(data, Alias(b))
+	type Alias BigmapRow
+	return json.Unmarshal(data, (*Alias)(b))
 }
 
 func (b *BigmapRow) UnmarshalJSONBrief(data []byte) error {
@@ -150,50 +149,15 @@ func (b *BigmapRow) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, b.columns); err != nil {
+		return err
+	}
 	for i, v := range b.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			br.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "contract":
-			br.Contract, err = tezos.ParseAddress(f.(string))
-		case "account_id":
-			br.AccountId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "bigmap_id":
-			br.BigmapId, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "n_updates":
-			br.NUpdates, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "n_keys":
-			br.NKeys, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "alloc_height":
-			br.AllocHeight, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "alloc_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				br.AllocTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "alloc_block":
-			br.AllocBlock, err = tezos.ParseBlockHash(f.(string))
-		case "update_height":
-			br.UpdateHeight, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "update_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				br.UpdateTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "update_block":
-			br.UpdateBlock, err = tezos.ParseBlockHash(f.(string))
-		case "key_type":
-			br.KeyType = f.(string)
-		case "value_type":
-			br.ValueType = f.(string)
-		}
-		if err != nil {
+		if _, err := br.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
@@ -10,7 +10,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"strconv"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -58,7 +57,7 @@ func (r BigmapUpdateRow) DecodeKeyType() (micheline.Type, error) {
 		return micheline.Type{}, io.ErrShortBuffer
 	}
 	t := micheline.Type{}
-	err = t.UnmarshalBinary(buf)
+	err = safeDecode("type", func() error { return t.UnmarshalBinary(buf) })
 	return t, err
 }
 
@@ -77,7 +76,7 @@ func (r BigmapUpdateRow) DecodeValueType() (micheline.Type, error) {
 		return micheline.Type{}, io.ErrShortBuffer
 	}
 	t := micheline.Type{}
-	err = t.UnmarshalBinary(buf)
+	err = safeDecode("type", func() error { return t.UnmarshalBinary(buf) })
 	return t, err
 }
 
@@ -96,7 +95,12 @@ func (r BigmapUpdateRow) DecodeKey(typ micheline.Type) (micheline.Key, error) {
 	if len(buf) == 0 {
 		return micheline.Key{}, io.ErrShortBuffer
 	}
-	return micheline.DecodeKey(typ, buf)
+	var k micheline.Key
+	err = safeDecode("key", func() (err error) {
+		k, err = micheline.DecodeKey(typ, buf)
+		return
+	})
+	return k, err
 }
 
 // Update only
@@ -112,7 +116,7 @@ func (r BigmapUpdateRow) DecodeValue(typ micheline.Type) (micheline.Value, error
 	if len(buf) == 0 {
 		return v, io.ErrShortBuffer
 	}
-	err = v.Decode(buf)
+	err = safeDecode("value", func() error { return v.Decode(buf) })
 	return v, err
 }
 
@@ -166,8 +170,8 @@ func (b *BigmapUpdateRow) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return b.UnmarshalJSONBrief(data)
 	}
-	type Alias *BigmapUpdateRow
-	return json.Unmarshal(data, Alias(b))
+	type Alias BigmapUpdateRow
+	return json.Unmarshal(data, (*Alias)(b))
 }
 
 func (b *BigmapUpdateRow) UnmarshalJSONBrief(data []byte) error {
@@ -179,36 +183,15 @@ func (b *BigmapUpdateRow) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, b.columns); err != nil {
+		return err
+	}
 	for i, v := range b.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			br.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "bigmap_id":
-			br.BigmapId, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "action":
-			br.Action, err = micheline.ParseDiffAction(f.(string))
-		case "key_id":
-			br.KeyId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "hash":
-			br.Hash, err = tezos.ParseExprHash(f.(string))
-		case "key":
-			br.Key = f.(string)
-		case "value":
-			br.Value = f.(string)
-		case "height":
-			br.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				br.Time = time.Unix(0, ts*1000000).UTC()
-			}
-		}
-		if err != nil {
+		if _, err := br.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
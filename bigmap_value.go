@@ -11,7 +11,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"strconv"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -86,7 +85,7 @@ type BigmapValueRow struct {
 	Height   int64          `json:"height"`
 	Time     time.Time      `json:"time"`
 	KeyId    uint64         `json:"key_id"`
-	Hash     tezos.ExprHash `json:"hash,omitempty"`
+	Hash     tezos.ExprHash `json:"hash,omitempty" brief:"key_hash"`
 	Key      string         `json:"key,omitempty"`
 	Value    string         `json:"value,omitempty"`
 
@@ -101,7 +100,12 @@ func (r BigmapValueRow) DecodeKey(typ micheline.Type) (micheline.Key, error) {
 	if len(buf) == 0 {
 		return micheline.Key{}, io.ErrShortBuffer
 	}
-	return micheline.DecodeKey(typ, buf)
+	var k micheline.Key
+	err = safeDecode("key", func() (err error) {
+		k, err = micheline.DecodeKey(typ, buf)
+		return
+	})
+	return k, err
 }
 
 func (r BigmapValueRow) DecodeValue(typ micheline.Type) (micheline.Value, error) {
@@ -113,7 +117,7 @@ func (r BigmapValueRow) DecodeValue(typ micheline.Type) (micheline.Value, error)
 	if len(buf) == 0 {
 		return v, io.ErrShortBuffer
 	}
-	err = v.Decode(buf)
+	err = safeDecode("value", func() error { return v.Decode(buf) })
 	return v, err
 }
 
@@ -167,8 +171,8 @@ func (b *BigmapValueRow) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return b.UnmarshalJSONBrief(data)
 	}
-	type Alias *BigmapValueRow
-	return json.Unmarshal(data, Alias(b))
+	type Alias BigmapValueRow
+	return json.Unmarshal(data, (*Alias)(b))
 }
 
 func (b *BigmapValueRow) UnmarshalJSONBrief(data []byte) error {
@@ -180,34 +184,15 @@ func (b *BigmapValueRow) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, b.columns); err != nil {
+		return err
+	}
 	for i, v := range b.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			br.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "bigmap_id":
-			br.BigmapId, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "key_id":
-			br.KeyId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "key_hash":
-			br.Hash, err = tezos.ParseExprHash(f.(string))
-		case "key":
-			br.Key = f.(string)
-		case "value":
-			br.Value = f.(string)
-		case "height":
-			br.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				br.Time = time.Unix(0, ts*1000000).UTC()
-			}
-		}
-		if err != nil {
+		if _, err := br.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
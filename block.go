@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 	"time"
 
@@ -15,51 +16,52 @@ import (
 )
 
 type Block struct {
-	RowId            uint64                 `json:"row_id"`
-	Hash             tezos.BlockHash        `json:"hash"`
-	ParentHash       *tezos.BlockHash       `json:"predecessor,omitempty,notable"`
-	FollowerHash     *tezos.BlockHash       `json:"successor,omitempty,notable"`
-	Timestamp        time.Time              `json:"time"`
-	Height           int64                  `json:"height"`
-	Cycle            int64                  `json:"cycle"`
-	IsCycleSnapshot  bool                   `json:"is_cycle_snapshot"`
-	Solvetime        int                    `json:"solvetime"`
-	Version          int                    `json:"version"`
-	Round            int                    `json:"round"`
-	Nonce            string                 `json:"nonce"`
-	VotingPeriodKind tezos.VotingPeriodKind `json:"voting_period_kind"`
-	BakerId          uint64                 `json:"baker_id"`
-	Baker            tezos.Address          `json:"baker"`
-	ProposerId       uint64                 `json:"proposer_id"`
-	Proposer         tezos.Address          `json:"proposer"`
-	NSlotsEndorsed   int                    `json:"n_endorsed_slots"`
-	NOpsApplied      int                    `json:"n_ops_applied"`
-	NOpsFailed       int                    `json:"n_ops_failed"`
-	NContractCalls   int                    `json:"n_calls"`
-	NEvents          int                    `json:"n_events"`
-	Volume           float64                `json:"volume"`
-	Fee              float64                `json:"fee"`
-	Reward           float64                `json:"reward"`
-	Deposit          float64                `json:"deposit"`
-	ActivatedSupply  float64                `json:"activated_supply"`
-	MintedSupply     float64                `json:"minted_supply"`
-	BurnedSupply     float64                `json:"burned_supply"`
-	SeenAccounts     int                    `json:"n_accounts"`
-	NewAccounts      int                    `json:"n_new_accounts"`
-	NewContracts     int                    `json:"n_new_contracts"`
-	ClearedAccounts  int                    `json:"n_cleared_accounts"`
-	FundedAccounts   int                    `json:"n_funded_accounts"`
-	GasLimit         int64                  `json:"gas_limit"`
-	GasUsed          int64                  `json:"gas_used"`
-	StoragePaid      int64                  `json:"storage_paid"`
-	PctAccountReuse  float64                `json:"pct_account_reuse"`
-	LbEscapeVote     bool                   `json:"lb_esc_vote"`
-	LbEscapeEma      int64                  `json:"lb_esc_ema"`
-	Protocol         tezos.ProtocolHash     `json:"protocol"`
-	Metadata         map[string]Metadata    `json:"metadata,omitempty,notable"`
-	Rights           []Right                `json:"rights,omitempty,notable"`
-	Ops              []*Op                  `json:"ops,omitempty,notable"`
-	columns          []string               `json:"-"`
+	RowId            uint64                     `json:"row_id" preset:"light,fees"`
+	Hash             tezos.BlockHash            `json:"hash" preset:"light"`
+	ParentHash       *tezos.BlockHash           `json:"predecessor,omitempty,notable"`
+	FollowerHash     *tezos.BlockHash           `json:"successor,omitempty,notable"`
+	Timestamp        time.Time                  `json:"time" preset:"light,fees"`
+	Height           int64                      `json:"height" preset:"light,fees"`
+	Cycle            int64                      `json:"cycle" preset:"light"`
+	IsCycleSnapshot  bool                       `json:"is_cycle_snapshot"`
+	Solvetime        int                        `json:"solvetime"`
+	Version          int                        `json:"version"`
+	Round            int                        `json:"round"`
+	Nonce            string                     `json:"nonce"`
+	VotingPeriodKind tezos.VotingPeriodKind     `json:"voting_period_kind"`
+	BakerId          uint64                     `json:"baker_id"`
+	Baker            tezos.Address              `json:"baker" preset:"light"`
+	ProposerId       uint64                     `json:"proposer_id"`
+	Proposer         tezos.Address              `json:"proposer"`
+	NSlotsEndorsed   int                        `json:"n_endorsed_slots"`
+	NOpsApplied      int                        `json:"n_ops_applied" preset:"light"`
+	NOpsFailed       int                        `json:"n_ops_failed"`
+	NContractCalls   int                        `json:"n_calls" brief:"n_contract_calls"`
+	NEvents          int                        `json:"n_events"`
+	Volume           float64                    `json:"volume"`
+	Fee              float64                    `json:"fee" preset:"fees"`
+	Reward           float64                    `json:"reward"`
+	Deposit          float64                    `json:"deposit"`
+	ActivatedSupply  float64                    `json:"activated_supply"`
+	MintedSupply     float64                    `json:"minted_supply"`
+	BurnedSupply     float64                    `json:"burned_supply" preset:"fees"`
+	SeenAccounts     int                        `json:"n_accounts"`
+	NewAccounts      int                        `json:"n_new_accounts"`
+	NewContracts     int                        `json:"n_new_contracts"`
+	ClearedAccounts  int                        `json:"n_cleared_accounts"`
+	FundedAccounts   int                        `json:"n_funded_accounts"`
+	GasLimit         int64                      `json:"gas_limit"`
+	GasUsed          int64                      `json:"gas_used" preset:"fees"`
+	StoragePaid      int64                      `json:"storage_paid" preset:"fees"`
+	PctAccountReuse  float64                    `json:"pct_account_reuse"`
+	LbEscapeVote     bool                       `json:"lb_esc_vote"`
+	LbEscapeEma      int64                      `json:"lb_esc_ema"`
+	Protocol         tezos.ProtocolHash         `json:"protocol"`
+	Metadata         map[string]Metadata        `json:"metadata,omitempty,notable"`
+	Rights           []Right                    `json:"rights,omitempty,notable"`
+	Ops              []*Op                      `json:"ops,omitempty,notable"`
+	Extra            map[string]json.RawMessage `json:"-"` // fields unknown to this SDK version
+	columns          []string                   `json:"-"`
 }
 
 type Head struct {
@@ -144,6 +146,51 @@ func (b *Block) Head() *Head {
 	}
 }
 
+// BlockUtilization measures how much of a block's capacity was used
+// relative to protocol limits. Percentages are in the range 0..100 and
+// zero when a limit is unknown.
+type BlockUtilization struct {
+	GasUsed        int64   `json:"gas_used"`
+	GasLimit       int64   `json:"gas_limit"`
+	GasPct         float64 `json:"gas_pct"`
+	SlotsEndorsed  int     `json:"slots_endorsed"`
+	SlotsAvailable int     `json:"slots_available"`
+	SlotsPct       float64 `json:"slots_pct"`
+	NOps           int     `json:"n_ops"`
+	FeePerGas      float64 `json:"fee_per_gas"` // mutez per gas unit
+	FeePerOp       float64 `json:"fee_per_op"`  // tez per operation
+}
+
+// Utilization computes gas fullness, endorsement slot usage and fee density
+// from the block and the protocol constants active at the block.
+func (b *Block) Utilization(cfg *BlockchainConfig) BlockUtilization {
+	u := BlockUtilization{
+		GasUsed:       b.GasUsed,
+		SlotsEndorsed: b.NSlotsEndorsed,
+		NOps:          b.NOpsApplied + b.NOpsFailed,
+	}
+	if cfg != nil {
+		u.GasLimit = cfg.HardGasLimitPerBlock
+		u.SlotsAvailable = cfg.ConsensusCommitteeSize
+		if u.SlotsAvailable == 0 {
+			u.SlotsAvailable = cfg.EndorsersPerBlock
+		}
+	}
+	if u.GasLimit > 0 {
+		u.GasPct = float64(u.GasUsed) * 100 / float64(u.GasLimit)
+	}
+	if u.SlotsAvailable > 0 {
+		u.SlotsPct = float64(u.SlotsEndorsed) * 100 / float64(u.SlotsAvailable)
+	}
+	if u.GasUsed > 0 {
+		u.FeePerGas = float64(tezToMutez(b.Fee)) / float64(u.GasUsed)
+	}
+	if u.NOps > 0 {
+		u.FeePerOp = b.Fee / float64(u.NOps)
+	}
+	return u
+}
+
 func (b *Block) WithColumns(cols ...string) *Block {
 	b.columns = cols
 	return b
@@ -152,6 +199,7 @@ func (b *Block) WithColumns(cols ...string) *Block {
 type BlockList struct {
 	Rows    []*Block
 	columns []string
+	onRow   func(*Block) error // optional, rows are not retained when set
 }
 
 func (l BlockList) Len() int {
@@ -172,20 +220,39 @@ func (l *BlockList) UnmarshalJSON(data []byte) error {
 	if data[0] != '[' {
 		return fmt.Errorf("BlockList: expected JSON array")
 	}
-	array := make([]json.RawMessage, 0)
-	if err := json.Unmarshal(data, &array); err != nil {
+	return l.decodeStream(bytes.NewReader(data))
+}
+
+func (l *BlockList) decodeStream(r io.Reader) error {
+	return decodeRows(r, "BlockList", l.decodeRow)
+}
+
+func (l *BlockList) decodeRow(v json.RawMessage) error {
+	b := &Block{
+		columns: l.columns,
+	}
+	if err := b.UnmarshalJSON(v); err != nil {
 		return err
 	}
-	for _, v := range array {
-		r := &Block{
-			columns: l.columns,
-		}
-		if err := r.UnmarshalJSON(v); err != nil {
-			return err
-		}
-		r.columns = nil
-		l.Rows = append(l.Rows, r)
+	return l.addRow(b)
+}
+
+func (l *BlockList) decodeValues(row []interface{}) error {
+	b := &Block{
+		columns: l.columns,
 	}
+	if err := b.decodeBriefValues(row); err != nil {
+		return err
+	}
+	return l.addRow(b)
+}
+
+func (l *BlockList) addRow(b *Block) error {
+	b.columns = nil
+	if l.onRow != nil {
+		return l.onRow(b)
+	}
+	l.Rows = append(l.Rows, b)
 	return nil
 }
 
@@ -199,117 +266,43 @@ func (b *Block) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return b.UnmarshalJSONBrief(data)
 	}
-	type Alias *Block
-	return json.Unmarshal(data, Alias(b))
+	type Alias Block
+	if err := json.Unmarshal(data, (*Alias)(b)); err != nil {
+		return err
+	}
+	b.Extra = extraFields(data, blockModelType)
+	return nil
 }
 
 func (b *Block) UnmarshalJSONBrief(data []byte) error {
-	block := Block{}
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
 	unpacked := make([]interface{}, 0)
-	err := dec.Decode(&unpacked)
-	if err != nil {
+	if err := dec.Decode(&unpacked); err != nil {
+		return err
+	}
+	return b.decodeBriefValues(unpacked)
+}
+
+// decodeBriefValues decodes a brief row from JSON values as produced by
+// json.Decoder.UseNumber.
+func (b *Block) decodeBriefValues(unpacked []interface{}) error {
+	if err := checkRowLen(unpacked, b.columns); err != nil {
 		return err
 	}
+	block := Block{}
 	for i, v := range b.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			block.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "hash":
-			block.Hash, err = tezos.ParseBlockHash(f.(string))
-		case "predecessor":
-			var h tezos.BlockHash
-			h, err = tezos.ParseBlockHash(f.(string))
-			if err == nil {
-				block.ParentHash = &h
-			}
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				block.Timestamp = time.Unix(0, ts*1000000).UTC()
-			}
-		case "height":
-			block.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "cycle":
-			block.Cycle, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "is_cycle_snapshot":
-			block.IsCycleSnapshot, err = strconv.ParseBool(f.(json.Number).String())
-		case "solvetime":
-			block.Solvetime, err = strconv.Atoi(f.(json.Number).String())
-		case "version":
-			block.Version, err = strconv.Atoi(f.(json.Number).String())
-		case "round":
-			block.Round, err = strconv.Atoi(f.(json.Number).String())
-		case "nonce":
-			block.Nonce = f.(string)
-		case "voting_period_kind":
-			block.VotingPeriodKind = tezos.ParseVotingPeriod(f.(string))
-		case "baker_id":
-			block.BakerId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "baker":
-			block.Baker, err = tezos.ParseAddress(f.(string))
-		case "proposer_id":
-			block.ProposerId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "proposer":
-			block.Proposer, err = tezos.ParseAddress(f.(string))
-		case "n_endorsed_slots":
-			block.NSlotsEndorsed, err = strconv.Atoi(f.(json.Number).String())
-		case "n_ops_applied":
-			block.NOpsApplied, err = strconv.Atoi(f.(json.Number).String())
-		case "n_ops_failed":
-			block.NOpsFailed, err = strconv.Atoi(f.(json.Number).String())
-		case "n_contract_calls":
-			block.NContractCalls, err = strconv.Atoi(f.(json.Number).String())
-		case "n_events":
-			block.NEvents, err = strconv.Atoi(f.(json.Number).String())
-		case "volume":
-			block.Volume, err = strconv.ParseFloat(f.(json.Number).String(), 4)
-		case "fee":
-			block.Fee, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "reward":
-			block.Reward, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "deposit":
-			block.Deposit, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "activated_supply":
-			block.ActivatedSupply, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "minted_supply":
-			block.MintedSupply, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "burned_supply":
-			block.BurnedSupply, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "n_accounts":
-			block.SeenAccounts, err = strconv.Atoi(f.(json.Number).String())
-		case "n_new_accounts":
-			block.NewAccounts, err = strconv.Atoi(f.(json.Number).String())
-		case "n_new_contracts":
-			block.NewContracts, err = strconv.Atoi(f.(json.Number).String())
-		case "n_cleared_accounts":
-			block.ClearedAccounts, err = strconv.Atoi(f.(json.Number).String())
-		case "n_funded_accounts":
-			block.FundedAccounts, err = strconv.Atoi(f.(json.Number).String())
-		case "gas_limit":
-			block.GasLimit, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "gas_used":
-			block.GasUsed, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "storage_paid":
-			block.StoragePaid, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "pct_account_reuse":
-			block.PctAccountReuse, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "lb_esc_vote":
-			block.LbEscapeVote, err = strconv.ParseBool(f.(json.Number).String())
-		case "lb_esc_ema":
-			block.LbEscapeEma, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "protocol":
-			block.Protocol, err = tezos.ParseProtocolHash(f.(string))
-		}
+		ok, err := block.decodeBriefColumn(v, f)
 		if err != nil {
 			return err
 		}
+		if !ok {
+			block.Extra = addExtra(block.Extra, v, f)
+		}
 	}
 	*b = block
 	return nil
@@ -337,6 +330,51 @@ func (c *Client) NewBlockQuery() BlockQuery {
 	return BlockQuery{q}
 }
 
+// RunFunc runs the query and calls fn for each row while the response is
+// read. Rows are not retained, so pages of any size decode with bounded
+// memory. Returns the number of rows fn accepted and the cursor after the
+// last of them, also when fn or the request fails.
+func (q BlockQuery) RunFunc(ctx context.Context, fn func(*Block) error) (int, uint64, error) {
+	var (
+		n      int
+		cursor uint64
+		fnErr  error
+	)
+	result := &BlockList{
+		columns: q.Columns,
+		onRow: func(b *Block) error {
+			if fnErr = fn(b); fnErr != nil {
+				return fnErr
+			}
+			n++
+			cursor = b.RowId
+			return nil
+		},
+	}
+	// merging filter expression branches needs all rows
+	if bs, err := q.filterBranches(); err == nil && len(bs) > 1 {
+		l, err := q.Run(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return n, cursor, err
+			}
+			n++
+			cursor = v.RowId
+		}
+		return n, cursor, nil
+	}
+	if err := q.client.QueryTable(ctx, &q.tableQuery, result); err != nil {
+		if fnErr != nil {
+			return n, cursor, fnErr
+		}
+		return n, cursor, err
+	}
+	return n, cursor, nil
+}
+
 func (q BlockQuery) Run(ctx context.Context) (*BlockList, error) {
 	result := &BlockList{
 		columns: q.Columns,
@@ -367,32 +405,32 @@ func NewBlockParams() BlockParams {
 }
 
 func (p BlockParams) WithLimit(v uint) BlockParams {
-	p.Query.Set("limit", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p BlockParams) WithOffset(v uint) BlockParams {
-	p.Query.Set("offset", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p BlockParams) WithCursor(v uint64) BlockParams {
-	p.Query.Set("cursor", strconv.FormatUint(v, 10))
+	p.Params = p.Params.With("cursor", strconv.FormatUint(v, 10))
 	return p
 }
 
 func (p BlockParams) WithOrder(v OrderType) BlockParams {
-	p.Query.Set("order", string(v))
+	p.Params = p.Params.With("order", string(v))
 	return p
 }
 
 func (p BlockParams) WithMeta() BlockParams {
-	p.Query.Set("meta", "1")
+	p.Params = p.Params.With("meta", "1")
 	return p
 }
 
 func (p BlockParams) WithRights() BlockParams {
-	p.Query.Set("rights", "1")
+	p.Params = p.Params.With("rights", "1")
 	return p
 }
 
@@ -438,5 +476,8 @@ func (c *Client) GetBlockOps(ctx context.Context, hash tezos.BlockHash, params O
 	if err := c.get(ctx, u, nil, &ops); err != nil {
 		return nil, err
 	}
+	if params.dedup {
+		ops = Deduplicate(ops)
+	}
 	return ops, nil
 }
@@ -0,0 +1,679 @@
+// Code generated by briefgen; DO NOT EDIT.
+
+package tzstats
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// decodeBriefColumn decodes brief column col into Op. It returns false
+// for columns which need a hand-written decoder.
+func (o *Op) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "id":
+		o.Id, err = briefUint64(f)
+	case "hash":
+		o.Hash, err = tezos.ParseOpHash(jsonString(f))
+	case "type":
+		o.Type = ParseOpType(jsonString(f))
+	case "block":
+		o.Block, err = tezos.ParseBlockHash(jsonString(f))
+	case "time":
+		o.Timestamp, err = briefTime(f)
+	case "height":
+		o.Height, err = briefInt64(f)
+	case "cycle":
+		o.Cycle, err = briefInt64(f)
+	case "counter":
+		o.Counter, err = briefInt64(f)
+	case "op_n":
+		o.OpN, err = briefInt(f)
+	case "op_p":
+		o.OpP, err = briefInt(f)
+	case "op_c":
+		o.OpC, err = briefInt(f)
+	case "op_i":
+		o.OpI, err = briefInt(f)
+	case "status":
+		o.Status = tezos.ParseOpStatus(jsonString(f))
+	case "is_success":
+		o.IsSuccess, err = briefBool(f)
+	case "is_contract":
+		o.IsContract, err = briefBool(f)
+	case "is_batch":
+		o.IsBatch, err = briefBool(f)
+	case "is_event":
+		o.IsEvent, err = briefBool(f)
+	case "is_internal":
+		o.IsInternal, err = briefBool(f)
+	case "gas_limit":
+		o.GasLimit, err = briefInt64(f)
+	case "gas_used":
+		o.GasUsed, err = briefInt64(f)
+	case "storage_limit":
+		o.StorageLimit, err = briefInt64(f)
+	case "storage_paid":
+		o.StoragePaid, err = briefInt64(f)
+	case "volume":
+		o.Volume, err = briefFloat64(f)
+	case "fee":
+		o.Fee, err = briefFloat64(f)
+	case "reward":
+		o.Reward, err = briefFloat64(f)
+	case "deposit":
+		o.Deposit, err = briefFloat64(f)
+	case "burned":
+		o.Burned, err = briefFloat64(f)
+	case "days_destroyed":
+		o.TDD, err = briefFloat64(f)
+	case "sender_id":
+		o.SenderId, err = briefUint64(f)
+	case "receiver_id":
+		o.ReceiverId, err = briefUint64(f)
+	case "creator_id":
+		o.CreatorId, err = briefUint64(f)
+	case "baker_id":
+		o.BakerId, err = briefUint64(f)
+	case "sender":
+		o.Sender, err = tezos.ParseAddress(jsonString(f))
+	case "receiver":
+		o.Receiver, err = tezos.ParseAddress(jsonString(f))
+	case "creator":
+		o.Creator, err = tezos.ParseAddress(jsonString(f))
+	case "baker":
+		o.Baker, err = tezos.ParseAddress(jsonString(f))
+	case "previous_baker":
+		o.PrevBaker, err = tezos.ParseAddress(jsonString(f))
+	case "source":
+		o.Source, err = tezos.ParseAddress(jsonString(f))
+	case "offender":
+		o.Offender, err = tezos.ParseAddress(jsonString(f))
+	case "accuser":
+		o.Accuser, err = tezos.ParseAddress(jsonString(f))
+	case "data":
+		o.Data, err = json.Marshal(f)
+	case "errors":
+		o.Errors, err = json.Marshal(f)
+	case "power":
+		o.Power, err = briefInt(f)
+	case "limit":
+		var v float64
+		if v, err = briefFloat64(f); err == nil {
+			o.Limit = &v
+		}
+	case "confirmations":
+		o.Confirmations, err = briefInt64(f)
+	case "batch_volume":
+		o.BatchVolume, err = briefFloat64(f)
+	case "n_ops":
+		o.NOps, err = briefInt(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Block. It returns false
+// for columns which need a hand-written decoder.
+func (b *Block) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		b.RowId, err = briefUint64(f)
+	case "hash":
+		b.Hash, err = tezos.ParseBlockHash(jsonString(f))
+	case "predecessor":
+		var v tezos.BlockHash
+		if v, err = tezos.ParseBlockHash(jsonString(f)); err == nil {
+			b.ParentHash = &v
+		}
+	case "successor":
+		var v tezos.BlockHash
+		if v, err = tezos.ParseBlockHash(jsonString(f)); err == nil {
+			b.FollowerHash = &v
+		}
+	case "time":
+		b.Timestamp, err = briefTime(f)
+	case "height":
+		b.Height, err = briefInt64(f)
+	case "cycle":
+		b.Cycle, err = briefInt64(f)
+	case "is_cycle_snapshot":
+		b.IsCycleSnapshot, err = briefBool(f)
+	case "solvetime":
+		b.Solvetime, err = briefInt(f)
+	case "version":
+		b.Version, err = briefInt(f)
+	case "round":
+		b.Round, err = briefInt(f)
+	case "nonce":
+		b.Nonce = jsonString(f)
+	case "voting_period_kind":
+		b.VotingPeriodKind = tezos.ParseVotingPeriod(jsonString(f))
+	case "baker_id":
+		b.BakerId, err = briefUint64(f)
+	case "baker":
+		b.Baker, err = tezos.ParseAddress(jsonString(f))
+	case "proposer_id":
+		b.ProposerId, err = briefUint64(f)
+	case "proposer":
+		b.Proposer, err = tezos.ParseAddress(jsonString(f))
+	case "n_endorsed_slots":
+		b.NSlotsEndorsed, err = briefInt(f)
+	case "n_ops_applied":
+		b.NOpsApplied, err = briefInt(f)
+	case "n_ops_failed":
+		b.NOpsFailed, err = briefInt(f)
+	case "n_contract_calls":
+		b.NContractCalls, err = briefInt(f)
+	case "n_events":
+		b.NEvents, err = briefInt(f)
+	case "volume":
+		b.Volume, err = briefFloat64(f)
+	case "fee":
+		b.Fee, err = briefFloat64(f)
+	case "reward":
+		b.Reward, err = briefFloat64(f)
+	case "deposit":
+		b.Deposit, err = briefFloat64(f)
+	case "activated_supply":
+		b.ActivatedSupply, err = briefFloat64(f)
+	case "minted_supply":
+		b.MintedSupply, err = briefFloat64(f)
+	case "burned_supply":
+		b.BurnedSupply, err = briefFloat64(f)
+	case "n_accounts":
+		b.SeenAccounts, err = briefInt(f)
+	case "n_new_accounts":
+		b.NewAccounts, err = briefInt(f)
+	case "n_new_contracts":
+		b.NewContracts, err = briefInt(f)
+	case "n_cleared_accounts":
+		b.ClearedAccounts, err = briefInt(f)
+	case "n_funded_accounts":
+		b.FundedAccounts, err = briefInt(f)
+	case "gas_limit":
+		b.GasLimit, err = briefInt64(f)
+	case "gas_used":
+		b.GasUsed, err = briefInt64(f)
+	case "storage_paid":
+		b.StoragePaid, err = briefInt64(f)
+	case "pct_account_reuse":
+		b.PctAccountReuse, err = briefFloat64(f)
+	case "lb_esc_vote":
+		b.LbEscapeVote, err = briefBool(f)
+	case "lb_esc_ema":
+		b.LbEscapeEma, err = briefInt64(f)
+	case "protocol":
+		b.Protocol, err = tezos.ParseProtocolHash(jsonString(f))
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Account. It returns false
+// for columns which need a hand-written decoder.
+func (a *Account) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		a.RowId, err = briefUint64(f)
+	case "address":
+		a.Address, err = tezos.ParseAddress(jsonString(f))
+	case "address_type":
+		a.AddressType = tezos.ParseAddressType(jsonString(f))
+	case "pubkey":
+		a.Pubkey, err = tezos.ParseKey(jsonString(f))
+	case "counter":
+		a.Counter, err = briefInt64(f)
+	case "baker_id":
+		a.BakerId, err = briefUint64(f)
+	case "baker":
+		var v tezos.Address
+		if v, err = tezos.ParseAddress(jsonString(f)); err == nil {
+			a.Baker = &v
+		}
+	case "creator_id":
+		a.CreatorId, err = briefUint64(f)
+	case "creator":
+		var v tezos.Address
+		if v, err = tezos.ParseAddress(jsonString(f)); err == nil {
+			a.Creator = &v
+		}
+	case "first_in":
+		a.FirstIn, err = briefInt64(f)
+	case "first_out":
+		a.FirstOut, err = briefInt64(f)
+	case "first_seen":
+		a.FirstSeen, err = briefInt64(f)
+	case "last_in":
+		a.LastIn, err = briefInt64(f)
+	case "last_out":
+		a.LastOut, err = briefInt64(f)
+	case "last_seen":
+		a.LastSeen, err = briefInt64(f)
+	case "first_seen_time":
+		a.FirstSeenTime, err = briefTime(f)
+	case "last_seen_time":
+		a.LastSeenTime, err = briefTime(f)
+	case "first_in_time":
+		a.FirstInTime, err = briefTime(f)
+	case "last_in_time":
+		a.LastInTime, err = briefTime(f)
+	case "first_out_time":
+		a.FirstOutTime, err = briefTime(f)
+	case "last_out_time":
+		a.LastOutTime, err = briefTime(f)
+	case "delegated_since":
+		a.DelegatedSince, err = briefInt64(f)
+	case "delegated_since_time":
+		a.DelegatedSinceTime, err = briefTime(f)
+	case "total_received":
+		a.TotalReceived, err = briefFloat64(f)
+	case "total_sent":
+		a.TotalSent, err = briefFloat64(f)
+	case "total_burned":
+		a.TotalBurned, err = briefFloat64(f)
+	case "total_fees_paid":
+		a.TotalFeesPaid, err = briefFloat64(f)
+	case "unclaimed_balance":
+		a.UnclaimedBalance, err = briefFloat64(f)
+	case "spendable_balance":
+		a.SpendableBalance, err = briefFloat64(f)
+	case "is_funded":
+		a.IsFunded, err = briefBool(f)
+	case "is_activated":
+		a.IsActivated, err = briefBool(f)
+	case "is_delegated":
+		a.IsDelegated, err = briefBool(f)
+	case "is_revealed":
+		a.IsRevealed, err = briefBool(f)
+	case "is_baker":
+		a.IsBaker, err = briefBool(f)
+	case "is_contract":
+		a.IsContract, err = briefBool(f)
+	case "n_ops":
+		a.NOps, err = briefInt(f)
+	case "n_ops_failed":
+		a.NOpsFailed, err = briefInt(f)
+	case "n_tx":
+		a.NTx, err = briefInt(f)
+	case "n_delegation":
+		a.NDelegation, err = briefInt(f)
+	case "n_origination":
+		a.NOrigination, err = briefInt(f)
+	case "n_constants":
+		a.NConstants, err = briefInt(f)
+	case "token_gen_min":
+		a.TokenGenMin, err = briefInt64(f)
+	case "token_gen_max":
+		a.TokenGenMax, err = briefInt64(f)
+	case "lifetime_rewards":
+		a.LifetimeRewards, err = briefFloat64(f)
+	case "pending_rewards":
+		a.PendingRewards, err = briefFloat64(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into BigmapRow. It returns false
+// for columns which need a hand-written decoder.
+func (b *BigmapRow) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		b.RowId, err = briefUint64(f)
+	case "contract":
+		b.Contract, err = tezos.ParseAddress(jsonString(f))
+	case "account_id":
+		b.AccountId, err = briefUint64(f)
+	case "bigmap_id":
+		b.BigmapId, err = briefInt64(f)
+	case "n_updates":
+		b.NUpdates, err = briefInt64(f)
+	case "n_keys":
+		b.NKeys, err = briefInt64(f)
+	case "alloc_height":
+		b.AllocHeight, err = briefInt64(f)
+	case "alloc_time":
+		b.AllocTime, err = briefTime(f)
+	case "alloc_block":
+		b.AllocBlock, err = tezos.ParseBlockHash(jsonString(f))
+	case "update_height":
+		b.UpdateHeight, err = briefInt64(f)
+	case "update_time":
+		b.UpdateTime, err = briefTime(f)
+	case "update_block":
+		b.UpdateBlock, err = tezos.ParseBlockHash(jsonString(f))
+	case "key_type":
+		b.KeyType = jsonString(f)
+	case "value_type":
+		b.ValueType = jsonString(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into BigmapUpdateRow. It returns false
+// for columns which need a hand-written decoder.
+func (b *BigmapUpdateRow) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		b.RowId, err = briefUint64(f)
+	case "bigmap_id":
+		b.BigmapId, err = briefInt64(f)
+	case "action":
+		b.Action, err = micheline.ParseDiffAction(jsonString(f))
+	case "key_id":
+		b.KeyId, err = briefUint64(f)
+	case "hash":
+		b.Hash, err = tezos.ParseExprHash(jsonString(f))
+	case "key":
+		b.Key = jsonString(f)
+	case "value":
+		b.Value = jsonString(f)
+	case "height":
+		b.Height, err = briefInt64(f)
+	case "time":
+		b.Time, err = briefTime(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into BigmapValueRow. It returns false
+// for columns which need a hand-written decoder.
+func (b *BigmapValueRow) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		b.RowId, err = briefUint64(f)
+	case "bigmap_id":
+		b.BigmapId, err = briefInt64(f)
+	case "height":
+		b.Height, err = briefInt64(f)
+	case "time":
+		b.Time, err = briefTime(f)
+	case "key_id":
+		b.KeyId, err = briefUint64(f)
+	case "key_hash":
+		b.Hash, err = tezos.ParseExprHash(jsonString(f))
+	case "key":
+		b.Key = jsonString(f)
+	case "value":
+		b.Value = jsonString(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Chain. It returns false
+// for columns which need a hand-written decoder.
+func (c *Chain) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		c.RowId, err = briefUint64(f)
+	case "height":
+		c.Height, err = briefInt64(f)
+	case "cycle":
+		c.Cycle, err = briefInt64(f)
+	case "time":
+		c.Timestamp, err = briefInt64(f)
+	case "total_accounts":
+		c.TotalAccounts, err = briefInt64(f)
+	case "total_contracts":
+		c.TotalContracts, err = briefInt64(f)
+	case "total_ops":
+		c.TotalOps, err = briefInt64(f)
+	case "total_contract_ops":
+		c.TotalContractOps, err = briefInt64(f)
+	case "total_contract_calls":
+		c.TotalContractCalls, err = briefInt64(f)
+	case "total_activations":
+		c.TotalActivations, err = briefInt64(f)
+	case "total_nonce_revelations":
+		c.TotalNonces, err = briefInt64(f)
+	case "total_endorsements":
+		c.TotalEndorsements, err = briefInt64(f)
+	case "total_preendorsements":
+		c.TotalPreendorsements, err = briefInt64(f)
+	case "total_double_bakings":
+		c.TotalDoubleBake, err = briefInt64(f)
+	case "total_double_endorsements":
+		c.TotalDoubleEndorse, err = briefInt64(f)
+	case "total_delegations":
+		c.TotalDelegations, err = briefInt64(f)
+	case "total_reveals":
+		c.TotalReveals, err = briefInt64(f)
+	case "total_originations":
+		c.TotalOriginations, err = briefInt64(f)
+	case "total_transactions":
+		c.TotalTransactions, err = briefInt64(f)
+	case "total_proposals":
+		c.TotalProposals, err = briefInt64(f)
+	case "total_ballots":
+		c.TotalBallots, err = briefInt64(f)
+	case "total_constants":
+		c.TotalConstants, err = briefInt64(f)
+	case "total_set_limits":
+		c.TotalSetLimits, err = briefInt64(f)
+	case "total_storage_bytes":
+		c.TotalStorageBytes, err = briefInt64(f)
+	case "funded_accounts":
+		c.FundedAccounts, err = briefInt64(f)
+	case "dust_accounts":
+		c.DustAccounts, err = briefInt64(f)
+	case "unclaimed_accounts":
+		c.UnclaimedAccounts, err = briefInt64(f)
+	case "total_delegators":
+		c.TotalDelegators, err = briefInt64(f)
+	case "active_delegators":
+		c.ActiveDelegators, err = briefInt64(f)
+	case "inactive_delegators":
+		c.InactiveDelegators, err = briefInt64(f)
+	case "dust_delegators":
+		c.DustDelegators, err = briefInt64(f)
+	case "total_bakers":
+		c.TotalBakers, err = briefInt64(f)
+	case "active_bakers":
+		c.ActiveBakers, err = briefInt64(f)
+	case "inactive_bakers":
+		c.InactiveBakers, err = briefInt64(f)
+	case "zero_bakers":
+		c.ZeroBakers, err = briefInt64(f)
+	case "self_bakers":
+		c.SelfBakers, err = briefInt64(f)
+	case "single_bakers":
+		c.SingleBakers, err = briefInt64(f)
+	case "multi_bakers":
+		c.MultiBakers, err = briefInt64(f)
+	case "rolls":
+		c.Rolls, err = briefInt64(f)
+	case "roll_owners":
+		c.RollOwners, err = briefInt64(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Constant. It returns false
+// for columns which need a hand-written decoder.
+func (c *Constant) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		c.RowId, err = briefUint64(f)
+	case "address":
+		c.Address, err = tezos.ParseExprHash(jsonString(f))
+	case "creator_id":
+		c.CreatorId, err = briefUint64(f)
+	case "creator":
+		c.Creator, err = tezos.ParseAddress(jsonString(f))
+	case "height":
+		c.Height, err = briefInt64(f)
+	case "time":
+		c.Time, err = briefTime(f)
+	case "storage_size":
+		c.StorageSize, err = briefInt64(f)
+	case "features":
+		c.Features = strings.Split(jsonString(f), ",")
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Contract. It returns false
+// for columns which need a hand-written decoder.
+func (c *Contract) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		c.RowId, err = briefUint64(f)
+	case "account_id":
+		c.AccountId, err = briefUint64(f)
+	case "address":
+		c.Address, err = tezos.ParseAddress(jsonString(f))
+	case "creator_id":
+		c.CreatorId, err = briefUint64(f)
+	case "creator":
+		c.Creator, err = tezos.ParseAddress(jsonString(f))
+	case "baker_id":
+		c.BakerId, err = briefUint64(f)
+	case "baker":
+		c.Baker, err = tezos.ParseAddress(jsonString(f))
+	case "first_seen":
+		c.FirstSeen, err = briefInt64(f)
+	case "last_seen":
+		c.LastSeen, err = briefInt64(f)
+	case "first_seen_time":
+		c.FirstSeenTime, err = briefTime(f)
+	case "last_seen_time":
+		c.LastSeenTime, err = briefTime(f)
+	case "storage_size":
+		c.StorageSize, err = briefInt64(f)
+	case "storage_paid":
+		c.StoragePaid, err = briefInt64(f)
+	case "iface_hash":
+		c.InterfaceHash = jsonString(f)
+	case "code_hash":
+		c.CodeHash = jsonString(f)
+	case "storage_hash":
+		c.StorageHash = jsonString(f)
+	case "features":
+		c.Features = strings.Split(jsonString(f), ",")
+	case "interfaces":
+		c.Interfaces = strings.Split(jsonString(f), ",")
+	case "n_calls_success":
+		c.NCallsSuccess, err = briefInt(f)
+	case "n_calls_failed":
+		c.NCallsFailed, err = briefInt(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Status. It returns false
+// for columns which need a hand-written decoder.
+func (s *Status) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "status":
+		s.Status = jsonString(f)
+	case "blocks":
+		s.Blocks, err = briefInt64(f)
+	case "finalized":
+		s.Finalized, err = briefInt64(f)
+	case "indexed":
+		s.Indexed, err = briefInt64(f)
+	case "progress":
+		s.Progress, err = briefFloat64(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into CycleRights. It returns false
+// for columns which need a hand-written decoder.
+func (c *CycleRights) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		c.RowId, err = briefUint64(f)
+	case "cycle":
+		c.Cycle, err = briefInt64(f)
+	case "height":
+		c.Height, err = briefInt64(f)
+	case "account_id":
+		c.AccountId, err = briefUint64(f)
+	case "address":
+		c.Address, err = tezos.ParseAddress(jsonString(f))
+	case "baking_rights":
+		c.Bake, err = hex.DecodeString(jsonString(f))
+	case "endorsing_rights":
+		c.Endorse, err = hex.DecodeString(jsonString(f))
+	case "blocks_baked":
+		c.Baked, err = hex.DecodeString(jsonString(f))
+	case "blocks_endorsed":
+		c.Endorsed, err = hex.DecodeString(jsonString(f))
+	case "seeds_required":
+		c.Seed, err = hex.DecodeString(jsonString(f))
+	case "seeds_revealed":
+		c.Seeded, err = hex.DecodeString(jsonString(f))
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeBriefColumn decodes brief column col into Snapshot. It returns false
+// for columns which need a hand-written decoder.
+func (s *Snapshot) decodeBriefColumn(col string, f interface{}) (ok bool, err error) {
+	switch col {
+	case "row_id":
+		s.RowId, err = briefUint64(f)
+	case "height":
+		s.Height, err = briefInt64(f)
+	case "cycle":
+		s.Cycle, err = briefInt64(f)
+	case "is_selected":
+		s.IsSelected, err = briefBool(f)
+	case "time":
+		s.Timestamp, err = briefTime(f)
+	case "index":
+		s.Index, err = briefInt64(f)
+	case "rolls":
+		s.Rolls, err = briefInt64(f)
+	case "account_id":
+		s.AccountId, err = briefUint64(f)
+	case "address":
+		s.Address, err = tezos.ParseAddress(jsonString(f))
+	case "baker_id":
+		s.BakerId, err = briefUint64(f)
+	case "baker":
+		s.Baker, err = tezos.ParseAddress(jsonString(f))
+	case "is_baker":
+		s.IsBaker, err = briefBool(f)
+	case "is_active":
+		s.IsActive, err = briefBool(f)
+	case "balance":
+		s.Balance, err = briefFloat64(f)
+	case "delegated":
+		s.Delegated, err = briefFloat64(f)
+	case "n_delegations":
+		s.NDelegations, err = briefInt64(f)
+	case "since":
+		s.Since, err = briefInt64(f)
+	case "since_time":
+		s.SinceTime, err = briefTime(f)
+	default:
+		return false, nil
+	}
+	return true, err
+}
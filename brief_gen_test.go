@@ -0,0 +1,230 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+type briefModel interface {
+	UnmarshalJSON([]byte) error
+	decodeBriefColumn(string, interface{}) (bool, error)
+}
+
+// briefModels lists all types with generated brief decoders, it must
+// match the go:generate line in decode.go.
+var briefModels = []struct {
+	name string
+	new  func(cols []string) briefModel
+}{
+	{"Op", func(c []string) briefModel { return (&Op{}).WithColumns(c...) }},
+	{"Block", func(c []string) briefModel { return (&Block{}).WithColumns(c...) }},
+	{"Account", func(c []string) briefModel { return &Account{columns: c} }},
+	{"BigmapRow", func(c []string) briefModel { return &BigmapRow{columns: c} }},
+	{"BigmapUpdateRow", func(c []string) briefModel { return &BigmapUpdateRow{columns: c} }},
+	{"BigmapValueRow", func(c []string) briefModel { return &BigmapValueRow{columns: c} }},
+	{"Chain", func(c []string) briefModel { return &Chain{columns: c} }},
+	{"Constant", func(c []string) briefModel { return &Constant{columns: c} }},
+	{"Contract", func(c []string) briefModel { return &Contract{columns: c} }},
+	{"Status", func(c []string) briefModel { return (&Status{}).WithColumns(c...) }},
+	{"CycleRights", func(c []string) briefModel { return &CycleRights{columns: c} }},
+	{"Snapshot", func(c []string) briefModel { return &Snapshot{columns: c} }},
+}
+
+// briefSample is a field value in brief and in verbose encoding and, for
+// numbers, as a native value decoded from MessagePack.
+type briefSample struct {
+	brief   interface{}
+	verbose interface{}
+	native  interface{}
+}
+
+func hashBytes(n byte) []byte {
+	return bytes.Repeat([]byte{n}, 32)
+}
+
+var briefSamples = map[reflect.Type]briefSample{
+	reflect.TypeOf(int(0)):                     {json.Number("42"), 42, int64(42)},
+	reflect.TypeOf(int64(0)):                   {json.Number("-42"), -42, int64(-42)},
+	reflect.TypeOf(uint64(0)):                  {json.Number("42"), 42, uint64(42)},
+	reflect.TypeOf(float64(0)):                 {json.Number("1.5"), 1.5, 1.5},
+	reflect.TypeOf(false):                      {json.Number("1"), true, int64(1)},
+	reflect.TypeOf(""):                         {"abc", "abc", nil},
+	reflect.TypeOf([]string{}):                 {"a,b", []string{"a", "b"}, nil},
+	reflect.TypeOf(time.Time{}):                {json.Number("1651752000000"), "2022-05-05T12:00:00Z", uint64(1651752000000)},
+	reflect.TypeOf(json.RawMessage{}):          {map[string]interface{}{"a": json.Number("1")}, map[string]int{"a": 1}, nil},
+	reflect.TypeOf(tezos.HexBytes{}):           {"00ff", "00ff", nil},
+	reflect.TypeOf(tezos.Address{}):            {"tz1gfArv665EUkSg2ojMBzcbfwuPxAvqPvjo", "tz1gfArv665EUkSg2ojMBzcbfwuPxAvqPvjo", nil},
+	reflect.TypeOf(tezos.AddressTypeEd25519):   {"ed25519", "ed25519", nil},
+	reflect.TypeOf(tezos.OpStatusApplied):      {"applied", "applied", nil},
+	reflect.TypeOf(tezos.VotingPeriodProposal): {"proposal", "proposal", nil},
+	reflect.TypeOf(micheline.DiffActionUpdate): {"update", "update", nil},
+	reflect.TypeOf(OpTypeTransaction):          {"transaction", "transaction", nil},
+}
+
+func init() {
+	key := tezos.Key{Type: tezos.KeyTypeEd25519, Data: hashBytes(1)}.String()
+	for typ, s := range map[reflect.Type]string{
+		reflect.TypeOf(tezos.Key{}):          key,
+		reflect.TypeOf(tezos.BlockHash{}):    tezos.NewBlockHash(hashBytes(2)).String(),
+		reflect.TypeOf(tezos.OpHash{}):       tezos.NewOpHash(hashBytes(3)).String(),
+		reflect.TypeOf(tezos.ProtocolHash{}): tezos.NewProtocolHash(hashBytes(4)).String(),
+		reflect.TypeOf(tezos.ExprHash{}):     tezos.NewExprHash(hashBytes(5)).String(),
+	} {
+		briefSamples[typ] = briefSample{s, s, nil}
+	}
+}
+
+// TestBriefVerbose decodes the same row through the brief decoder and the
+// verbose JSON decoder and compares all fields with generated decoders.
+func TestBriefVerbose(t *testing.T) {
+	for _, m := range briefModels {
+		t.Run(m.name, func(t *testing.T) {
+			typ := reflect.TypeOf(m.new(nil)).Elem()
+			var (
+				cols   []string
+				fields []int
+				row    []interface{}
+				obj    = make(map[string]interface{})
+			)
+			for i := 0; i < typ.NumField(); i++ {
+				f := typ.Field(i)
+				name := strings.Split(f.Tag.Get("json"), ",")[0]
+				col := name
+				if b, ok := f.Tag.Lookup("brief"); ok {
+					col = b
+				}
+				if f.PkgPath != "" || name == "" || name == "-" || col == "-" {
+					continue
+				}
+				s, ok := briefSamples[f.Type]
+				if !ok {
+					continue
+				}
+				// only columns with a generated decoder
+				if ok, err := m.new(nil).decodeBriefColumn(col, s.brief); !ok || err != nil {
+					if err != nil {
+						t.Errorf("column %s: %v", col, err)
+					}
+					continue
+				}
+				cols = append(cols, col)
+				fields = append(fields, i)
+				row = append(row, s.brief)
+				obj[name] = s.verbose
+			}
+			if len(cols) == 0 {
+				t.Fatal("no generated columns")
+			}
+			buf, _ := json.Marshal(row)
+			brief := m.new(cols)
+			if err := brief.UnmarshalJSON(buf); err != nil {
+				t.Fatalf("brief: %v\n%s", err, buf)
+			}
+			buf, _ = json.Marshal(obj)
+			verbose := m.new(nil)
+			if err := verbose.UnmarshalJSON(buf); err != nil {
+				t.Fatalf("verbose: %v\n%s", err, buf)
+			}
+			bv, vv := reflect.ValueOf(brief).Elem(), reflect.ValueOf(verbose).Elem()
+			for j, i := range fields {
+				a, b := bv.Field(i).Interface(), vv.Field(i).Interface()
+				if ta, ok := a.(time.Time); ok && ta.Equal(b.(time.Time)) {
+					continue
+				}
+				if !reflect.DeepEqual(a, b) {
+					t.Errorf("column %s: brief %v, verbose %v", cols[j], a, b)
+				}
+			}
+			// native values from MessagePack rows decode like brief JSON
+			native := m.new(cols)
+			nv := reflect.ValueOf(native).Elem()
+			for j, i := range fields {
+				s := briefSamples[typ.Field(i).Type]
+				if s.native == nil {
+					continue
+				}
+				if _, err := native.decodeBriefColumn(cols[j], s.native); err != nil {
+					t.Errorf("column %s: native %v", cols[j], err)
+					continue
+				}
+				a, b := bv.Field(i).Interface(), nv.Field(i).Interface()
+				if ta, ok := a.(time.Time); ok && ta.Equal(b.(time.Time)) {
+					continue
+				}
+				if !reflect.DeepEqual(a, b) {
+					t.Errorf("column %s: brief %v, native %v", cols[j], a, b)
+				}
+			}
+		})
+	}
+}
+
+// TestBriefGenUpToDate checks that brief_gen.go matches the output of the
+// go:generate command in decode.go.
+func TestBriefGenUpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	src, err := os.ReadFile("decode.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for _, line := range strings.Split(string(src), "\n") {
+		if strings.HasPrefix(line, "//go:generate go run ./scripts/briefgen ") {
+			args = strings.Fields(strings.TrimPrefix(line, "//go:generate go "))
+		}
+	}
+	if len(args) == 0 {
+		t.Fatal("missing go:generate line for briefgen in decode.go")
+	}
+	out := filepath.Join(t.TempDir(), "brief_gen.go")
+	var types []string
+	for i := 2; i < len(args); i++ {
+		if args[i] == "-o" && i+1 < len(args) {
+			args[i+1] = out
+			i++
+			continue
+		}
+		types = append(types, args[i])
+	}
+	var names []string
+	for _, m := range briefModels {
+		names = append(names, m.name)
+	}
+	if !reflect.DeepEqual(types, names) {
+		t.Errorf("briefModels %v do not match generated types %v", names, types)
+	}
+	cmd := exec.Command(gobin, args...)
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("briefgen: %v\n%s", err, msg)
+	}
+	want, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("brief_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("brief_gen.go is out of date, run go generate")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The And* methods add filters which are checked against the json tags of
+// the table's row model. Unknown columns, filter modes which don't fit the
+// column type and wrong value counts are reported by Check (and thus by
+// Run) before a request is sent. Tables without a known model only check
+// value counts.
+//
+//	q := c.NewOpQuery()
+//	q.AndEq("type", OpTypeTransaction).
+//		AndRange("height", 2000000, 2100000).
+//		AndIn("receiver", addrs)
+
+func (q *tableQuery) AndEq(col string, val interface{}) TableQuery {
+	return q.And(FilterModeEqual, col, val)
+}
+
+func (q *tableQuery) AndNe(col string, val interface{}) TableQuery {
+	return q.And(FilterModeNotEqual, col, val)
+}
+
+func (q *tableQuery) AndGt(col string, val interface{}) TableQuery {
+	return q.And(FilterModeGt, col, val)
+}
+
+func (q *tableQuery) AndGte(col string, val interface{}) TableQuery {
+	return q.And(FilterModeGte, col, val)
+}
+
+func (q *tableQuery) AndLt(col string, val interface{}) TableQuery {
+	return q.And(FilterModeLt, col, val)
+}
+
+func (q *tableQuery) AndLte(col string, val interface{}) TableQuery {
+	return q.And(FilterModeLte, col, val)
+}
+
+// AndIn matches any of vals. A single slice argument is expanded.
+func (q *tableQuery) AndIn(col string, vals ...interface{}) TableQuery {
+	return q.And(FilterModeIn, col, vals...)
+}
+
+// AndNotIn matches none of vals. A single slice argument is expanded.
+func (q *tableQuery) AndNotIn(col string, vals ...interface{}) TableQuery {
+	return q.And(FilterModeNotIn, col, vals...)
+}
+
+// AndRange matches values between from and to (inclusive).
+func (q *tableQuery) AndRange(col string, from, to interface{}) TableQuery {
+	return q.And(FilterModeRange, col, from, to)
+}
+
+func (q *tableQuery) AndRegexp(col string, expr string) TableQuery {
+	return q.And(FilterModeRegexp, col, expr)
+}
+
+// WithTimeRange limits the time column to [from, to]. A zero time leaves
+// that end of the range open.
+func (q *tableQuery) WithTimeRange(from, to time.Time) TableQuery {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return q.fail(fmt.Errorf("filter time: invalid range %s..%s", from, to))
+	}
+	if !from.IsZero() {
+		q.And(FilterModeGte, "time", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		q.And(FilterModeLte, "time", to.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+// WithHeightRange limits the height column to [from, to].
+func (q *tableQuery) WithHeightRange(from, to int64) TableQuery {
+	return q.withIntRange("height", from, to)
+}
+
+// WithCycleRange limits the cycle column to [from, to].
+func (q *tableQuery) WithCycleRange(from, to int64) TableQuery {
+	return q.withIntRange("cycle", from, to)
+}
+
+func (q *tableQuery) withIntRange(col string, from, to int64) TableQuery {
+	if to < from {
+		return q.fail(fmt.Errorf("filter %s: invalid range %d..%d", col, from, to))
+	}
+	return q.And(FilterModeGte, col, from).And(FilterModeLte, col, to)
+}
+
+// fail records the first filter builder error.
+func (q *tableQuery) fail(err error) TableQuery {
+	if q.err == nil {
+		q.err = err
+	}
+	return q
+}
+
+// And adds a checked filter with any mode.
+func (q *tableQuery) And(mode FilterMode, col string, vals ...interface{}) TableQuery {
+	// expand a single slice argument
+	if len(vals) == 1 {
+		if v := reflect.ValueOf(vals[0]); v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			vals = make([]interface{}, v.Len())
+			for i := range vals {
+				vals[i] = v.Index(i).Interface()
+			}
+		}
+	}
+	if err := checkFilter(q.Table, mode, col, len(vals)); err != nil {
+		return q.fail(err)
+	}
+	q.Filter.Add(mode, col, vals...)
+	return q
+}
+
+// checkFilter validates a filter against the row model of table.
+func checkFilter(table string, mode FilterMode, col string, n int) error {
+	if !mode.IsValid() {
+		return fmt.Errorf("filter %s: invalid mode %q", col, mode)
+	}
+	switch mode {
+	case FilterModeIn, FilterModeNotIn:
+		if n == 0 {
+			return fmt.Errorf("filter %s.%s: empty value list", col, mode)
+		}
+	case FilterModeRange:
+		if n != 2 {
+			return fmt.Errorf("filter %s.%s: range requires 2 values, got %d", col, mode, n)
+		}
+	default:
+		if n != 1 {
+			return fmt.Errorf("filter %s.%s: requires 1 value, got %d", col, mode, n)
+		}
+	}
+	m, ok := tableModels[table]
+	if !ok {
+		return nil
+	}
+	typ, ok := columnType(m, col)
+	if !ok {
+		return fmt.Errorf("filter %s.%s: unknown column for table %s", col, mode, table)
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		if mode == FilterModeRegexp {
+			return fmt.Errorf("filter %s.%s: invalid mode for time column", col, mode)
+		}
+	case reflect.PtrTo(typ).Implements(textUnmarshalerType) || typ.Kind() == reflect.Struct:
+		// addresses, hashes and enums only support identity
+		switch mode {
+		case FilterModeEqual, FilterModeNotEqual, FilterModeIn, FilterModeNotIn:
+		default:
+			return fmt.Errorf("filter %s.%s: invalid mode for %s column", col, mode, typ)
+		}
+	case typ.Kind() == reflect.Bool:
+		switch mode {
+		case FilterModeEqual, FilterModeNotEqual:
+		default:
+			return fmt.Errorf("filter %s.%s: invalid mode for bool column", col, mode)
+		}
+	case typ.Kind() == reflect.String:
+	default:
+		if mode == FilterModeRegexp {
+			return fmt.Errorf("filter %s.%s: invalid mode for numeric column", col, mode)
+		}
+	}
+	return nil
+}
+
+func (m FilterMode) IsValid() bool {
+	switch m {
+	case FilterModeEqual, FilterModeNotEqual, FilterModeGt, FilterModeGte,
+		FilterModeLt, FilterModeLte, FilterModeIn, FilterModeNotIn,
+		FilterModeRange, FilterModeRegexp:
+		return true
+	}
+	return false
+}
+
+// columnType returns the field type of table column col in model m.
+// Columns flagged notable are not stored in tables.
+func columnType(m interface{}, col string) (reflect.Type, bool) {
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil, false
+	}
+	for _, f := range tinfo.Fields {
+		if f.Alias != col || f.ContainsFlag("notable") {
+			continue
+		}
+		return reflect.Indirect(reflect.ValueOf(m)).Type().FieldByIndex(f.Idx).Type, true
+	}
+	return nil, false
+}
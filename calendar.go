@@ -0,0 +1,166 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// ICSOptions controls iCalendar export of rights.
+type ICSOptions struct {
+	Name      string          // calendar name
+	Endorsing bool            // include endorsing rights, there is one per block
+	Alarms    []time.Duration // reminders before each event
+}
+
+var DefaultICSOptions = ICSOptions{
+	Name:   "Tezos baking rights",
+	Alarms: []time.Duration{10 * time.Minute},
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// GetUpcomingRights lists a baker's rights above the current tip for the
+// current and the next cycles.
+func (c *Client) GetUpcomingRights(ctx context.Context, baker tezos.Address, cycles int) ([]Right, error) {
+	tip, err := c.GetTip(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.listRightsAfter(ctx, baker, tip, cycles)
+}
+
+func (c *Client) listRightsAfter(ctx context.Context, baker tezos.Address, tip *Tip, cycles int) ([]Right, error) {
+	q := c.NewCycleRightsQuery()
+	q.WithFilter(FilterModeEqual, "address", baker).
+		WithFilter(FilterModeRange, "cycle", tip.Cycle, tip.Cycle+int64(cycles))
+	list, err := q.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Right, 0)
+	for _, r := range list.Rows {
+		n := len(r.Bake) * 8
+		if l := len(r.Endorse) * 8; l > n {
+			n = l
+		}
+		for pos := 0; pos < n; pos++ {
+			height := r.Height + int64(pos)
+			if height <= tip.Height {
+				continue
+			}
+			if v, ok := r.RightAt(height, tezos.RightTypeBaking); ok {
+				res = append(res, v)
+			}
+			if v, ok := r.RightAt(height, tezos.RightTypeEndorsing); ok {
+				res = append(res, v)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ExportRightsICS writes a baker's upcoming rights as iCalendar file with
+// block times estimated from the current tip.
+func (c *Client) ExportRightsICS(ctx context.Context, w io.Writer, baker tezos.Address, cycles int, opts ICSOptions) error {
+	tip, err := c.GetTip(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := c.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	rights, err := c.listRightsAfter(ctx, baker, tip, cycles)
+	if err != nil {
+		return err
+	}
+	return WriteRightsICS(w, rights, cfg, tip, opts)
+}
+
+// WriteRightsICS writes rights as iCalendar events. Each event lasts for
+// one round.
+func WriteRightsICS(w io.Writer, rights []Right, cfg *BlockchainConfig, tip *Tip, opts ICSOptions) error {
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...interface{}) {
+		writeICSLine(bw, fmt.Sprintf(format, args...))
+	}
+	now := time.Now().UTC().Format(icsTimeFormat)
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Blockwatch//tzstats-go %s//EN", ClientVersion)
+	line("CALSCALE:GREGORIAN")
+	if opts.Name != "" {
+		line("X-WR-CALNAME:%s", icsEscape(opts.Name))
+	}
+	for _, r := range rights {
+		if r.IsEndorsing() && !opts.Endorsing {
+			continue
+		}
+		start := r.EstimateTime(cfg, tip).UTC()
+		end := start.Add(RoundDuration(cfg, r.Round))
+		kind := "Baking"
+		if r.IsEndorsing() {
+			kind = "Endorsing"
+		}
+		line("BEGIN:VEVENT")
+		line("UID:%s-%d-%s@tzstats.com", strings.ToLower(kind), r.Height, r.Address)
+		line("DTSTAMP:%s", now)
+		line("DTSTART:%s", start.Format(icsTimeFormat))
+		line("DTEND:%s", end.Format(icsTimeFormat))
+		line("SUMMARY:%s", icsEscape(fmt.Sprintf("%s right at block %d", kind, r.Height)))
+		line("DESCRIPTION:%s", icsEscape(fmt.Sprintf("Cycle %d, round %d, baker %s. Estimated time.", r.Cycle, r.Round, r.Address)))
+		for _, a := range opts.Alarms {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:%s", icsEscape(fmt.Sprintf("%s right at block %d", kind, r.Height)))
+			line("TRIGGER:-PT%dS", int64(a/time.Second))
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsLineLen is the maximum length of an iCalendar content line in octets
+// without the line break.
+const icsLineLen = 75
+
+// writeICSLine writes a content line and folds it into continuation lines
+// starting with a space when it is longer than icsLineLen (RFC 5545
+// section 3.1). Lines are not split inside UTF-8 sequences.
+func writeICSLine(w *bufio.Writer, s string) {
+	max := icsLineLen
+	for len(s) > max {
+		n := max
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		w.WriteString(s[:n])
+		w.WriteString("\r\n ")
+		s = s[n:]
+		// the leading space counts towards the line length
+		max = icsLineLen - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// String returns the query in canonical form: the request url with
+// arguments sorted by name and sorted values of in and nin filters.
+// Queries which send the same requests have equal strings, so it is safe
+// to use as key for caches and request deduplication. Filter expressions
+// which need several requests are listed as sorted request urls separated
+// by " | ". Credentials and timeouts are not part of the string.
+func (q tableQuery) String() string {
+	if q.prep == nil && q.expr != nil {
+		if bs, err := q.filterBranches(); err == nil && len(bs) > 1 {
+			urls := make([]string, len(bs))
+			for i, b := range bs {
+				urls[i] = canonicalUrl(q.urlWith(b))
+			}
+			sort.Strings(urls)
+			return strings.Join(urls, " | ")
+		}
+	}
+	return canonicalUrl(q.Url())
+}
+
+// MarshalText returns the canonical query string. Invalid queries return
+// an error.
+func (q tableQuery) MarshalText() ([]byte, error) {
+	if err := q.Check(); err != nil {
+		return nil, err
+	}
+	return []byte(q.String()), nil
+}
+
+// canonicalUrl sorts query arguments and list filter values of u.
+func canonicalUrl(u string) string {
+	i := strings.IndexByte(u, '?')
+	if i < 0 {
+		return u
+	}
+	vals, err := url.ParseQuery(u[i+1:])
+	if err != nil {
+		return u
+	}
+	for k, v := range vals {
+		if !strings.HasSuffix(k, "."+string(FilterModeIn)) && !strings.HasSuffix(k, "."+string(FilterModeNotIn)) {
+			continue
+		}
+		for j, s := range v {
+			v[j] = sortedList(s)
+		}
+	}
+	// Encode sorts by key
+	return u[:i] + "?" + vals.Encode()
+}
+
+// sortedList sorts and deduplicates a comma separated list.
+func sortedList(s string) string {
+	items := strings.Split(s, ",")
+	sort.Strings(items)
+	n := 0
+	for j, v := range items {
+		if j > 0 && v == items[n-1] {
+			continue
+		}
+		items[n] = v
+		n++
+	}
+	return strings.Join(items[:n], ",")
+}
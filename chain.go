@@ -8,7 +8,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
 )
 
 type Chain struct {
@@ -106,8 +105,8 @@ func (a *Chain) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return a.UnmarshalJSONBrief(data)
 	}
-	type Alias *Chain
-	return json.Unmarshal(data, Alias(a))
+	type Alias Chain
+	return json.Unmarshal(data, (*Alias)(a))
 }
 
 func (c *Chain) UnmarshalJSONBrief(data []byte) error {
@@ -119,92 +118,15 @@ func (c *Chain) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, c.columns); err != nil {
+		return err
+	}
 	for i, v := range c.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			cc.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "height":
-			cc.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "cycle":
-			cc.Cycle, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "time":
-			cc.Timestamp, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_accounts":
-			cc.TotalAccounts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_contracts":
-			cc.TotalContracts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_ops":
-			cc.TotalOps, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_contract_ops":
-			cc.TotalContractOps, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_contract_calls":
-			cc.TotalContractCalls, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_activations":
-			cc.TotalActivations, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_nonce_revelations":
-			cc.TotalNonces, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_endorsements":
-			cc.TotalEndorsements, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_double_bakings":
-			cc.TotalDoubleBake, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_double_endorsements":
-			cc.TotalDoubleEndorse, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_delegations":
-			cc.TotalDelegations, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_reveals":
-			cc.TotalReveals, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_originations":
-			cc.TotalOriginations, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_transactions":
-			cc.TotalTransactions, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_proposals":
-			cc.TotalProposals, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_ballots":
-			cc.TotalBallots, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_constants":
-			cc.TotalConstants, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_set_limits":
-			cc.TotalSetLimits, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_storage_bytes":
-			cc.TotalStorageBytes, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "funded_accounts":
-			cc.FundedAccounts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "dust_accounts":
-			cc.DustAccounts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "unclaimed_accounts":
-			cc.UnclaimedAccounts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_delegators":
-			cc.TotalDelegators, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "active_delegators":
-			cc.ActiveDelegators, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "inactive_delegators":
-			cc.InactiveDelegators, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "dust_delegators":
-			cc.DustDelegators, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "total_bakers":
-			cc.TotalBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "active_bakers":
-			cc.ActiveBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "inactive_bakers":
-			cc.InactiveBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "zero_bakers":
-			cc.ZeroBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "self_bakers":
-			cc.SelfBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "single_bakers":
-			cc.SingleBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "multi_bakers":
-			cc.MultiBakers, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "rolls":
-			cc.Rolls, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "roll_owners":
-			cc.RollOwners, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		}
-		if err != nil {
+		if _, err := cc.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
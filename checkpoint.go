@@ -0,0 +1,187 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore persists named progress cursors so that long running
+// consumers can resume where they stopped.
+type CheckpointStore interface {
+	LoadCheckpoint(ctx context.Context, name string) (uint64, error)
+	SaveCheckpoint(ctx context.Context, name string, cursor uint64) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory. It is useful for tests
+// and for consumers which don't need to survive restarts.
+type MemoryCheckpointStore struct {
+	mu sync.Mutex
+	m  map[string]uint64
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		m: make(map[string]uint64),
+	}
+}
+
+func (s *MemoryCheckpointStore) LoadCheckpoint(_ context.Context, name string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[name], nil
+}
+
+func (s *MemoryCheckpointStore) SaveCheckpoint(_ context.Context, name string, cursor uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[name] = cursor
+	return nil
+}
+
+// FileCheckpointStore keeps checkpoints in a JSON file. The file is
+// replaced atomically on every save, so a crash never leaves it corrupt.
+type FileCheckpointStore struct {
+	mu   sync.Mutex
+	path string
+	m    map[string]uint64
+}
+
+// NewFileCheckpointStore opens or creates the checkpoint file at path.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	s := &FileCheckpointStore{
+		path: path,
+		m:    make(map[string]uint64),
+	}
+	buf, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &s.m); err != nil {
+		return nil, fmt.Errorf("checkpoint file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileCheckpointStore) LoadCheckpoint(_ context.Context, name string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[name], nil
+}
+
+func (s *FileCheckpointStore) SaveCheckpoint(_ context.Context, name string, cursor uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[name] = cursor
+	buf, err := json.MarshalIndent(s.m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// SQLCheckpointStore keeps checkpoints in a database table with columns
+// name and cursor_id. Queries use ? placeholders, call UseDollarParams for
+// databases like PostgreSQL which expect $1.
+type SQLCheckpointStore struct {
+	db     *sql.DB
+	table  string
+	dollar bool
+}
+
+func NewSQLCheckpointStore(db *sql.DB, table string) *SQLCheckpointStore {
+	return &SQLCheckpointStore{
+		db:    db,
+		table: table,
+	}
+}
+
+func (s *SQLCheckpointStore) UseDollarParams() *SQLCheckpointStore {
+	s.dollar = true
+	return s
+}
+
+// Init creates the checkpoint table if it does not exist.
+func (s *SQLCheckpointStore) Init(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+
+		" (name VARCHAR(255) NOT NULL PRIMARY KEY, cursor_id BIGINT NOT NULL)")
+	return err
+}
+
+func (s *SQLCheckpointStore) param(n int) string {
+	if s.dollar {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (s *SQLCheckpointStore) LoadCheckpoint(ctx context.Context, name string) (uint64, error) {
+	var cursor int64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT cursor_id FROM "+s.table+" WHERE name = "+s.param(1),
+		name,
+	).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return uint64(cursor), err
+}
+
+// SaveCheckpoint updates or inserts the checkpoint in a transaction.
+func (s *SQLCheckpointStore) SaveCheckpoint(ctx context.Context, name string, cursor uint64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var found int
+	err = tx.QueryRowContext(ctx,
+		"SELECT 1 FROM "+s.table+" WHERE name = "+s.param(1),
+		name,
+	).Scan(&found)
+	switch err {
+	case nil:
+		_, err = tx.ExecContext(ctx,
+			"UPDATE "+s.table+" SET cursor_id = "+s.param(1)+" WHERE name = "+s.param(2),
+			int64(cursor), name,
+		)
+	case sql.ErrNoRows:
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO "+s.table+" (name, cursor_id) VALUES ("+s.param(1)+", "+s.param(2)+")",
+			name, int64(cursor),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
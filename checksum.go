@@ -0,0 +1,253 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// RowChecksum returns a stable SHA-256 hash over the canonical JSON
+// encoding of a decoded row. Struct fields are encoded in declaration order
+// and map keys sorted, so equal rows always produce equal checksums.
+func RowChecksum(row interface{}) (string, error) {
+	buf, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(buf)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// PageChecksum covers one page of rows as written to a sink.
+type PageChecksum struct {
+	Index    int      `json:"index"`
+	Kind     string   `json:"kind"` // ops or blocks
+	FirstId  uint64   `json:"first_id"`
+	LastId   uint64   `json:"last_id"`
+	Rows     int      `json:"rows"`
+	Checksum string   `json:"checksum"`
+	RowSums  []string `json:"row_checksums,omitempty"`
+}
+
+// ExportManifest records checksums for all pages of an export. The
+// manifest checksum chains page checksums in order, so dropped, reordered
+// or modified pages are detected.
+type ExportManifest struct {
+	Created  time.Time      `json:"created"`
+	From     int64          `json:"from"`
+	To       int64          `json:"to"`
+	Rows     int64          `json:"rows"`
+	Pages    []PageChecksum `json:"pages"`
+	Checksum string         `json:"checksum"`
+}
+
+// ReadExportManifest decodes a manifest written with WriteTo.
+func ReadExportManifest(r io.Reader) (*ExportManifest, error) {
+	m := &ExportManifest{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *ExportManifest) WriteTo(w io.Writer) (int64, error) {
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(buf, '\n'))
+	return int64(n), err
+}
+
+func (m *ExportManifest) add(p PageChecksum) {
+	p.Index = len(m.Pages)
+	m.Pages = append(m.Pages, p)
+	m.Rows += int64(p.Rows)
+	m.Checksum = m.chain()
+}
+
+func (m *ExportManifest) chain() string {
+	h := sha256.New()
+	for _, p := range m.Pages {
+		io.WriteString(h, p.Checksum)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyOps checks ops against the manifest's op pages. Ops must be in
+// export order.
+func (m *ExportManifest) VerifyOps(ops []*Op) error {
+	rows := make([]interface{}, len(ops))
+	ids := make([]uint64, len(ops))
+	for i, o := range ops {
+		rows[i], ids[i] = o, o.Id
+	}
+	return m.verify("ops", rows, ids)
+}
+
+// VerifyBlocks checks blocks against the manifest's block pages. Blocks
+// must be in export order.
+func (m *ExportManifest) VerifyBlocks(blocks []*Block) error {
+	rows := make([]interface{}, len(blocks))
+	ids := make([]uint64, len(blocks))
+	for i, b := range blocks {
+		rows[i], ids[i] = b, b.RowId
+	}
+	return m.verify("blocks", rows, ids)
+}
+
+func (m *ExportManifest) verify(kind string, rows []interface{}, ids []uint64) error {
+	if c := m.chain(); c != m.Checksum {
+		return fmt.Errorf("manifest: checksum mismatch %s != %s", c, m.Checksum)
+	}
+	var pos int
+	for _, p := range m.Pages {
+		if p.Kind != kind {
+			continue
+		}
+		if pos+p.Rows > len(rows) {
+			return fmt.Errorf("manifest: page %d: missing rows, have %d want %d", p.Index, len(rows)-pos, p.Rows)
+		}
+		if p.Rows > 0 && (ids[pos] != p.FirstId || ids[pos+p.Rows-1] != p.LastId) {
+			return fmt.Errorf("manifest: page %d: id range %d..%d does not match %d..%d",
+				p.Index, ids[pos], ids[pos+p.Rows-1], p.FirstId, p.LastId)
+		}
+		sum, err := pageChecksum(rows[pos:pos+p.Rows], nil)
+		if err != nil {
+			return err
+		}
+		if sum != p.Checksum {
+			return fmt.Errorf("manifest: page %d: checksum mismatch", p.Index)
+		}
+		pos += p.Rows
+	}
+	if pos != len(rows) {
+		return fmt.Errorf("manifest: %d extra %s rows", len(rows)-pos, kind)
+	}
+	return nil
+}
+
+// pageChecksum hashes the row checksums of a page and optionally returns
+// them in sums.
+func pageChecksum(rows []interface{}, sums *[]string) (string, error) {
+	h := sha256.New()
+	for _, r := range rows {
+		s, err := RowChecksum(r)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, s)
+		if sums != nil {
+			*sums = append(*sums, s)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ChecksumSink records page checksums of all data written to the wrapped
+// sink in a manifest.
+type ChecksumSink struct {
+	sink     Sink
+	rowSums  bool
+	mu       sync.Mutex
+	manifest ExportManifest
+}
+
+func NewChecksumSink(s Sink) *ChecksumSink {
+	return &ChecksumSink{
+		sink: s,
+		manifest: ExportManifest{
+			Created: time.Now().UTC(),
+		},
+	}
+}
+
+// WithRowChecksums additionally stores per row checksums in the manifest
+// so single corrupt rows can be located.
+func (s *ChecksumSink) WithRowChecksums(b bool) *ChecksumSink {
+	s.rowSums = b
+	return s
+}
+
+func (s *ChecksumSink) WriteOps(ctx context.Context, ops []*Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	rows := make([]interface{}, len(ops))
+	for i, o := range ops {
+		rows[i] = o
+	}
+	if err := s.record("ops", rows, ops[0].Id, ops[len(ops)-1].Id); err != nil {
+		return err
+	}
+	return s.sink.WriteOps(ctx, ops)
+}
+
+func (s *ChecksumSink) WriteBlocks(ctx context.Context, blocks []*Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	rows := make([]interface{}, len(blocks))
+	for i, b := range blocks {
+		rows[i] = b
+	}
+	if err := s.record("blocks", rows, blocks[0].RowId, blocks[len(blocks)-1].RowId); err != nil {
+		return err
+	}
+	return s.sink.WriteBlocks(ctx, blocks)
+}
+
+func (s *ChecksumSink) Flush(ctx context.Context) error {
+	return s.sink.Flush(ctx)
+}
+
+func (s *ChecksumSink) record(kind string, rows []interface{}, first, last uint64) error {
+	p := PageChecksum{
+		Kind:    kind,
+		FirstId: first,
+		LastId:  last,
+		Rows:    len(rows),
+	}
+	var sums *[]string
+	if s.rowSums {
+		sums = &p.RowSums
+	}
+	sum, err := pageChecksum(rows, sums)
+	if err != nil {
+		return err
+	}
+	p.Checksum = sum
+	s.mu.Lock()
+	s.manifest.add(p)
+	s.mu.Unlock()
+	return nil
+}
+
+// Manifest returns a copy of the current manifest.
+func (s *ChecksumSink) Manifest() *ExportManifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.manifest
+	m.Pages = append([]PageChecksum(nil), m.Pages...)
+	return &m
+}
+
+// ExportOpsWithManifest works like ExportOps and returns a manifest with
+// checksums of all exported pages.
+func (c *Client) ExportOpsWithManifest(ctx context.Context, from, to int64, shards int, sink Sink) (*ExportManifest, error) {
+	cs := NewChecksumSink(sink)
+	if err := c.ExportOps(ctx, from, to, shards, cs); err != nil {
+		return nil, err
+	}
+	m := cs.Manifest()
+	m.From, m.To = from, to
+	return m, nil
+}
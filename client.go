@@ -7,12 +7,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"blockwatch.cc/tzgo/tezos"
@@ -20,12 +23,14 @@ import (
 )
 
 var (
-	ClientVersion    = "0.12.0"
-	DefaultLimit     = 50000
-	DefaultCacheSize = 2048
-	userAgent        = "tzstats-go/v" + ClientVersion
-	DefaultClient    *Client
-	IpfsClient       *Client
+	ClientVersion         = "0.12.0"
+	DefaultLimit          = 50000
+	DefaultCacheSize      = 2048
+	DefaultKeyCacheSize   = 1024
+	DefaultMaxConcurrency = 0 // unlimited
+	userAgent             = "tzstats-go/v" + ClientVersion
+	DefaultClient         *Client
+	IpfsClient            *Client
 )
 
 func init() {
@@ -33,11 +38,49 @@ func init() {
 	IpfsClient, _ = NewClient("https://ipfs.tzstats.com/ipfs/", nil)
 }
 
+// Client is safe for concurrent use by multiple goroutines and a single
+// shared client is the recommended way to access the API from a service.
+// Configuration methods (Use*, Set*) must be called before the client is
+// shared.
 type Client struct {
-	httpClient *http.Client
-	params     Params
-	cache      *lru.TwoQueueCache
-	UserAgent  string
+	httpClient    *http.Client
+	params        Params
+	cache         *lru.TwoQueueCache
+	keys          *lru.TwoQueueCache // revealed public keys
+	resolver      *AddressResolver
+	tokens        *TokenRegistry
+	queries       *QueryRegistry
+	audit         *AuditLog
+	retry         *RetryPolicy
+	limiter       Limiter
+	failover      *failover
+	transport     http.RoundTripper
+	middleware    []Middleware
+	metrics       Metrics
+	apiKey        string
+	apiKeyQuery   bool
+	noCompression bool
+	msgpack       bool
+	noCoalesce    bool
+	batch         bool
+	noBatch       int32 // server has no batch endpoint
+	flight        flightGroup
+	etags         *lru.TwoQueueCache
+	logger        Logger
+	hedgeDelay    time.Duration
+	breaker       *CircuitBreaker
+	strictMeta    bool
+	maxRespSize   int64
+	dumpSize      int
+	dumpFn        func(*Exchange)
+	versionMu     sync.Mutex
+	apiVersion    ApiVersion
+	warnMu        sync.Mutex
+	warnings      []Warning
+	rateMu        sync.Mutex
+	rateLimit     RateLimit
+	sem           chan struct{}
+	UserAgent     string
 }
 
 func NewClient(url string, httpClient *http.Client) (*Client, error) {
@@ -46,26 +89,71 @@ func NewClient(url string, httpClient *http.Client) (*Client, error) {
 		return nil, err
 	}
 	if httpClient == nil {
-		httpClient = http.DefaultClient
+		httpClient = &http.Client{
+			Transport: newPoolTransport(DefaultPoolOptions),
+		}
 	}
 	sz := DefaultCacheSize
 	if sz < 2 {
 		sz = 2
 	}
 	cache, _ := lru.New2Q(sz)
-	return &Client{
-		httpClient: httpClient,
-		params:     params,
-		cache:      cache,
-		UserAgent:  userAgent,
-	}, nil
+	ksz := DefaultKeyCacheSize
+	if ksz < 2 {
+		ksz = 2
+	}
+	keys, _ := lru.New2Q(ksz)
+	c := &Client{
+		httpClient:  httpClient,
+		params:      params,
+		cache:       cache,
+		keys:        keys,
+		maxRespSize: DefaultMaxResponseSize,
+		UserAgent:   userAgent,
+	}
+	c.tokens = NewTokenRegistry(c)
+	c.queries = NewQueryRegistry()
+	c.SetMaxConcurrency(DefaultMaxConcurrency)
+	return c, nil
+}
+
+// SetMaxConcurrency limits the number of in-flight HTTP requests across
+// all goroutines using this client. Zero or negative values disable the limit.
+func (c *Client) SetMaxConcurrency(n int) {
+	if n > 0 {
+		c.sem = make(chan struct{}, n)
+	} else {
+		c.sem = nil
+	}
+}
+
+func (c *Client) MaxConcurrency() int {
+	return cap(c.sem)
 }
 
 func (c *Client) UseScriptCache(cache *lru.TwoQueueCache) {
 	c.cache = cache
 }
 
+// GetJSON calls an arbitrary API endpoint and decodes the JSON response
+// into v. Use it for endpoints which are not wrapped by the SDK yet, and
+// QueryRaw or a RawQuery for unmodelled tables. When v is an io.Writer it
+// receives the response body unmodified.
+func (c *Client) GetJSON(ctx context.Context, path string, params url.Values, v interface{}) error {
+	if len(params) > 0 {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + params.Encode()
+	}
+	return c.get(ctx, path, nil, v)
+}
+
 func (c *Client) get(ctx context.Context, path string, headers http.Header, result interface{}) error {
+	if c.canCoalesce(ctx, headers, result) {
+		return c.getCoalesced(ctx, path, result)
+	}
 	return c.call(ctx, http.MethodGet, path, headers, nil, result)
 }
 
@@ -86,17 +174,75 @@ func (c *Client) getAsync(ctx context.Context, path string, headers http.Header,
 }
 
 func (c *Client) call(ctx context.Context, method, path string, headers http.Header, data, result interface{}) error {
-	return c.callAsync(ctx, method, path, headers, data, result).Receive(ctx)
+	var reqHeaders http.Header
+	if headers != nil {
+		reqHeaders = headers.Clone()
+	}
+	err := c.callRetry(ctx, method, path, headers, data, result)
+	if p, ok := c.withoutMetadata(method, path, err); ok {
+		c.addWarning(ctx, Warning{
+			Code:    299,
+			Text:    "metadata unavailable: " + err.Error(),
+			Request: method + " " + path,
+		})
+		if headers != nil {
+			mergeHeaders(headers, reqHeaders, nil)
+		}
+		return c.callRetry(ctx, method, p, headers, data, result)
+	}
+	return err
+}
+
+func (c *Client) callRetry(ctx context.Context, method, path string, headers http.Header, data, result interface{}) error {
+	policy := c.retryPolicy(ctx)
+	if policy == nil {
+		return c.callAsync(ctx, method, path, headers, data, result).Receive(ctx)
+	}
+	// headers are overwritten with response headers, keep the originals
+	var reqHeaders http.Header
+	if headers != nil {
+		reqHeaders = headers.Clone()
+	}
+	var prev time.Duration
+	for attempt := 0; ; attempt++ {
+		status, err := c.callAsync(ctx, method, path, headers, data, result).receiveStatus(ctx)
+		if ctx.Err() != nil || !policy.canRetry(method, err, attempt) {
+			return err
+		}
+		if status >= 200 && status < 300 {
+			// the body may already be partially delivered
+			return err
+		}
+		p := policy.forError(err)
+		d := p.delay(attempt, prev, err)
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) < d {
+			// the server asks to wait longer than the caller can
+			return err
+		}
+		prev = d
+		log.Debugf("retry %s %s in %s after %v", method, path, d, err)
+		c.logWarn("retrying request", "method", method, "url", path, "attempt", attempt+1, "delay", d, "error", err)
+		if err := p.wait(ctx, d); err != nil {
+			return err
+		}
+		if headers != nil {
+			mergeHeaders(headers, reqHeaders, nil)
+		}
+	}
 }
 
 func (c *Client) callAsync(ctx context.Context, method, path string, headers http.Header, data, result interface{}) FutureResult {
+	ctx, cancel := requestContext(ctx)
+	defer cancel()
 	if headers == nil {
 		headers = make(http.Header)
 	}
 	headers.Set("User-Agent", c.UserAgent)
+	c.setAcceptEncoding(headers)
 	if !strings.HasPrefix(path, "http") {
 		path = c.params.Url(path)
 	}
+	path = c.applyAuth(ctx, path, headers)
 
 	req, err := c.newRequest(ctx, method, path, headers, data, result)
 	if err != nil {
@@ -138,12 +284,12 @@ func (c *Client) newRequest(ctx context.Context, method, path string, headers ht
 	}
 
 	// create http request
-	log.Debugf("%s %s", method, path)
-	req, err := http.NewRequest(method, path, body)
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
-	req.WithContext(ctx)
+	log.Debugf("%s %s", method, redactAuth(req.URL))
+	c.logDebug("request", "method", method, "url", redactAuth(req.URL).String())
 
 	// add content-type header to POST, PUT, PATCH
 	switch method {
@@ -168,22 +314,87 @@ func (c *Client) newRequest(ctx context.Context, method, path string, headers ht
 func (c *Client) handleRequest(req *request) {
 	// only dump content-type application/json
 	log.Trace(newLogClosure(func() string {
-		r, _ := httputil.DumpRequestOut(req.httpRequest, req.httpRequest.Header.Get("Content-Type") == "application/json")
-		return string(r)
+		return dumpRequest(req.httpRequest, req.httpRequest.Header.Get("Content-Type") == "application/json")
 	}))
 
-	resp, err := c.httpClient.Do(req.httpRequest)
+	var audit *auditEntry
+	if c.audit != nil {
+		audit = c.audit.begin(req.httpRequest)
+		defer audit.finish()
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(req.httpRequest.Context()); err != nil {
+			audit.fail(err)
+			req.responseChan <- &response{err: err, request: req.String()}
+			return
+		}
+	}
+
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		case <-req.httpRequest.Context().Done():
+			audit.fail(req.httpRequest.Context().Err())
+			req.responseChan <- &response{err: req.httpRequest.Context().Err(), request: req.String()}
+			return
+		}
+	}
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			audit.fail(err)
+			req.responseChan <- &response{err: err, request: req.String()}
+			return
+		}
+	}
+
+	c.etagRequest(req)
+	start := time.Now()
+	resp, err := c.do(req.httpRequest)
+	if ue, ok := err.(*url.Error); ok {
+		// transport errors contain the request url
+		ue.URL = redactAuth(req.httpRequest.URL).String()
+	}
+	c.observeRequest(req.httpRequest, resp, start)
+	if c.breaker != nil {
+		// timeouts count as failures, a stalled server must open the circuit
+		if errors.Is(req.httpRequest.Context().Err(), context.Canceled) {
+			c.breaker.cancel()
+		} else {
+			c.breaker.record(err == nil && resp.StatusCode < 500)
+		}
+	}
 	if err != nil {
+		c.logWarn("request failed", "url", redactAuth(req.httpRequest.URL).String(), "error", err)
+		audit.fail(err)
 		req.responseChan <- &response{err: err, request: req.String()}
 		return
 	}
+	if err := decompress(resp); err != nil {
+		audit.fail(err)
+		req.responseChan <- &response{err: err, request: req.String()}
+		return
+	}
+	if err := c.limitResponse(req, resp); err != nil {
+		audit.fail(err)
+		req.responseChan <- &response{err: err, request: req.String()}
+		return
+	}
+	audit.wrap(resp)
 	defer resp.Body.Close()
+	c.logDebug("response", "url", redactAuth(req.httpRequest.URL).String(), "status", resp.StatusCode, "duration", time.Since(start))
+	c.etagResponse(req, resp)
 
 	log.Tracef("response: %s", newLogClosure(func() string {
 		s, _ := httputil.DumpResponse(resp, isTextResponse(resp))
 		return string(s)
 	}))
 
+	c.handleWarnings(req, resp.Header)
+	c.updateRateLimit(resp.Header)
+
 	// process as stream when response interface is an io.Writer
 	if resp.StatusCode == http.StatusOK && req.responseVal != nil {
 		if stream, ok := req.responseVal.(io.Writer); ok {
@@ -205,6 +416,23 @@ func (c *Client) handleRequest(req *request) {
 			}
 			return
 		}
+		if s, ok := req.responseVal.(contentTypeSetter); ok {
+			s.setContentType(resp.Header.Get("Content-Type"))
+		}
+		// decode table rows while reading
+		if dec, ok := req.responseVal.(streamDecoder); ok {
+			err := dec.decodeStream(resp.Body)
+			if err != nil {
+				err = fmt.Errorf("unmarshalling reply: %w", err)
+			}
+			req.responseChan <- &response{
+				status:  resp.StatusCode,
+				request: req.String(),
+				headers: mergeHeaders(req.responseHeaders, resp.Header, resp.Trailer),
+				err:     err,
+			}
+			return
+		}
 	}
 
 	// non-stream handling below
@@ -221,17 +449,26 @@ func (c *Client) handleRequest(req *request) {
 		return
 	}
 
+	c.etagStore(req, resp, respBytes)
+
 	// on failure, return error and response (some API's send specific
 	// error codes as details which we cannot parse here; some other APIs
 	// even send 5xx error codes to signal non-error situations)
 	if resp.StatusCode >= 400 {
 		if resp.StatusCode == 429 {
-			// TODO: read rate limit header
 			wait := 5 * time.Second
+			if d, ok := parseRetryAfter(resp.Header); ok {
+				wait = d
+			}
 			err = newRateLimitError(wait, resp)
+		} else if e, ok := newPrunedError(resp, respBytes, req.String()); ok {
+			err = e
+		} else if e, ok := newApiError(resp, respBytes); ok {
+			err = e
 		} else {
 			err = newHttpError(resp, respBytes, req.String())
 		}
+		c.captureExchange(req, resp, respBytes)
 		req.responseChan <- &response{
 			status:  resp.StatusCode,
 			request: req.String(),
@@ -259,6 +496,8 @@ func (c *Client) handleRequest(req *request) {
 			return
 		}
 		err = fmt.Errorf("unmarshalling reply: %w", err)
+		c.logWarn("decode failed", "url", redactAuth(req.httpRequest.URL).String(), "error", err)
+		err = c.decodeError(req, resp, respBytes, err)
 	}
 	req.responseChan <- &response{
 		status:  resp.StatusCode,
@@ -269,11 +508,56 @@ func (c *Client) handleRequest(req *request) {
 	}
 }
 
+// Maximum number of server warnings kept until the next call to Warnings.
+var MaxWarnings = 64
+
+func (c *Client) handleWarnings(req *request, header http.Header) {
+	warnings := parseWarnings(header)
+	if len(warnings) == 0 {
+		return
+	}
+	for _, w := range warnings {
+		w.Request = req.String()
+		c.addWarning(req.httpRequest.Context(), w)
+	}
+}
+
+// addWarning records w in the warning list of ctx or, without list, in
+// the client-wide buffer.
+func (c *Client) addWarning(ctx context.Context, w Warning) {
+	log.Warnf("API warning on %s: %s", w.Request, w)
+	c.logWarn("API warning", "url", w.Request, "code", w.Code, "text", w.Text)
+	if l := warningsFrom(ctx); l != nil {
+		l.add(w)
+		return
+	}
+	c.warnMu.Lock()
+	defer c.warnMu.Unlock()
+	if len(c.warnings) >= MaxWarnings {
+		c.warnings = c.warnings[1:]
+	}
+	c.warnings = append(c.warnings, w)
+}
+
+// Warnings returns and clears deprecation notices and other warnings the
+// API server sent since the last call. Warnings of calls with a context
+// from WithWarnings are only recorded in the context's list. Use
+// WithWarnings to tell warnings of concurrent queries apart.
+func (c *Client) Warnings() []Warning {
+	c.warnMu.Lock()
+	defer c.warnMu.Unlock()
+	w := c.warnings
+	c.warnings = nil
+	return w
+}
+
 func (c *Client) loadCachedContractScript(ctx context.Context, addr tezos.Address) (*ContractScript, error) {
 	if c.cache != nil {
 		if script, ok := c.cache.Get(addr.String()); ok {
+			c.logDebug("cache hit", "cache", "script", "key", addr.String())
 			return script.(*ContractScript), nil
 		}
+		c.logDebug("cache miss", "cache", "script", "key", addr.String())
 	}
 	log.Tracef("Loading contract %s", addr)
 	script, err := c.GetContractScript(ctx, addr, NewContractParams().WithPrim())
@@ -0,0 +1,160 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t testing.TB, h http.HandlerFunc) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := NewClient(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientConcurrent(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Warning", `299 - "deprecated"`)
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				var v struct{ Path string }
+				if err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v); err != nil {
+					t.Error(err)
+					return
+				}
+				if v.Path != "/explorer/tip" {
+					t.Errorf("unexpected response %q", v.Path)
+				}
+				c.Warnings()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClientMaxConcurrency(t *testing.T) {
+	const limit = 3
+	var cur, peak int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&cur, 1)
+		defer atomic.AddInt32(&cur, -1)
+		for {
+			m := atomic.LoadInt32(&peak)
+			if n <= m || atomic.CompareAndSwapInt32(&peak, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	})
+	c.SetMaxConcurrency(limit)
+	if got := c.MaxConcurrency(); got != limit {
+		t.Fatalf("MaxConcurrency: got %d, want %d", got, limit)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v struct{}
+			if err := c.GetJSON(context.Background(), "/explorer/status", nil, &v); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&peak); n > limit {
+		t.Errorf("in-flight requests: got %d, want <= %d", n, limit)
+	}
+}
+
+func TestClientMaxConcurrencyCancel(t *testing.T) {
+	release := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{}`))
+	})
+	defer close(release)
+	c.SetMaxConcurrency(1)
+	go func() {
+		var v struct{}
+		c.GetJSON(context.Background(), "/explorer/status", nil, &v)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the second call waits for a slot until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var v struct{}
+	if err := c.GetJSON(ctx, "/explorer/status", nil, &v); err == nil {
+		t.Fatal("expected error while waiting for a free slot")
+	}
+}
+
+func BenchmarkClientGetJSON(b *testing.B) {
+	c := newTestClient(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"height":1}`))
+	})
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v struct{ Height int64 }
+		if err := c.GetJSON(ctx, "/explorer/tip", nil, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClientGetJSONParallel(b *testing.B) {
+	for _, n := range []int{0, 4} {
+		b.Run("max="+strconv.Itoa(n), func(b *testing.B) {
+			c := newTestClient(b, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"height":1}`))
+			})
+			c.SetMaxConcurrency(n)
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					var v struct{ Height int64 }
+					if err := c.GetJSON(ctx, "/explorer/tip", nil, &v); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParamsWith(b *testing.B) {
+	base := NewOpParams().WithLimit(100).WithMeta()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint64
+		for pb.Next() {
+			i++
+			_ = base.WithCursor(i).Encode()
+		}
+	})
+}
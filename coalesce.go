@@ -0,0 +1,128 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// UseCoalescing enables or disables merging of identical concurrent GET
+// requests. Coalescing is enabled by default. While a GET for a URL is in
+// flight, other callers requesting the same URL with the same credentials
+// wait for its response instead of sending their own request. Each caller
+// decodes the shared response into its own result. A caller whose result
+// fails to decode sends its own request to report the error. Calls with
+// a context from WithWarnings, WithRequestTimeout or WithRetryPolicy are
+// never coalesced.
+func (c *Client) UseCoalescing(enable bool) {
+	c.noCoalesce = !enable
+}
+
+type flightCall struct {
+	done chan struct{}
+	raw  json.RawMessage
+	err  error
+}
+
+// flightGroup deduplicates concurrent calls with equal keys.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn once for all concurrent callers using key. Waiting callers
+// return early when their own context is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (json.RawMessage, error)) (json.RawMessage, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.raw, true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.raw, call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return call.raw, false, call.err
+}
+
+// canCoalesce returns true for GET calls whose caller neither expects
+// response headers nor streams the response and whose context carries no
+// request options.
+func (c *Client) canCoalesce(ctx context.Context, headers http.Header, result interface{}) bool {
+	if c.noCoalesce || headers != nil || result == nil {
+		return false
+	}
+	if warningsFrom(ctx) != nil || ctx.Value(timeoutContextKey{}) != nil || ctx.Value(retryContextKey{}) != nil {
+		return false
+	}
+	switch result.(type) {
+	case io.Writer, streamDecoder:
+		return false
+	}
+	return true
+}
+
+// sharedResult decodes a response into the leading caller's result and
+// keeps the raw response for waiting callers.
+type sharedResult struct {
+	v   interface{}
+	raw json.RawMessage
+}
+
+func (r *sharedResult) UnmarshalJSON(buf []byte) error {
+	r.raw = append(json.RawMessage(nil), buf...)
+	return json.Unmarshal(buf, r.v)
+}
+
+func (c *Client) getCoalesced(ctx context.Context, path string, result interface{}) error {
+	key := path
+	if v, ok := ctx.Value(authContextKey{}).(string); ok {
+		key = v + " " + path
+	}
+	raw, shared, err := c.flight.do(ctx, key, func() (json.RawMessage, error) {
+		// the leader decodes in the request path, so decode errors and
+		// debug dumps are reported as for other calls
+		res := &sharedResult{v: result}
+		err := c.call(ctx, http.MethodGet, path, nil, nil, res)
+		return res.raw, err
+	})
+	if !shared {
+		return err
+	}
+	if raw == nil {
+		if err == nil {
+			return nil
+		}
+		// the leading caller was cancelled, try again on our own
+		if ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return c.call(ctx, http.MethodGet, path, nil, nil, result)
+		}
+		return err
+	}
+	c.logDebug("coalesced request", "url", path)
+	if json.Unmarshal(raw, result) != nil {
+		// request again to report the decode error
+		return c.call(ctx, http.MethodGet, path, nil, nil, result)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesceShared(t *testing.T) {
+	var n int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"height":7}`))
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v struct{ Height int64 }
+			if err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v); err != nil || v.Height != 7 {
+				t.Errorf("got height %d, err %v", v.Height, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n >= 8 {
+		t.Errorf("got %d requests, want fewer than 8", n)
+	}
+}
+
+func TestCoalesceDecodeError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"height":"x"}`))
+	})
+	var dumped int32
+	c.UseDebugDump(0, func(*Exchange) { atomic.AddInt32(&dumped, 1) })
+	var v struct{ Height int64 }
+	err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v)
+	if _, ok := IsDecodeError(err); !ok {
+		t.Errorf("expected decode error, got %v", err)
+	}
+	if dumped != 1 {
+		t.Errorf("got %d dumped exchanges, want 1", dumped)
+	}
+}
+
+func TestCoalesceContextOptions(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Warning", `299 - "deprecated"`)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	})
+	var wg sync.WaitGroup
+	lists := make([]*WarningList, 4)
+	for i := range lists {
+		ctx, l := WithWarnings(context.Background())
+		lists[i] = l
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v struct{}
+			if err := c.GetJSON(ctx, "/explorer/tip", nil, &v); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, l := range lists {
+		if len(l.Warnings()) != 1 {
+			t.Errorf("caller %d did not receive the warning", i)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// exportKind is the column type of a row model field in columnar
+// exports like Arrow and Parquet.
+type exportKind byte
+
+const (
+	exportInt     exportKind = iota // int64
+	exportUint                      // uint64
+	exportFloat                     // float64
+	exportBool                      // bool
+	exportString                    // string, also hashes, addresses and enums
+	exportTime                      // time.Time, zero times are null
+	exportBytes                     // []byte
+	exportStrings                   // []string
+	exportJSON                      // any other type as JSON text
+)
+
+// exportColumn maps a row model field to a typed column.
+type exportColumn struct {
+	Name     string
+	Kind     exportKind
+	Nullable bool // pointer fields
+	idx      []int
+}
+
+// exportColumns returns the column layout of table for cols. Without cols
+// all table columns are used.
+func exportColumns(table string, cols []string) ([]exportColumn, error) {
+	m, ok := tableModels[table]
+	if !ok {
+		return nil, fmt.Errorf("export: unknown table %q", table)
+	}
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		cols = tinfo.FilteredAliases("notable")
+	}
+	typ := reflect.Indirect(reflect.ValueOf(m)).Type()
+	res := make([]exportColumn, 0, len(cols))
+	for _, name := range cols {
+		var f *FieldInfo
+		for i := range tinfo.Fields {
+			if tinfo.Fields[i].Alias == name {
+				f = &tinfo.Fields[i]
+				break
+			}
+		}
+		if f == nil {
+			return nil, fmt.Errorf("export: unknown column %q in table %s", name, table)
+		}
+		ft := typ.FieldByIndex(f.Idx).Type
+		col := exportColumn{
+			Name: name,
+			idx:  f.Idx,
+		}
+		if ft.Kind() == reflect.Ptr {
+			col.Nullable = true
+			ft = ft.Elem()
+		}
+		col.Kind = exportKindOf(ft)
+		if col.Kind == exportTime || col.Kind == exportJSON {
+			col.Nullable = true
+		}
+		res = append(res, col)
+	}
+	return res, nil
+}
+
+func exportKindOf(t reflect.Type) exportKind {
+	switch {
+	case t == timeType:
+		return exportTime
+	case t == rawMessageType:
+		return exportJSON
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t.Name() == "":
+		return exportBytes
+	case t.Implements(textMarshalerType), reflect.PtrTo(t).Implements(textMarshalerType):
+		return exportString
+	case t.Implements(jsonMarshaler), reflect.PtrTo(t).Implements(jsonMarshaler):
+		return exportJSON
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return exportInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return exportUint
+	case reflect.Float32, reflect.Float64:
+		return exportFloat
+	case reflect.Bool:
+		return exportBool
+	case reflect.String:
+		return exportString
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return exportStrings
+		}
+	}
+	return exportJSON
+}
+
+// value returns the column value of row, which must be a struct. The
+// result type follows the column kind, nil is returned for null values.
+func (c exportColumn) value(row reflect.Value) (interface{}, error) {
+	v := row.FieldByIndex(c.idx)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch c.Kind {
+	case exportInt:
+		return v.Int(), nil
+	case exportUint:
+		return v.Uint(), nil
+	case exportFloat:
+		return v.Float(), nil
+	case exportBool:
+		return v.Bool(), nil
+	case exportTime:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil, nil
+		}
+		return t, nil
+	case exportBytes:
+		return v.Bytes(), nil
+	case exportStrings:
+		s := make([]string, v.Len())
+		for i := range s {
+			s[i] = v.Index(i).String()
+		}
+		return s, nil
+	case exportString:
+		if !v.CanAddr() {
+			cp := reflect.New(v.Type()).Elem()
+			cp.Set(v)
+			v = cp
+		}
+		m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+		if !ok {
+			return v.String(), nil
+		}
+		buf, err := m.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", c.Name, err)
+		}
+		return string(buf), nil
+	default:
+		if v.Type() == rawMessageType {
+			if v.Len() == 0 {
+				return nil, nil
+			}
+			return string(v.Bytes()), nil
+		}
+		if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+			return nil, nil
+		}
+		buf, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", c.Name, err)
+		}
+		return string(buf), nil
+	}
+}
+
+// exportRows returns the struct values of rows, which is a row model
+// pointer, a slice of row pointers or a list with a Rows field.
+func exportRows(rows interface{}) ([]reflect.Value, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		if f := v.Elem().FieldByName("Rows"); f.IsValid() && f.Kind() == reflect.Slice {
+			v = f
+		} else {
+			return []reflect.Value{v.Elem()}, nil
+		}
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("export: unsupported row type %T", rows)
+	}
+	res := make([]reflect.Value, 0, v.Len())
+	for i, l := 0, v.Len(); i < l; i++ {
+		row := v.Index(i)
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+		if row.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("export: unsupported row type %T", rows)
+		}
+		res = append(res, row)
+	}
+	return res, nil
+}
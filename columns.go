@@ -0,0 +1,107 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithUncheckedColumns disables column validation, e.g. to request columns
+// added by a newer API release. Unknown op and block columns are decoded
+// into Extra.
+func (q *tableQuery) WithUncheckedColumns() TableQuery {
+	q.anyColumns = true
+	return q
+}
+
+// knownColumns returns all columns the row decoder of table understands.
+func knownColumns(table string) ([]string, bool) {
+	m, ok := tableModels[table]
+	if !ok {
+		return nil, false
+	}
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil, false
+	}
+	cols := tinfo.Aliases()
+	// brief tags name columns which differ from the model's json tags
+	typ := reflect.Indirect(reflect.ValueOf(m)).Type()
+	for _, f := range tinfo.Fields {
+		if name := typ.FieldByIndex(f.Idx).Tag.Get("brief"); name != "" && name != "-" {
+			cols = append(cols, name)
+		}
+	}
+	return cols, true
+}
+
+// checkColumns fails when cols contains columns unknown to the row model
+// of table. Brief rows are decoded by position, so an unknown column would
+// shift all following values.
+func checkColumns(table string, cols []string) error {
+	if len(cols) == 0 {
+		return nil
+	}
+	known, ok := knownColumns(table)
+	if !ok {
+		return nil
+	}
+	set := make(map[string]struct{}, len(known))
+	for _, v := range known {
+		set[v] = struct{}{}
+	}
+	var bad []string
+	for _, v := range cols {
+		if _, ok := set[v]; ok {
+			continue
+		}
+		if s := closestColumn(v, known); s != "" {
+			v = fmt.Sprintf("%s (did you mean %s?)", v, s)
+		}
+		bad = append(bad, v)
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unknown columns for table %s: %s", table, strings.Join(bad, ", "))
+}
+
+// closestColumn returns the known column with the smallest edit distance
+// to col, if any is close enough to be a typo.
+func closestColumn(col string, known []string) string {
+	sort.Strings(known)
+	var (
+		best string
+		dist = 3
+	)
+	for _, v := range known {
+		if d := editDistance(col, v); d < dist {
+			best, dist = v, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// UseCompression enables or disables gzip compressed responses. Compression
+// is enabled by default and independent of the HTTP transport, so it also
+// works with custom transports that don't decompress.
+func (c *Client) UseCompression(enable bool) {
+	c.noCompression = !enable
+}
+
+func (c *Client) setAcceptEncoding(headers http.Header) {
+	if headers.Get("Accept-Encoding") != "" {
+		return
+	}
+	if c.noCompression {
+		headers.Set("Accept-Encoding", "identity")
+	} else {
+		headers.Set("Accept-Encoding", "gzip")
+	}
+}
+
+// decompress replaces a gzip encoded response body with a decoding reader.
+// Responses without body like 204, 304 and replies to HEAD requests are
+// left as is, even when they announce an encoding.
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	switch {
+	case resp.StatusCode == http.StatusNoContent, resp.StatusCode == http.StatusNotModified:
+		return nil
+	case resp.Request != nil && resp.Request.Method == http.MethodHead:
+		return nil
+	case resp.ContentLength == 0:
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// empty body of unknown length
+		return nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	resp.Body = &gzipBody{zr, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -10,7 +10,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -81,8 +80,8 @@ func (a *Constant) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return a.UnmarshalJSONBrief(data)
 	}
-	type Alias *Constant
-	return json.Unmarshal(data, Alias(a))
+	type Alias Constant
+	return json.Unmarshal(data, (*Alias)(a))
 }
 
 func (c *Constant) UnmarshalJSONBrief(data []byte) error {
@@ -94,39 +93,28 @@ func (c *Constant) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, c.columns); err != nil {
+		return err
+	}
 	for i, v := range c.columns {
 		// var t int64
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			cc.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "address":
-			cc.Address, err = tezos.ParseExprHash(f.(string))
-		case "creator_id":
-			cc.CreatorId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "creator":
-			cc.Creator, err = tezos.ParseAddress(f.(string))
-		case "height":
-			cc.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				cc.Time = time.Unix(0, ts*1000000).UTC()
+		if ok, err := cc.decodeBriefColumn(v, f); ok {
+			if err != nil {
+				return err
 			}
-		case "storage_size":
-			cc.StorageSize, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
+			continue
+		}
+		switch v {
 		case "value":
 			var buf []byte
-			buf, err = hex.DecodeString(f.(string))
+			buf, err = hex.DecodeString(jsonString(f))
 			if err == nil {
-				err = cc.Value.UnmarshalBinary(buf)
+				err = safeDecode("value", func() error { return cc.Value.UnmarshalBinary(buf) })
 			}
-		case "features":
-			cc.Features = strings.Split(f.(string), ",")
 		}
 		if err != nil {
 			return err
@@ -145,22 +133,22 @@ func NewConstantParams() ConstantParams {
 }
 
 func (p ConstantParams) WithLimit(v uint) ConstantParams {
-	p.Query.Set("limit", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p ConstantParams) WithOffset(v uint) ConstantParams {
-	p.Query.Set("offset", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p ConstantParams) WithCursor(v uint64) ConstantParams {
-	p.Query.Set("cursor", strconv.FormatUint(v, 10))
+	p.Params = p.Params.With("cursor", strconv.FormatUint(v, 10))
 	return p
 }
 
 func (p ConstantParams) WithOrder(v OrderType) ConstantParams {
-	p.Query.Set("order", string(v))
+	p.Params = p.Params.With("order", string(v))
 	return p
 }
 
@@ -12,7 +12,6 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
-	"strings"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -99,8 +98,8 @@ func (a *Contract) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return a.UnmarshalJSONBrief(data)
 	}
-	type Alias *Contract
-	return json.Unmarshal(data, Alias(a))
+	type Alias Contract
+	return json.Unmarshal(data, (*Alias)(a))
 }
 
 func (c *Contract) UnmarshalJSONBrief(data []byte) error {
@@ -112,66 +111,39 @@ func (c *Contract) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, c.columns); err != nil {
+		return err
+	}
 	for i, v := range c.columns {
 		// var t int64
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			cc.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "account_id":
-			cc.AccountId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "address":
-			cc.Address, err = tezos.ParseAddress(f.(string))
-		case "creator_id":
-			cc.CreatorId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "creator":
-			cc.Creator, err = tezos.ParseAddress(f.(string))
-		case "first_seen":
-			cc.FirstSeen, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "last_seen":
-			cc.LastSeen, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "first_seen_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				cc.FirstSeenTime = time.Unix(0, ts*1000000).UTC()
-			}
-		case "last_seen_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				cc.LastSeenTime = time.Unix(0, ts*1000000).UTC()
+		if ok, err := cc.decodeBriefColumn(v, f); ok {
+			if err != nil {
+				return err
 			}
-		case "storage_size":
-			cc.StorageSize, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "storage_paid":
-			cc.StoragePaid, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
+			continue
+		}
+		switch v {
 		case "script":
 			var buf []byte
-			buf, err = hex.DecodeString(f.(string))
+			buf, err = hex.DecodeString(jsonString(f))
 			if err == nil {
 				cc.Script = &micheline.Script{}
-				err = cc.Script.UnmarshalBinary(buf)
+				err = safeDecode("script", func() error { return cc.Script.UnmarshalBinary(buf) })
 			}
 		case "storage":
 			var buf []byte
-			buf, err = hex.DecodeString(f.(string))
+			buf, err = hex.DecodeString(jsonString(f))
 			if err == nil {
 				cc.Storage = &micheline.Prim{}
-				err = cc.Storage.UnmarshalBinary(buf)
+				err = safeDecode("storage", func() error { return cc.Storage.UnmarshalBinary(buf) })
 			}
-		case "iface_hash":
-			cc.InterfaceHash = f.(string)
-		case "code_hash":
-			cc.CodeHash = f.(string)
-		case "storage_hash":
-			cc.StorageHash = f.(string)
 		case "call_stats":
 			var buf []byte
-			buf, err = hex.DecodeString(f.(string))
+			buf, err = hex.DecodeString(jsonString(f))
 			if err == nil {
 				cc.CallStats = make(map[string]int)
 				if cc.Script != nil {
@@ -189,10 +161,6 @@ func (c *Contract) UnmarshalJSONBrief(data []byte) error {
 					}
 				}
 			}
-		case "features":
-			cc.Features = strings.Split(f.(string), ",")
-		case "interfaces":
-			cc.Interfaces = strings.Split(f.(string), ",")
 		}
 		if err != nil {
 			return err
@@ -314,64 +282,73 @@ func (v ContractValue) Unmarshal(val interface{}) error {
 
 type ContractParams struct {
 	Params
+	dedup bool // client-side only
 }
 
 func NewContractParams() ContractParams {
-	return ContractParams{NewParams()}
+	return ContractParams{Params: NewParams()}
 }
 
 func (p ContractParams) WithLimit(v uint) ContractParams {
-	p.Query.Set("limit", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p ContractParams) WithOffset(v uint) ContractParams {
-	p.Query.Set("offset", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p ContractParams) WithCursor(v uint64) ContractParams {
-	p.Query.Set("cursor", strconv.FormatUint(v, 10))
+	p.Params = p.Params.With("cursor", strconv.FormatUint(v, 10))
 	return p
 }
 
 func (p ContractParams) WithOrder(v OrderType) ContractParams {
-	p.Query.Set("order", string(v))
+	p.Params = p.Params.With("order", string(v))
 	return p
 }
 
 func (p ContractParams) WithBlock(v string) ContractParams {
-	p.Query.Set("block", v)
+	p.Params = p.Params.With("block", v)
 	return p
 }
 
 func (p ContractParams) WithSince(v string) ContractParams {
-	p.Query.Set("since", v)
+	p.Params = p.Params.With("since", v)
 	return p
 }
 
 func (p ContractParams) WithUnpack() ContractParams {
-	p.Query.Set("unpack", "1")
+	p.Params = p.Params.With("unpack", "1")
 	return p
 }
 
 func (p ContractParams) WithPrim() ContractParams {
-	p.Query.Set("prim", "1")
+	p.Params = p.Params.With("prim", "1")
 	return p
 }
 
 func (p ContractParams) WithMeta() ContractParams {
-	p.Query.Set("meta", "1")
+	p.Params = p.Params.With("meta", "1")
 	return p
 }
 
 func (p ContractParams) WithMerge() ContractParams {
-	p.Query.Set("merge", "1")
+	p.Params = p.Params.With("merge", "1")
 	return p
 }
 
 func (p ContractParams) WithStorage() ContractParams {
-	p.Query.Set("storage", "1")
+	p.Params = p.Params.With("storage", "1")
+	return p
+}
+
+// WithDeduplicate removes calls from GetContractCalls results which are
+// also contained in a batch or internal list of another call. See
+// Deduplicate.
+func (p ContractParams) WithDeduplicate() ContractParams {
+	p.dedup = true
 	return p
 }
 
@@ -451,5 +428,58 @@ func (c *Client) GetContractCalls(ctx context.Context, addr tezos.Address, param
 	if err := c.get(ctx, u, nil, &calls); err != nil {
 		return nil, err
 	}
+	if params.dedup {
+		calls = Deduplicate(calls)
+	}
 	return calls, nil
 }
+
+// ContractStats summarizes calls to a contract over its lifetime. Fees are
+// the transaction fees paid by callers, Volume is the amount of tez sent
+// along with calls.
+type ContractStats struct {
+	Address       tezos.Address `json:"address"`
+	FirstCall     int64         `json:"first_call"`
+	FirstCallTime time.Time     `json:"first_call_time"`
+	LastCall      int64         `json:"last_call"`
+	LastCallTime  time.Time     `json:"last_call_time"`
+	NCalls        int           `json:"n_calls"`
+	NCallsFailed  int           `json:"n_calls_failed"`
+	NCallers      int           `json:"n_callers"`
+	Fees          float64       `json:"fees"`
+	Volume        float64       `json:"volume"`
+}
+
+// GetContractStats scans all calls to a contract from the op table and
+// summarizes first and last call, call count, unique callers and fees.
+func (c *Client) GetContractStats(ctx context.Context, addr tezos.Address) (*ContractStats, error) {
+	q := c.NewOpQuery()
+	q.WithFilter(FilterModeEqual, "type", "transaction").
+		WithFilter(FilterModeEqual, "receiver", addr).
+		WithColumns("id", "height", "time", "sender", "is_success", "fee", "volume")
+	stats := &ContractStats{Address: addr}
+	callers := make(map[string]struct{})
+	err := q.Each(ctx, func(ops *OpList) error {
+		for _, o := range ops.Rows {
+			if stats.NCalls == 0 {
+				stats.FirstCall = o.Height
+				stats.FirstCallTime = o.Timestamp
+			}
+			stats.LastCall = o.Height
+			stats.LastCallTime = o.Timestamp
+			stats.NCalls++
+			if !o.IsSuccess {
+				stats.NCallsFailed++
+			}
+			stats.Fees += o.Fee
+			stats.Volume += o.Volume
+			callers[o.Sender.String()] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.NCallers = len(callers)
+	return stats, nil
+}
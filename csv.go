@@ -0,0 +1,233 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Table queries using FormatCSV are decoded into the same list types as
+// JSON queries. CSV cells are converted into brief JSON rows by per-column
+// decoders derived from the table's row model, so all row decoders work
+// unchanged. Lists which decode rows one by one (OpList, BlockList and
+// generic lists) never hold more than one row in memory.
+//
+//	q := c.NewOpQuery()
+//	q.WithFormat(tzstats.FormatCSV)
+//	ops, err := q.Run(ctx)
+
+// rowDecoder is implemented by lists which decode brief rows one by one.
+type rowDecoder interface {
+	decodeRow(v json.RawMessage) error
+}
+
+// csvCell converts a CSV cell into a JSON value and appends it to buf.
+type csvCell func(buf []byte, s string) ([]byte, error)
+
+// csvResult decodes a CSV table response into a list.
+type csvResult struct {
+	columns []string
+	cells   []csvCell
+	version ApiVersion
+	result  interface{}
+}
+
+func newCSVResult(q *tableQuery, result interface{}) *csvResult {
+	var version ApiVersion
+	if q.client != nil {
+		version = q.client.ApiVersion()
+	}
+	return &csvResult{
+		columns: q.Columns,
+		cells:   csvCells(q.Table, q.Columns),
+		version: version,
+		result:  result,
+	}
+}
+
+func (r *csvResult) decodeStream(rd io.Reader) error {
+	fn, done := rowSink(r.result)
+	if err := r.decodeRows(rd, fn); err != nil {
+		return err
+	}
+	return done()
+}
+
+// rowSink returns a function which passes brief rows to result and a
+// function which completes decoding. Rows for lists without a row decoder
+// are buffered and unmarshalled as JSON array.
+func rowSink(result interface{}) (func(json.RawMessage) error, func() error) {
+	if dec, ok := result.(rowDecoder); ok {
+		return dec.decodeRow, func() error { return nil }
+	}
+	buf := []byte{'['}
+	add := func(v json.RawMessage) error {
+		if len(buf) > 1 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, v...)
+		return nil
+	}
+	done := func() error {
+		return json.Unmarshal(append(buf, ']'), result)
+	}
+	return add, done
+}
+
+// decodeRows reads CSV records and calls fn with each record as brief
+// JSON row in query column order. A header record is skipped.
+func (r *csvResult) decodeRows(rd io.Reader, fn func(json.RawMessage) error) error {
+	cr := csv.NewReader(rd)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+	var line int
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line++
+		if line == 1 && r.isHeader(rec) {
+			continue
+		}
+		if len(rec) < len(r.columns) {
+			return fmt.Errorf("csv: short row %d with %d values for %d columns", line, len(rec), len(r.columns))
+		}
+		// rows are not reused since decoders may keep references
+		row := make([]byte, 1, 256)
+		row[0] = '['
+		for i, cell := range r.cells {
+			if i > 0 {
+				row = append(row, ',')
+			}
+			if rec[i] == "" {
+				row = append(row, "null"...)
+				continue
+			}
+			if row, err = cell(row, rec[i]); err != nil {
+				return fmt.Errorf("csv: row %d column %s: %w", line, r.columns[i], err)
+			}
+		}
+		row = append(row, ']')
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *csvResult) isHeader(rec []string) bool {
+	if len(rec) == 0 || len(r.columns) == 0 {
+		return false
+	}
+	return rec[0] == r.columns[0] || rec[0] == r.version.ColumnName(r.columns[0])
+}
+
+// csvCells returns cell decoders for table columns. Columns of unknown
+// tables are detected from their content.
+func csvCells(table string, cols []string) []csvCell {
+	m := tableModels[table]
+	cells := make([]csvCell, len(cols))
+	for i, col := range cols {
+		cells[i] = csvAnyCell
+		if m == nil {
+			continue
+		}
+		typ, ok := columnType(m, col)
+		if !ok {
+			continue
+		}
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		switch {
+		case typ == timeType:
+			cells[i] = csvTimeCell
+		case reflect.PtrTo(typ).Implements(textUnmarshalerType):
+			cells[i] = csvStringCell
+		case typ.Kind() == reflect.Bool:
+			cells[i] = csvBoolCell
+		case typ.Kind() == reflect.String:
+			cells[i] = csvStringCell
+		case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Float64:
+			cells[i] = csvNumberCell
+		default:
+			cells[i] = csvValueCell
+		}
+	}
+	return cells
+}
+
+func csvStringCell(buf []byte, s string) ([]byte, error) {
+	return appendJSONString(buf, s), nil
+}
+
+func csvNumberCell(buf []byte, s string) ([]byte, error) {
+	if !isJSONNumber(s) {
+		return buf, fmt.Errorf("invalid number %q", s)
+	}
+	return append(buf, s...), nil
+}
+
+// brief rows encode bools as numbers
+func csvBoolCell(buf []byte, s string) ([]byte, error) {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return buf, fmt.Errorf("invalid bool %q", s)
+	}
+	if b {
+		return append(buf, '1'), nil
+	}
+	return append(buf, '0'), nil
+}
+
+// brief rows encode times as unix milliseconds
+func csvTimeCell(buf []byte, s string) ([]byte, error) {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return append(buf, s...), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return buf, fmt.Errorf("invalid time %q", s)
+	}
+	return strconv.AppendInt(buf, t.UnixNano()/1000000, 10), nil
+}
+
+// csvValueCell keeps embedded JSON arrays and objects, other values such
+// as hex encoded Micheline are strings.
+func csvValueCell(buf []byte, s string) ([]byte, error) {
+	if (s[0] == '[' || s[0] == '{') && json.Valid([]byte(s)) {
+		return append(buf, bytes.TrimSpace([]byte(s))...), nil
+	}
+	return appendJSONString(buf, s), nil
+}
+
+func csvAnyCell(buf []byte, s string) ([]byte, error) {
+	if isJSONNumber(s) {
+		return append(buf, s...), nil
+	}
+	return csvValueCell(buf, s)
+}
+
+func isJSONNumber(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil && json.Valid([]byte(s))
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build go1.18
+// +build go1.18
+
+package tzstats
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+var (
+	csvOpColumns = []string{
+		"id", "hash", "type", "time", "height", "status", "is_success",
+		"volume", "fee", "sender", "receiver", "entrypoint",
+	}
+	csvBlockColumns = []string{
+		"row_id", "hash", "time", "height", "cycle", "is_cycle_snapshot",
+		"baker", "n_ops_applied", "volume", "fee", "pct_account_reuse",
+		"protocol",
+	}
+)
+
+// FuzzCSVOps fuzzes CSV decoding of op table responses. Seeds are in
+// testdata/fuzz/FuzzCSVOps. Columns leave out is_contract, so decoding
+// never loads contract scripts from the server.
+func FuzzCSVOps(f *testing.F) {
+	f.Add([]byte("id,hash\n"))
+	f.Add([]byte("1,oo,transaction\n"))
+	c, err := NewClient("http://127.0.0.1:0", nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		q := c.NewOpQuery()
+		q.Columns = csvOpColumns
+		l := &OpList{columns: q.Columns, ctx: context.Background(), client: c}
+		_ = newCSVResult(&q.tableQuery, l).decodeStream(bytes.NewReader(data))
+	})
+}
+
+// FuzzCSVBlocks fuzzes CSV decoding of block table responses. Seeds are in
+// testdata/fuzz/FuzzCSVBlocks.
+func FuzzCSVBlocks(f *testing.F) {
+	f.Add([]byte("row_id,hash\n"))
+	f.Add([]byte("\"1\n"))
+	c, err := NewClient("http://127.0.0.1:0", nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		q := c.NewBlockQuery()
+		q.Columns = csvBlockColumns
+		l := &BlockList{columns: q.Columns}
+		_ = newCSVResult(&q.tableQuery, l).decodeStream(bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultDebugDumpSize is the number of response body bytes kept in debug
+// dumps when UseDebugDump is called with a zero size.
+var DefaultDebugDumpSize = 4096
+
+// Exchange is a raw HTTP request and response captured in debug mode.
+// Api keys are redacted and the body is truncated to the dump size.
+type Exchange struct {
+	Method         string
+	Url            string
+	RequestHeader  http.Header
+	Status         int
+	ResponseHeader http.Header
+	Body           []byte
+	Truncated      bool
+}
+
+func (x *Exchange) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", x.Method, x.Url)
+	x.RequestHeader.Write(&b)
+	fmt.Fprintf(&b, "\n%d %s\n", x.Status, http.StatusText(x.Status))
+	x.ResponseHeader.Write(&b)
+	b.WriteByte('\n')
+	b.Write(x.Body)
+	if x.Truncated {
+		b.WriteString("\n[truncated]")
+	}
+	return b.String()
+}
+
+// DecodeError is returned when a response cannot be decoded and debug
+// dumps are enabled. It carries the raw exchange for bug reports.
+type DecodeError struct {
+	Err      error
+	Exchange *Exchange
+}
+
+func (e DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func IsDecodeError(err error) (DecodeError, bool) {
+	var e DecodeError
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// UseDebugDump captures failed exchanges, i.e. error responses and
+// responses that cannot be decoded. Decode errors are returned as
+// DecodeError and fn, when not nil, is called for every failed exchange.
+// Size limits the number of response body bytes kept. Call with a
+// negative size to disable.
+func (c *Client) UseDebugDump(size int, fn func(*Exchange)) {
+	if size < 0 {
+		c.dumpSize, c.dumpFn = 0, nil
+		return
+	}
+	if size == 0 {
+		size = DefaultDebugDumpSize
+	}
+	c.dumpSize, c.dumpFn = size, fn
+}
+
+func (c *Client) captureExchange(req *request, resp *http.Response, body []byte) *Exchange {
+	if c.dumpSize <= 0 {
+		return nil
+	}
+	hdr := req.httpRequest.Header.Clone()
+	if hdr.Get(headerApiKey) != "" {
+		hdr.Set(headerApiKey, "redacted")
+	}
+	x := &Exchange{
+		Method:         req.httpRequest.Method,
+		Url:            redactAuth(req.httpRequest.URL).String(),
+		RequestHeader:  hdr,
+		Status:         resp.StatusCode,
+		ResponseHeader: resp.Header.Clone(),
+	}
+	n := min(len(body), c.dumpSize)
+	x.Body = append([]byte(nil), body[:n]...)
+	x.Truncated = n < len(body)
+	if c.dumpFn != nil {
+		c.dumpFn(x)
+	}
+	return x
+}
+
+// decodeError attaches the raw exchange to a decode error in debug mode.
+func (c *Client) decodeError(req *request, resp *http.Response, body []byte, err error) error {
+	if x := c.captureExchange(req, resp, body); x != nil {
+		return DecodeError{Err: err, Exchange: x}
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Column decoders for brief table rows are generated from row model struct
+// tags. Columns whose API name differs from the json tag are declared with
+// a brief tag, fields tagged brief:"-" are decoded by hand.
+//
+//go:generate go run ./scripts/briefgen -o brief_gen.go Op Block Account BigmapRow BigmapUpdateRow BigmapValueRow Chain Constant Contract Status CycleRights Snapshot
+
+// Brief row decoders use these helpers instead of type assertions so
+// malformed or truncated server responses fail with an error instead of a
+// panic. Values of unexpected type become empty, which fails to parse for
+// numbers and booleans.
+
+func jsonNumber(f interface{}) json.Number {
+	n, _ := f.(json.Number)
+	return n
+}
+
+func jsonString(f interface{}) string {
+	s, _ := f.(string)
+	return s
+}
+
+// Numbers arrive as json.Number from JSON rows and as int64, uint64 or
+// float64 from MessagePack rows, which are used without formatting them
+// into strings first.
+
+func briefInt(f interface{}) (int, error) {
+	v, err := briefInt64(f)
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(v)) != v {
+		return 0, fmt.Errorf("decode: %d overflows int", v)
+	}
+	return int(v), nil
+}
+
+func briefInt64(f interface{}) (int64, error) {
+	switch v := f.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("decode: %d overflows int64", v)
+		}
+		return int64(v), nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("decode: %v is not an integer", v)
+		}
+		return int64(v), nil
+	}
+	return strconv.ParseInt(jsonNumber(f).String(), 10, 64)
+}
+
+func briefUint64(f interface{}) (uint64, error) {
+	switch v := f.(type) {
+	case uint64:
+		return v, nil
+	case int64:
+		if v < 0 {
+			return 0, fmt.Errorf("decode: %d is negative", v)
+		}
+		return uint64(v), nil
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return 0, fmt.Errorf("decode: %v is not an unsigned integer", v)
+		}
+		return uint64(v), nil
+	}
+	return strconv.ParseUint(jsonNumber(f).String(), 10, 64)
+}
+
+func briefFloat64(f interface{}) (float64, error) {
+	switch v := f.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+	return jsonNumber(f).Float64()
+}
+
+// brief rows encode bools as numbers
+func briefBool(f interface{}) (bool, error) {
+	switch v := f.(type) {
+	case int64, uint64:
+		switch v {
+		case int64(0), uint64(0):
+			return false, nil
+		case int64(1), uint64(1):
+			return true, nil
+		}
+		return false, fmt.Errorf("decode: invalid bool %d", v)
+	}
+	return strconv.ParseBool(jsonNumber(f).String())
+}
+
+// briefTime decodes a unix millisecond timestamp.
+func briefTime(f interface{}) (time.Time, error) {
+	ts, err := briefInt64(f)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, ts*1000000).UTC(), nil
+}
+
+// checkRowLen ensures a brief row contains a value for every column.
+func checkRowLen(row []interface{}, cols []string) error {
+	if len(row) < len(cols) {
+		return fmt.Errorf("decode: short row with %d values for %d columns", len(row), len(cols))
+	}
+	return nil
+}
+
+// safeDecode runs fn and converts panics raised by binary decoders on
+// corrupt data into errors.
+func safeDecode(what string, fn func() error) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("decoding %s: %v", what, e)
+		}
+	}()
+	return fn()
+}
+
+// streamDecoder is implemented by lists which decode table rows directly
+// from a response body instead of buffering the full response.
+type streamDecoder interface {
+	decodeStream(r io.Reader) error
+}
+
+// decodeRows reads a JSON array from r and calls fn for each element. Only
+// one element is held in memory at a time.
+func decodeRows(r io.Reader, name string, fn func(json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	switch {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	case tok == nil:
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("%s: expected JSON array", name)
+	}
+	for dec.More() {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Rows written while Each or Stream page through a table can show up
+// twice or fall between two pages. WithDedup drops rows whose row_id was
+// seen within the last n rows. Unfiltered queries on tables with
+// consecutive row ids also check that each page continues where the
+// previous page ended. Gaps are passed to the hook set with WithGapHook
+// or recorded as client warnings, see Client.Warnings.
+//
+//	q := c.NewBlockQuery()
+//	q.WithDedup(1000).WithGapHook(func(g tzstats.RowGap) {
+//		log.Printf("missing %d rows after %d", g.Missing(), g.After)
+//	})
+
+// RowGap describes rows missing between two result pages.
+type RowGap struct {
+	Table string
+	Order OrderType
+	After uint64 // last row id of the previous page
+	Next  uint64 // first row id of the next page
+}
+
+// Missing returns the number of skipped row ids.
+func (g RowGap) Missing() uint64 {
+	if g.Order == OrderDesc {
+		return g.After - g.Next - 1
+	}
+	return g.Next - g.After - 1
+}
+
+func (g RowGap) String() string {
+	return fmt.Sprintf("%s: %d rows missing between row_id %d and %d", g.Table, g.Missing(), g.After, g.Next)
+}
+
+// WithDedup drops rows already seen within the last n rows in Each and
+// Stream. Zero disables deduplication.
+func (q *tableQuery) WithDedup(n int) TableQuery {
+	if n < 0 {
+		return q.fail(fmt.Errorf("invalid dedup window %d", n))
+	}
+	q.dedup = n
+	return q
+}
+
+// WithGapHook calls fn when Each or Stream detect rows missing between
+// pages. Without hook, gaps are only checked when WithDedup is used.
+func (q *tableQuery) WithGapHook(fn func(RowGap)) TableQuery {
+	q.onGap = fn
+	return q
+}
+
+// rowGuard filters duplicate rows and checks page boundaries.
+type rowGuard struct {
+	ctx    context.Context
+	client *Client
+	table  string
+	order  OrderType
+	gaps   bool // row ids are consecutive
+	onGap  func(RowGap)
+	ring   []uint64 // last seen row ids
+	pos    int
+	seen   map[uint64]struct{}
+	last   uint64 // last row id of the previous page
+	first  bool   // next row starts a page
+}
+
+func (q tableQuery) newRowGuard(ctx context.Context) *rowGuard {
+	if q.dedup == 0 && q.onGap == nil {
+		return nil
+	}
+	g := &rowGuard{
+		ctx:    ctx,
+		client: q.client,
+		table:  q.Table,
+		order:  q.Order,
+		gaps:   len(q.Filter) == 0 && q.expr == nil && q.prep == nil,
+		onGap:  q.onGap,
+		last:   q.Cursor,
+		first:  q.Cursor > 0,
+	}
+	if q.dedup > 0 {
+		g.ring = make([]uint64, 0, q.dedup)
+		g.seen = make(map[uint64]struct{}, q.dedup)
+	}
+	return g
+}
+
+// page marks the end of a page with last row id cursor.
+func (g *rowGuard) page(cursor uint64) {
+	g.last = cursor
+	g.first = true
+}
+
+// keep returns false for rows seen before.
+func (g *rowGuard) keep(id uint64) bool {
+	if g.first {
+		g.first = false
+		g.check(id)
+	}
+	if g.seen == nil || id == 0 {
+		return true
+	}
+	if _, ok := g.seen[id]; ok {
+		return false
+	}
+	if len(g.ring) < cap(g.ring) {
+		g.ring = append(g.ring, id)
+	} else {
+		delete(g.seen, g.ring[g.pos])
+		g.ring[g.pos] = id
+		g.pos = (g.pos + 1) % len(g.ring)
+	}
+	g.seen[id] = struct{}{}
+	return true
+}
+
+// check reports a gap when the first row of a page does not follow the
+// last row of the previous page.
+func (g *rowGuard) check(id uint64) {
+	if !g.gaps || g.last == 0 || id == 0 {
+		return
+	}
+	gap := RowGap{
+		Table: g.table,
+		Order: g.order,
+		After: g.last,
+		Next:  id,
+	}
+	if g.order == OrderDesc {
+		if id+1 >= g.last {
+			return
+		}
+	} else if id <= g.last+1 {
+		return
+	}
+	if g.onGap != nil {
+		g.onGap(gap)
+		return
+	}
+	if g.client != nil {
+		g.client.addWarning(g.ctx, Warning{
+			Code: 299,
+			Text: gap.String(),
+		})
+	}
+}
+
+// rowFilter is implemented by lists which don't keep row ids in a RowId
+// or Id field.
+type rowFilter interface {
+	filterRows(keep func(uint64) bool)
+}
+
+// filter removes duplicate rows from list l in place.
+func (g *rowGuard) filter(l interface{}) {
+	if f, ok := l.(rowFilter); ok {
+		f.filterRows(g.keep)
+		return
+	}
+	v := reflect.ValueOf(l)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	rows := v.Elem().FieldByName("Rows")
+	if !rows.IsValid() || rows.Kind() != reflect.Slice {
+		return
+	}
+	typ := rows.Type().Elem()
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return
+	}
+	f, ok := typ.Elem().FieldByName("RowId")
+	if !ok {
+		f, ok = typ.Elem().FieldByName("Id")
+	}
+	if !ok || f.Type.Kind() != reflect.Uint64 {
+		return
+	}
+	n := 0
+	for i, l := 0, rows.Len(); i < l; i++ {
+		row := rows.Index(i)
+		if !row.IsNil() && !g.keep(row.Elem().FieldByIndex(f.Index).Uint()) {
+			continue
+		}
+		rows.Index(n).Set(row)
+		n++
+	}
+	rows.SetLen(n)
+}
+
+func (l *RawList) filterRows(keep func(uint64) bool) {
+	idx := colIndex(l.Columns, "row_id")
+	if idx < 0 {
+		idx = colIndex(l.Columns, "id")
+	}
+	if idx < 0 {
+		return
+	}
+	n := 0
+	for _, row := range l.Rows {
+		if idx < len(row) {
+			id, _ := strconv.ParseUint(string(row[idx]), 10, 64)
+			if !keep(id) {
+				continue
+			}
+		}
+		l.Rows[n] = row
+		n++
+	}
+	l.Rows = l.Rows[:n]
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	DefaultDownloadShards  = 16
+	DefaultDownloadWorkers = 4
+)
+
+// Downloader splits a row_id or height range into shards which are
+// fetched concurrently by a bounded number of workers. Result pages are
+// passed to the callback in range order, so callers see the same sequence
+// as from a single cursor loop. Each shard may read DefaultExportBuffer
+// pages ahead. Like Each, shards stop with an ErrPartialResult when the
+// context deadline leaves no time for another page. With WithCheckpoint
+// each shard saves its cursor after the callback accepted a page, so
+// pages read ahead are fetched again on resume.
+//
+//	d := tzstats.NewDownloader("height", 1, 2500000).WithWorkers(8)
+//	q := c.NewOpQuery()
+//	q.WithColumnsPreset("light")
+//	err := d.Ops(ctx, q, func(l *tzstats.OpList) error {
+//		// write l.Rows
+//		return nil
+//	})
+type Downloader struct {
+	Column  string // range column, e.g. "height", "row_id" or "id"
+	From    int64  // first value (inclusive)
+	To      int64  // last value (inclusive)
+	Shards  int
+	Workers int
+}
+
+func NewDownloader(col string, from, to int64) Downloader {
+	return Downloader{
+		Column:  col,
+		From:    from,
+		To:      to,
+		Shards:  DefaultDownloadShards,
+		Workers: DefaultDownloadWorkers,
+	}
+}
+
+func (d Downloader) WithShards(n int) Downloader {
+	d.Shards = n
+	return d
+}
+
+func (d Downloader) WithWorkers(n int) Downloader {
+	d.Workers = n
+	return d
+}
+
+// Ops downloads all rows matching q. The query's cursor is ignored.
+func (d Downloader) Ops(ctx context.Context, q OpQuery, fn func(*OpList) error) error {
+	return d.run(ctx, q.tableQuery, func(ctx context.Context, tq tableQuery, emit func(interface{}, uint64) error) error {
+		sq := q
+		sq.tableQuery = tq
+		return sq.Each(ctx, func(l *OpList) error { return emit(l, l.Cursor()) })
+	}, func(v interface{}) error {
+		return fn(v.(*OpList))
+	})
+}
+
+// Blocks downloads all rows matching q. The query's cursor is ignored.
+func (d Downloader) Blocks(ctx context.Context, q BlockQuery, fn func(*BlockList) error) error {
+	return d.run(ctx, q.tableQuery, func(ctx context.Context, tq tableQuery, emit func(interface{}, uint64) error) error {
+		sq := q
+		sq.tableQuery = tq
+		return sq.Each(ctx, func(l *BlockList) error { return emit(l, l.Cursor()) })
+	}, func(v interface{}) error {
+		return fn(v.(*BlockList))
+	})
+}
+
+// Raw downloads all rows matching q. The query's cursor is ignored.
+func (d Downloader) Raw(ctx context.Context, q RawQuery, fn func(*RawList) error) error {
+	return d.run(ctx, q.tableQuery, func(ctx context.Context, tq tableQuery, emit func(interface{}, uint64) error) error {
+		sq := q
+		sq.tableQuery = tq
+		return sq.Each(ctx, func(l *RawList) error { return emit(l, l.Cursor()) })
+	}, func(v interface{}) error {
+		return fn(v.(*RawList))
+	})
+}
+
+// withRange returns a copy of q limited to [lo, hi] on col. Checkpoints
+// are kept per shard, so resuming requires the same shard layout.
+func (q tableQuery) withRange(col string, lo, hi int64) tableQuery {
+	q.Filter = append(q.Filter[:len(q.Filter):len(q.Filter)], Filter{
+		Mode:   FilterModeRange,
+		Column: col,
+		Value:  ToString([]int64{lo, hi}),
+	})
+	q.Cursor = 0
+	if q.ckpt != nil {
+		q.ckptName = fmt.Sprintf("%s/%s:%d-%d", q.ckptName, col, lo, hi)
+	}
+	return q
+}
+
+type shardRange struct {
+	lo, hi int64
+}
+
+// shards splits the download range into consecutive shards.
+func (d Downloader) shards() ([]shardRange, error) {
+	if d.Column == "" {
+		return nil, fmt.Errorf("download: empty range column")
+	}
+	if d.To < d.From {
+		return nil, fmt.Errorf("download: invalid range %d..%d", d.From, d.To)
+	}
+	n := d.Shards
+	if n < 1 {
+		n = 1
+	}
+	if size := d.To - d.From + 1; int64(n) > size {
+		n = int(size)
+	}
+	step := (d.To - d.From + 1) / int64(n)
+	res := make([]shardRange, n)
+	for i := range res {
+		res[i].lo = d.From + int64(i)*step
+		res[i].hi = res[i].lo + step - 1
+	}
+	res[n-1].hi = d.To
+	return res, nil
+}
+
+// run fetches shards of q concurrently and delivers pages in range order.
+// Shards are started in order, so the shard being delivered always holds
+// a worker while later shards wait on their read ahead buffers. Shard
+// checkpoints are saved after a page was delivered, never for pages which
+// are still buffered.
+func (d Downloader) run(
+	ctx context.Context,
+	q tableQuery,
+	fetch func(ctx context.Context, q tableQuery, emit func(interface{}, uint64) error) error,
+	deliver func(interface{}) error,
+) error {
+	shards, err := d.shards()
+	if err != nil {
+		return err
+	}
+	if q.Order == OrderDesc {
+		for i, j := 0, len(shards)-1; i < j; i, j = i+1, j-1 {
+			shards[i], shards[j] = shards[j], shards[i]
+		}
+	}
+	workers := d.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make([]chan shardPage, len(shards))
+	for i := range pages {
+		pages[i] = make(chan shardPage, DefaultExportBuffer)
+	}
+	var errs firstError
+	sem := make(chan struct{}, workers)
+	go func() {
+		for i, s := range shards {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// unblock the delivery loop
+				for _, ch := range pages[i:] {
+					close(ch)
+				}
+				return
+			}
+			go func(s shardRange, ch chan<- shardPage) {
+				defer func() { <-sem }()
+				defer close(ch)
+				err := d.fetchShard(ctx, q, s, fetch, ch)
+				if err != nil {
+					errs.set(fmt.Errorf("download %s %d..%d: %w", d.Column, s.lo, s.hi, err))
+					cancel()
+				}
+			}(s, pages[i])
+		}
+	}()
+
+	for i, ch := range pages {
+		sq := q.withRange(d.Column, shards[i].lo, shards[i].hi)
+		for v := range ch {
+			if err := deliver(v.list); err != nil {
+				return err
+			}
+			if sq.ckpt == nil || v.cursor == 0 {
+				continue
+			}
+			if err := sq.ckpt.SaveCheckpoint(ctx, sq.ckptName, v.cursor); err != nil {
+				return fmt.Errorf("saving checkpoint %s: %w", sq.ckptName, err)
+			}
+		}
+		if err := errs.get(); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// shardPage is a fetched page and the cursor after its last row.
+type shardPage struct {
+	list   interface{}
+	cursor uint64
+}
+
+// fetchShard resumes a shard from its checkpoint and sends its pages to
+// ch. The shard query itself does not save checkpoints since its pages
+// are only read ahead.
+func (d Downloader) fetchShard(
+	ctx context.Context,
+	q tableQuery,
+	s shardRange,
+	fetch func(ctx context.Context, q tableQuery, emit func(interface{}, uint64) error) error,
+	ch chan<- shardPage,
+) error {
+	sq := q.withRange(d.Column, s.lo, s.hi)
+	if sq.ckpt != nil {
+		c, err := sq.ckpt.LoadCheckpoint(ctx, sq.ckptName)
+		if err != nil {
+			return fmt.Errorf("loading checkpoint %s: %w", sq.ckptName, err)
+		}
+		sq.Cursor = c
+		sq.ckpt = nil
+	}
+	return fetch(ctx, sq, func(v interface{}, cursor uint64) error {
+		select {
+		case ch <- shardPage{v, cursor}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// firstError keeps the first error of concurrent workers. Cancellations
+// which follow a failure never replace the failure.
+type firstError struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (e *firstError) set(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil || (errors.Is(e.err, context.Canceled) && !errors.Is(err, context.Canceled)) {
+		e.err = err
+	}
+}
+
+func (e *firstError) get() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
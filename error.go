@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -14,6 +15,55 @@ import (
 	"time"
 )
 
+// Sentinel errors for common failure kinds. All error types returned by
+// the client match them with errors.Is based on their HTTP status, e.g.
+//
+//	if errors.Is(err, tzstats.ErrNotFound) { ... }
+//
+// Rate limits match ErrTooManyRequests, use IsErrRateLimited to access the
+// reset deadline.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrGone            = errors.New("gone")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrServer          = errors.New("server error")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+// statusError maps an HTTP status to its sentinel error.
+func statusError(status int) error {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusGone:
+		return ErrGone
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	if status >= 500 {
+		return ErrServer
+	}
+	return nil
+}
+
+func isStatusError(status int, target error) bool {
+	return target != nil && statusError(status) == target
+}
+
 type ApiError struct {
 	Code      int    `json:"code"`
 	Status    int    `json:"status"`
@@ -22,6 +72,9 @@ type ApiError struct {
 	Detail    string `json:"detail"`
 	RequestId string `json:"requestId"`
 	Reason    string `json:"reason"`
+
+	// RetryAfter is the delay from the response's Retry-After header.
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e ApiError) Error() string {
@@ -48,6 +101,10 @@ func (e ApiError) Error() string {
 	return strings.Join(s, " ")
 }
 
+func (e ApiError) Is(target error) bool {
+	return isStatusError(e.Status, target)
+}
+
 type ApiErrors struct {
 	Errors []ApiError `json:"errors"`
 }
@@ -81,6 +138,37 @@ func (e ApiErrors) Error() string {
 	return e.Errors[0].Error()
 }
 
+func (e ApiErrors) Is(target error) bool {
+	return len(e.Errors) > 0 && e.Errors[0].Is(target)
+}
+
+// newApiError decodes a structured error response. Missing status and
+// request id are taken from the HTTP response.
+func newApiError(resp *http.Response, buf []byte) (ApiErrors, bool) {
+	var e ApiErrors
+	if len(buf) == 0 || buf[0] != '{' {
+		return e, false
+	}
+	if err := json.Unmarshal(buf, &e); err != nil || len(e.Errors) == 0 {
+		return e, false
+	}
+	for i := range e.Errors {
+		if e.Errors[i].Message == "" && e.Errors[i].Code == 0 {
+			return e, false
+		}
+		if e.Errors[i].Status == 0 {
+			e.Errors[i].Status = resp.StatusCode
+		}
+		if e.Errors[i].RequestId == "" {
+			e.Errors[i].RequestId = resp.Header.Get("X-Request-Id")
+		}
+		if d, ok := parseRetryAfter(resp.Header); ok {
+			e.Errors[i].RetryAfter = d
+		}
+	}
+	return e, true
+}
+
 func IsApiError(err error) (ApiErrors, bool) {
 	e, ok := err.(ApiErrors)
 	return e, ok
@@ -113,6 +201,10 @@ func (e HttpError) Error() string {
 	return fmt.Sprintf("%d %s: %s %s", e.Status, http.StatusText(e.Status), e.Data, e.Request)
 }
 
+func (e HttpError) Is(target error) bool {
+	return isStatusError(e.Status, target)
+}
+
 func IsHttpError(err error) (HttpError, bool) {
 	e, ok := err.(HttpError)
 	return e, ok
@@ -177,15 +269,111 @@ func (e ErrRateLimited) Deadline() time.Duration {
 	return e.deadline.Sub(time.Now().UTC())
 }
 
+func (e ErrRateLimited) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
 func IsErrRateLimited(err error) (ErrRateLimited, bool) {
 	e, ok := err.(ErrRateLimited)
 	return e, ok
 }
 
+// ErrPartialResult is returned when a multi-page query is interrupted by
+// context cancellation. Rows were processed before Cursor, set Cursor on
+// the query to resume.
+type ErrPartialResult struct {
+	Cursor uint64
+	Rows   int
+	Err    error
+}
+
+func (e ErrPartialResult) Error() string {
+	return fmt.Sprintf("partial result after %d rows (resume at cursor %d): %v", e.Rows, e.Cursor, e.Err)
+}
+
+func (e ErrPartialResult) Unwrap() error {
+	return e.Err
+}
+
+func IsErrPartialResult(err error) (ErrPartialResult, bool) {
+	e, ok := err.(ErrPartialResult)
+	return e, ok
+}
+
+const headerEarliestHeight = "X-Earliest-Height"
+
+// ErrPruned is returned when the server no longer holds data for the
+// requested range because it was pruned or never indexed. EarliestHeight
+// is the first available block height, or zero when the server did not
+// report it.
+type ErrPruned struct {
+	Status         int
+	Message        string
+	Request        string
+	EarliestHeight int64
+}
+
+func (e ErrPruned) Error() string {
+	if e.EarliestHeight > 0 {
+		return fmt.Sprintf("data pruned, earliest height %d: %s %s", e.EarliestHeight, e.Message, e.Request)
+	}
+	return fmt.Sprintf("data pruned: %s %s", e.Message, e.Request)
+}
+
+func (e ErrPruned) Is(target error) bool {
+	return isStatusError(e.Status, target)
+}
+
+func IsErrPruned(err error) (ErrPruned, bool) {
+	e, ok := err.(ErrPruned)
+	return e, ok
+}
+
+// newPrunedError detects pruned or not indexed data responses which the
+// server sends with status 410 or as an error message on other statuses.
+func newPrunedError(resp *http.Response, buf []byte, req string) (ErrPruned, bool) {
+	var (
+		apiErr ApiErrors
+		msg    string
+		body   struct {
+			EarliestHeight int64 `json:"earliest_height"`
+		}
+	)
+	if len(buf) > 0 && buf[0] == '{' {
+		_ = json.Unmarshal(buf, &apiErr)
+		_ = json.Unmarshal(buf, &body)
+	}
+	if len(apiErr.Errors) > 0 {
+		e := apiErr.Errors[0]
+		msg = strings.TrimSpace(e.Message + " " + e.Detail)
+	}
+	lmsg := strings.ToLower(msg)
+	isPruned := resp.StatusCode == http.StatusGone ||
+		strings.Contains(lmsg, "pruned") ||
+		strings.Contains(lmsg, "not indexed")
+	if !isPruned {
+		return ErrPruned{}, false
+	}
+	e := ErrPruned{
+		Status:         resp.StatusCode,
+		Message:        msg,
+		Request:        req,
+		EarliestHeight: body.EarliestHeight,
+	}
+	if h := resp.Header.Get(headerEarliestHeight); h != "" {
+		if v, err := strconv.ParseInt(h, 10, 64); err == nil {
+			e.EarliestHeight = v
+		}
+	}
+	return e, true
+}
+
 func ErrorStatus(err error) int {
 	switch e := err.(type) {
 	case ErrRateLimited:
 		return 427
+	case ErrPruned:
+		return e.Status
 	case HttpError:
 		return e.Status
 	case ApiError:
@@ -0,0 +1,216 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// DefaultEstimateSample is the number of rows Estimate reads to measure
+// row size and row id density.
+var DefaultEstimateSample = 100
+
+// QueryEstimate is the expected result size of a table query.
+type QueryEstimate struct {
+	Rows      int64 // expected number of rows
+	Bytes     int64 // expected JSON response size of all pages
+	Pages     int64 // expected number of pages at the query limit
+	Exact     bool  // all rows fit into the sample, Rows and Bytes are exact
+	Unbounded bool  // no filter restricts the query
+}
+
+func (e QueryEstimate) String() string {
+	s := "~"
+	if e.Exact {
+		s = ""
+	}
+	return fmt.Sprintf("%s%d rows, %s%d bytes in %d pages", s, e.Rows, s, e.Bytes, e.Pages)
+}
+
+// Estimate returns the expected number of rows and bytes of the query
+// without loading all results. It reads a sample page in query order and
+// the last matching row id, then extrapolates with the row id density of
+// the sample. Estimates of queries with sparse matches in a wide row id
+// range are less accurate. Batch schedulers can use it to budget time and
+// refuse unbounded queries. Queries with a filter expression are estimated
+// per branch, see WithExpr.
+//
+//	e, err := q.Estimate(ctx)
+//	if e.Unbounded || e.Rows > 1e6 {
+//		return fmt.Errorf("query too large: %s", e)
+//	}
+func (q tableQuery) Estimate(ctx context.Context) (QueryEstimate, error) {
+	if q.prep != nil {
+		return QueryEstimate{}, fmt.Errorf("cannot estimate prepared query on table %s", q.Table)
+	}
+	if err := q.Check(); err != nil {
+		return QueryEstimate{}, err
+	}
+	if q.expr != nil {
+		return q.estimateBranches(ctx)
+	}
+	idCol := "row_id"
+	if m, ok := tableModels[q.Table]; ok {
+		if cols := tableColumnsOf(m); colIndex(cols, idCol) < 0 && colIndex(cols, "id") >= 0 {
+			idCol = "id"
+		}
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	est := QueryEstimate{
+		Unbounded: len(q.Filter) == 0,
+	}
+
+	// continue after the query cursor with a filter, so the reverse
+	// probe finds the last row of the same range
+	q.Format = FormatJSON
+	q.Verbose = false
+	q.anyColumns = true
+	q.Filter = q.Filter[:len(q.Filter):len(q.Filter)]
+	if q.Cursor > 0 {
+		mode := FilterModeGt
+		if q.Order == OrderDesc {
+			mode = FilterModeLt
+		}
+		q.Filter.Add(mode, idCol, q.Cursor)
+		q.Cursor = 0
+	}
+	cols := q.Columns
+	if len(cols) == 0 {
+		if m, ok := tableModels[q.Table]; ok {
+			cols = tableColumnsOf(m)
+		}
+	}
+	extra := colIndex(cols, idCol) < 0
+	if extra {
+		cols = append(cols[:len(cols):len(cols)], idCol)
+	}
+
+	// sample rows in query order
+	sq := q
+	sq.Columns = cols
+	sq.Limit = DefaultEstimateSample
+	sample := &RawList{Columns: cols}
+	if err := q.client.QueryTable(ctx, &sq, sample); err != nil {
+		return est, err
+	}
+	n := int64(len(sample.Rows))
+	if n == 0 {
+		est.Exact = true
+		return est, nil
+	}
+	var size int64
+	for _, row := range sample.Rows {
+		size += 3 // brackets and separator
+		for i, v := range row {
+			if extra && i == len(row)-1 {
+				continue
+			}
+			size += int64(len(v)) + 1
+		}
+	}
+	if n < int64(DefaultEstimateSample) {
+		est.Rows = n
+		est.Bytes = size
+		est.Pages = (n + int64(limit) - 1) / int64(limit)
+		est.Exact = true
+		return est, nil
+	}
+	first, err := sampleRowId(sample, idCol, 0)
+	if err != nil {
+		return est, err
+	}
+	last, err := sampleRowId(sample, idCol, len(sample.Rows)-1)
+	if err != nil {
+		return est, err
+	}
+
+	// last matching row id from a reverse probe
+	rq := q
+	rq.Columns = []string{idCol}
+	rq.Limit = 1
+	rq.Order = OrderDesc
+	if q.Order == OrderDesc {
+		rq.Order = OrderAsc
+	}
+	end := &RawList{Columns: rq.Columns}
+	if err := q.client.QueryTable(ctx, &rq, end); err != nil {
+		return est, err
+	}
+	tail := last
+	if len(end.Rows) > 0 {
+		if tail, err = sampleRowId(end, idCol, 0); err != nil {
+			return est, err
+		}
+	}
+	est.Rows = n * idSpan(first, tail) / idSpan(first, last)
+	if est.Rows < n {
+		est.Rows = n
+	}
+	est.Bytes = size * est.Rows / n
+	est.Pages = (est.Rows + int64(limit) - 1) / int64(limit)
+	return est, nil
+}
+
+// estimateBranches estimates each branch of a filter expression and sums
+// the results. Rows matching more than one branch are counted once per
+// branch, so the sum is an upper bound.
+func (q tableQuery) estimateBranches(ctx context.Context) (QueryEstimate, error) {
+	bs, err := q.filterBranches()
+	if err != nil {
+		return QueryEstimate{}, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	est := QueryEstimate{Exact: true}
+	var nonEmpty int
+	for _, b := range bs {
+		bq := q
+		bq.expr = nil
+		bq.Filter = b
+		e, err := bq.Estimate(ctx)
+		if err != nil {
+			return est, err
+		}
+		if e.Rows > 0 {
+			nonEmpty++
+		}
+		est.Rows += e.Rows
+		est.Bytes += e.Bytes
+		est.Exact = est.Exact && e.Exact
+		est.Unbounded = est.Unbounded || e.Unbounded
+	}
+	// overlapping branches make the sum inexact
+	if nonEmpty > 1 {
+		est.Exact = false
+	}
+	est.Pages = (est.Rows + int64(limit) - 1) / int64(limit)
+	return est, nil
+}
+
+// idSpan returns the number of row ids in the range between a and b.
+func idSpan(a, b uint64) int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return int64(b-a) + 1
+}
+
+func sampleRowId(l *RawList, col string, i int) (uint64, error) {
+	idx := colIndex(l.Columns, col)
+	if idx < 0 || idx >= len(l.Rows[i]) {
+		return 0, fmt.Errorf("estimate: missing %s column in result", col)
+	}
+	id, err := strconv.ParseUint(string(l.Rows[i][idx]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("estimate: invalid %s: %w", col, err)
+	}
+	return id, nil
+}
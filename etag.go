@@ -0,0 +1,99 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultETagCacheSize is the number of responses kept for conditional
+// requests when enabled with UseConditionalRequests.
+var DefaultETagCacheSize = 256
+
+type etagEntry struct {
+	etag        string
+	contentType string
+	body        []byte
+}
+
+// UseConditionalRequests caches explorer and metadata GET responses which
+// carry an ETag, e.g. tip, config and contract scripts, and revalidates
+// them with If-None-Match on later requests for the same URL. When the
+// server replies 304 Not Modified the cached body is used, so polling loops
+// don't download unchanged payloads again. Table responses are not cached.
+// Size <= 0 uses DefaultETagCacheSize.
+func (c *Client) UseConditionalRequests(size int) {
+	if size <= 0 {
+		size = DefaultETagCacheSize
+	}
+	if size < 2 {
+		size = 2
+	}
+	c.etags, _ = lru.New2Q(size)
+}
+
+func (c *Client) etagCachable(req *request) bool {
+	if c.etags == nil || req.httpRequest.Method != http.MethodGet {
+		return false
+	}
+	if _, isStream := req.responseVal.(io.Writer); isStream {
+		return false
+	}
+	// table pages are large and rarely requested twice
+	p := req.httpRequest.URL.Path
+	return strings.Contains(p, "/explorer/") || strings.Contains(p, "/metadata")
+}
+
+// etagRequest adds If-None-Match for cached responses.
+func (c *Client) etagRequest(req *request) {
+	if !c.etagCachable(req) {
+		return
+	}
+	if v, ok := c.etags.Get(req.httpRequest.URL.String()); ok {
+		req.httpRequest.Header.Set("If-None-Match", v.(*etagEntry).etag)
+	}
+}
+
+// etagResponse replaces a 304 response with the cached response body.
+func (c *Client) etagResponse(req *request, resp *http.Response) {
+	if resp.StatusCode != http.StatusNotModified || !c.etagCachable(req) {
+		return
+	}
+	v, ok := c.etags.Get(req.httpRequest.URL.String())
+	if !ok {
+		return
+	}
+	e := v.(*etagEntry)
+	c.logDebug("cache hit", "cache", "etag", "key", redactAuth(req.httpRequest.URL).String())
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK"
+	resp.Body = ioutil.NopCloser(bytes.NewReader(e.body))
+	resp.ContentLength = int64(len(e.body))
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Set("Content-Type", e.contentType)
+	resp.Header.Set("Content-Length", strconv.Itoa(len(e.body)))
+}
+
+// etagStore caches successful responses which carry an ETag.
+func (c *Client) etagStore(req *request, resp *http.Response, body []byte) {
+	if resp.StatusCode != http.StatusOK || !c.etagCachable(req) {
+		return
+	}
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		return
+	}
+	c.etags.Add(req.httpRequest.URL.String(), &etagEntry{
+		etag:        tag,
+		contentType: resp.Header.Get("Content-Type"),
+		body:        body,
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestETagNotModifiedGzip(t *testing.T) {
+	var hits int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			hits++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"height":7}`))
+		zw.Close()
+	})
+	c.UseConditionalRequests(0)
+	for i := 0; i < 2; i++ {
+		var v struct{ Height int64 }
+		if err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if v.Height != 7 {
+			t.Errorf("request %d: got height %d, want 7", i, v.Height)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("got %d cache hits, want 1", hits)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"blockwatch.cc/tzgo/tezos"
 	"blockwatch.cc/tzstats-go"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"net/http"
 )
 
 func main() {
@@ -42,7 +42,7 @@ func run() error {
 	md, err := c.GetAccountMetadata(ctx, addr)
 	if err != nil {
 		// handle 404 NotFound errors in a special way
-		if e, ok := tzstats.IsHttpError(err); ok && e.Status == http.StatusNotFound {
+		if errors.Is(err, tzstats.ErrNotFound) {
 			return fmt.Errorf("No metadata for this account")
 		}
 		return err
@@ -76,8 +76,8 @@ func (s *Status) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return s.UnmarshalJSONBrief(data)
 	}
-	type Alias *Status
-	return json.Unmarshal(data, Alias(s))
+	type Alias Status
+	return json.Unmarshal(data, (*Alias)(s))
 }
 
 func (s *Status) UnmarshalJSONBrief(data []byte) error {
@@ -89,22 +89,15 @@ func (s *Status) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, s.columns); err != nil {
+		return err
+	}
 	for i, v := range s.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "status":
-			st.Status = f.(string)
-		case "blocks":
-			st.Blocks, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "indexed":
-			st.Indexed, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "progress":
-			st.Progress, err = f.(json.Number).Float64()
-		}
-		if err != nil {
+		if _, err := st.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultExportBuffer is the number of result pages a shard may read ahead
+// while earlier shards are still being written to the sink.
+var DefaultExportBuffer = 4
+
+// ExportOps exports all operations in the height range [from, to] to sink.
+// The range is split into shards which are scanned concurrently with
+// independent cursors by a Downloader. Results are written to the sink in
+// height order.
+func (c *Client) ExportOps(ctx context.Context, from, to int64, shards int, sink Sink) error {
+	if to < from {
+		return fmt.Errorf("export: invalid height range %d..%d", from, to)
+	}
+	if shards < 1 {
+		shards = 1
+	}
+	d := NewDownloader("height", from, to).WithShards(shards).WithWorkers(shards)
+	err := d.Ops(ctx, c.NewOpQuery(), func(l *OpList) error {
+		if l.Len() == 0 {
+			return nil
+		}
+		return sink.WriteOps(ctx, l.Rows)
+	})
+	if err != nil {
+		return err
+	}
+	return sink.Flush(ctx)
+}
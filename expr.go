@@ -0,0 +1,477 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxFilterBranches limits how many AND-only branches a filter expression
+// may expand into. Each branch is sent as a separate table request.
+var MaxFilterBranches = 8
+
+// FilterExpr is a boolean filter expression built from FilterCond,
+// FilterAnd, FilterOr and FilterNot. The table API only combines filters
+// with AND, so expressions are expanded into a disjunction of AND-only
+// branches. OR groups over a single column are sent as one "in" filter,
+// other branches run as separate requests and their rows are merged by
+// row id. Conditions of a branch with equal column and mode are combined
+// into one, e.g. two gt bounds into the larger one, expressions with
+// conditions that cannot be combined are invalid.
+//
+//	// sender=X OR receiver=X, AND type IN (transaction, origination)
+//	q.WithExpr(tzstats.FilterAnd(
+//		tzstats.FilterOr(
+//			tzstats.FilterCond(tzstats.FilterModeEqual, "sender", addr),
+//			tzstats.FilterCond(tzstats.FilterModeEqual, "receiver", addr),
+//		),
+//		tzstats.FilterCond(tzstats.FilterModeIn, "type", "transaction", "origination"),
+//	))
+type FilterExpr interface {
+	branches() ([]FilterList, error)
+	negate() (FilterExpr, error)
+	String() string
+}
+
+type filterCond Filter
+
+type filterAnd []FilterExpr
+
+type filterOr []FilterExpr
+
+type filterNot struct {
+	expr FilterExpr
+}
+
+// FilterCond is a single column condition. Multiple values are joined
+// like in WithFilter.
+func FilterCond(mode FilterMode, col string, vals ...interface{}) FilterExpr {
+	return filterCond{
+		Mode:   mode,
+		Column: col,
+		Value:  filterValue(vals),
+	}
+}
+
+func FilterAnd(exprs ...FilterExpr) FilterExpr {
+	return filterAnd(exprs)
+}
+
+func FilterOr(exprs ...FilterExpr) FilterExpr {
+	return filterOr(exprs)
+}
+
+func FilterNot(expr FilterExpr) FilterExpr {
+	return filterNot{expr}
+}
+
+func (f filterCond) String() string {
+	return f.Column + "." + string(f.Mode) + "=" + ToString(f.Value)
+}
+
+func (f filterCond) branches() ([]FilterList, error) {
+	return []FilterList{{Filter(f)}}, nil
+}
+
+func (f filterCond) negate() (FilterExpr, error) {
+	n := f
+	switch f.Mode {
+	case FilterModeEqual:
+		n.Mode = FilterModeNotEqual
+	case FilterModeNotEqual:
+		n.Mode = FilterModeEqual
+	case FilterModeIn:
+		n.Mode = FilterModeNotIn
+	case FilterModeNotIn:
+		n.Mode = FilterModeIn
+	case FilterModeGt:
+		n.Mode = FilterModeLte
+	case FilterModeGte:
+		n.Mode = FilterModeLt
+	case FilterModeLt:
+		n.Mode = FilterModeGte
+	case FilterModeLte:
+		n.Mode = FilterModeGt
+	case FilterModeRange:
+		vals := strings.Split(ToString(f.Value), ",")
+		if len(vals) != 2 {
+			return nil, fmt.Errorf("filter %s: invalid range", f)
+		}
+		return filterOr{
+			filterCond{Mode: FilterModeLt, Column: f.Column, Value: vals[0]},
+			filterCond{Mode: FilterModeGt, Column: f.Column, Value: vals[1]},
+		}, nil
+	default:
+		return nil, fmt.Errorf("filter %s: cannot negate mode %s", f, f.Mode)
+	}
+	return n, nil
+}
+
+func (e filterAnd) String() string {
+	return joinExprs(e, " AND ")
+}
+
+func (e filterAnd) branches() ([]FilterList, error) {
+	res := []FilterList{{}}
+	for _, v := range e {
+		bs, err := v.branches()
+		if err != nil {
+			return nil, err
+		}
+		next := make([]FilterList, 0, len(res)*len(bs))
+		for _, l := range res {
+			for _, r := range bs {
+				b := make(FilterList, 0, len(l)+len(r))
+				b, ok, err := mergeConds(append(append(b, l...), r...))
+				if err != nil {
+					return nil, fmt.Errorf("filter %s: %w", e, err)
+				}
+				if ok {
+					next = append(next, b)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil, fmt.Errorf("filter %s: conditions never match", e)
+		}
+		if len(next) > MaxFilterBranches {
+			return nil, fmt.Errorf("filter %s: more than %d branches", e, MaxFilterBranches)
+		}
+		res = next
+	}
+	return res, nil
+}
+
+// mergeConds combines conditions of an AND-only branch which use the same
+// column and mode, because a request carries a single value per filter.
+// Eq and in filters are intersected, nin filters joined and bounds
+// narrowed. It returns false when the branch can never match and an error
+// when two conditions cannot be combined.
+func mergeConds(b FilterList) (FilterList, bool, error) {
+	res := b[:0]
+	idx := make(map[string]int, len(b))
+	for _, f := range b {
+		key := f.Column + "." + string(f.Mode)
+		i, ok := idx[key]
+		if !ok {
+			idx[key] = len(res)
+			res = append(res, f)
+			continue
+		}
+		v, match, err := mergeCond(res[i], f)
+		if err != nil || !match {
+			return nil, false, err
+		}
+		res[i] = v
+	}
+	return res, true, nil
+}
+
+// mergeCond combines two conditions with equal column and mode.
+func mergeCond(a, b Filter) (Filter, bool, error) {
+	x, y := ToString(a.Value), ToString(b.Value)
+	if x == y {
+		return a, true, nil
+	}
+	switch a.Mode {
+	case FilterModeEqual:
+		return a, false, nil
+	case FilterModeIn:
+		in := make(map[string]bool)
+		for _, v := range strings.Split(y, ",") {
+			in[v] = true
+		}
+		var vals []string
+		for _, v := range strings.Split(x, ",") {
+			if in[v] {
+				vals = append(vals, v)
+				delete(in, v)
+			}
+		}
+		if len(vals) == 0 {
+			return a, false, nil
+		}
+		a.Value = strings.Join(vals, ",")
+		return a, true, nil
+	case FilterModeNotIn:
+		a.Value = sortedList(x + "," + y)
+		return a, true, nil
+	case FilterModeGt, FilterModeGte, FilterModeLt, FilterModeLte:
+		c, ok := compareValues(x, y)
+		if !ok {
+			break
+		}
+		lower := a.Mode == FilterModeGt || a.Mode == FilterModeGte
+		if (lower && c < 0) || (!lower && c > 0) {
+			a.Value = y
+		}
+		return a, true, nil
+	case FilterModeRange:
+		xs, ys := strings.Split(x, ","), strings.Split(y, ",")
+		if len(xs) != 2 || len(ys) != 2 {
+			break
+		}
+		cfrom, ok1 := compareValues(xs[0], ys[0])
+		cto, ok2 := compareValues(xs[1], ys[1])
+		if !ok1 || !ok2 {
+			break
+		}
+		from, to := xs[0], xs[1]
+		if cfrom < 0 {
+			from = ys[0]
+		}
+		if cto > 0 {
+			to = ys[1]
+		}
+		if c, ok := compareValues(from, to); !ok || c > 0 {
+			return a, false, nil
+		}
+		a.Value = from + "," + to
+		return a, true, nil
+	}
+	return a, false, fmt.Errorf("cannot combine %s and %s", filterCond(a), filterCond(b))
+}
+
+// compareValues compares two numeric or time filter values.
+func compareValues(x, y string) (int, bool) {
+	if a, err := strconv.ParseFloat(x, 64); err == nil {
+		b, err := strconv.ParseFloat(y, 64)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST", "2006-01-02"} {
+		a, err := time.Parse(layout, x)
+		if err != nil {
+			continue
+		}
+		b, err := time.Parse(layout, y)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case a.Before(b):
+			return -1, true
+		case a.After(b):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (e filterAnd) negate() (FilterExpr, error) {
+	res := make(filterOr, len(e))
+	for i, v := range e {
+		n, err := v.negate()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+func (e filterOr) String() string {
+	return joinExprs(e, " OR ")
+}
+
+func (e filterOr) branches() ([]FilterList, error) {
+	res := make([]FilterList, 0, len(e))
+	for _, v := range e {
+		bs, err := v.branches()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, bs...)
+	}
+	if len(res) > MaxFilterBranches {
+		return nil, fmt.Errorf("filter %s: more than %d branches", e, MaxFilterBranches)
+	}
+	return mergeInBranches(res), nil
+}
+
+func (e filterOr) negate() (FilterExpr, error) {
+	res := make(filterAnd, len(e))
+	for i, v := range e {
+		n, err := v.negate()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+func (e filterNot) String() string {
+	return "NOT (" + e.expr.String() + ")"
+}
+
+func (e filterNot) branches() ([]FilterList, error) {
+	n, err := e.expr.negate()
+	if err != nil {
+		return nil, err
+	}
+	return n.branches()
+}
+
+func (e filterNot) negate() (FilterExpr, error) {
+	return e.expr, nil
+}
+
+func joinExprs(exprs []FilterExpr, sep string) string {
+	s := make([]string, len(exprs))
+	for i, v := range exprs {
+		s[i] = v.String()
+	}
+	return "(" + strings.Join(s, sep) + ")"
+}
+
+// mergeInBranches combines branches which each consist of a single eq or
+// in filter on the same column into one in filter.
+func mergeInBranches(bs []FilterList) []FilterList {
+	if len(bs) < 2 {
+		return bs
+	}
+	col := bs[0][0].Column
+	vals := make([]string, 0, len(bs))
+	for _, b := range bs {
+		if len(b) != 1 || b[0].Column != col {
+			return bs
+		}
+		if b[0].Mode != FilterModeEqual && b[0].Mode != FilterModeIn {
+			return bs
+		}
+		vals = append(vals, ToString(b[0].Value))
+	}
+	return []FilterList{{{Mode: FilterModeIn, Column: col, Value: strings.Join(vals, ",")}}}
+}
+
+// WithExpr sets a filter expression which is combined with filters added
+// by WithFilter using AND.
+func (q *tableQuery) WithExpr(e FilterExpr) TableQuery {
+	q.expr = e
+	return q
+}
+
+// filterBranches returns the AND-only filter lists to request. Branch
+// conditions on the same column and mode as a query filter are merged
+// with it and replace the query filter in the request.
+func (q tableQuery) filterBranches() ([]FilterList, error) {
+	if q.expr == nil {
+		return []FilterList{nil}, nil
+	}
+	bs, err := q.expr.branches()
+	if err != nil || len(q.Filter) == 0 {
+		return bs, err
+	}
+	res := make([]FilterList, 0, len(bs))
+	for _, b := range bs {
+		l := make(FilterList, 0, len(q.Filter)+len(b))
+		l, ok, err := mergeConds(append(append(l, q.Filter...), b...))
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", q.expr, err)
+		}
+		if ok {
+			res = append(res, l)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("filter %s: conditions never match", q.expr)
+	}
+	return res, nil
+}
+
+// queryBranches runs one request per filter branch and merges rows into
+// result by row id in query order. Result must be a list with a Rows
+// slice of models carrying an Id or RowId.
+func (c *Client) queryBranches(ctx context.Context, q *tableQuery, branches []FilterList, result interface{}) error {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("filter expression: unsupported result type %T", result)
+	}
+	rows := rv.Elem().FieldByName("Rows")
+	if !rows.IsValid() || rows.Kind() != reflect.Slice {
+		return fmt.Errorf("filter expression: unsupported result type %T", result)
+	}
+	type idRow struct {
+		id  uint64
+		row reflect.Value
+	}
+	var (
+		merged []idRow
+		seen   = make(map[uint64]struct{})
+	)
+	for _, b := range branches {
+		// decode each branch into a copy of result to keep list settings
+		tmp := reflect.New(rv.Elem().Type())
+		tmp.Elem().Set(rv.Elem())
+		tmp.Elem().FieldByName("Rows").Set(reflect.Zero(rows.Type()))
+		var res interface{} = tmp.Interface()
+		if q.Format == FormatCSV {
+			res = newCSVResult(q, res)
+		}
+		if err := c.get(ctx, q.urlWith(b), nil, res); err != nil {
+			return err
+		}
+		trows := tmp.Elem().FieldByName("Rows")
+		for i := 0; i < trows.Len(); i++ {
+			r := trows.Index(i)
+			id, ok := modelRowId(r)
+			if !ok {
+				return fmt.Errorf("filter expression: rows of %T have no id", result)
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			merged = append(merged, idRow{id, r})
+		}
+	}
+	desc := q.Order == OrderDesc
+	sort.Slice(merged, func(i, j int) bool {
+		if desc {
+			return merged[i].id > merged[j].id
+		}
+		return merged[i].id < merged[j].id
+	})
+	if q.Limit > 0 && len(merged) > q.Limit {
+		merged = merged[:q.Limit]
+	}
+	for _, v := range merged {
+		rows.Set(reflect.Append(rows, v.row))
+	}
+	return nil
+}
+
+func modelRowId(v reflect.Value) (uint64, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	for _, name := range []string{"RowId", "Id"} {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.Uint64 {
+			return f.Uint(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// extraFields returns all members of JSON object data which have no
+// matching json tag in struct type typ. Models store the result in their
+// Extra map so fields added by newer API versions remain accessible.
+// Members are scanned in place, so objects without unknown fields do not
+// allocate.
+func extraFields(data []byte, typ reflect.Type) map[string]json.RawMessage {
+	known := jsonFieldIndex(typ)
+	var extra map[string]json.RawMessage
+	scanObject(data, func(key, val []byte) {
+		if bytes.IndexByte(key, '\\') < 0 {
+			if _, ok := known[string(key)]; ok {
+				return
+			}
+		}
+		var name string
+		if err := json.Unmarshal(append(append([]byte{'"'}, key...), '"'), &name); err != nil {
+			return
+		}
+		if _, ok := known[name]; ok {
+			return
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[name] = append(json.RawMessage(nil), val...)
+	})
+	return extra
+}
+
+// scanObject calls fn with the raw key and value of each member of JSON
+// object data. Data must be valid JSON, scanning stops at the first
+// syntax error.
+func scanObject(data []byte, fn func(key, val []byte)) {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return
+	}
+	for i++; ; i++ {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return
+		}
+		end := skipString(data, i)
+		if end < 0 {
+			return
+		}
+		key := data[i+1 : end-1]
+		i = skipSpace(data, end)
+		if i >= len(data) || data[i] != ':' {
+			return
+		}
+		start := skipSpace(data, i+1)
+		i = skipValue(data, start)
+		if i < 0 {
+			return
+		}
+		fn(key, data[start:i])
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != ',' {
+			return
+		}
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipString returns the position after the string starting at i.
+func skipString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// skipValue returns the position after the value starting at i.
+func skipValue(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				if i = skipString(data, i); i < 0 {
+					return -1
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+	}
+	for i < len(data) {
+		switch data[i] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// addExtra stores an unknown table column value decoded by a brief
+// (array) decoder.
+func addExtra(m map[string]json.RawMessage, name string, val interface{}) map[string]json.RawMessage {
+	buf, err := json.Marshal(val)
+	if err != nil {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]json.RawMessage)
+	}
+	m[name] = buf
+	return m
+}
+
+var (
+	opModelType    = reflect.TypeOf(Op{})
+	blockModelType = reflect.TypeOf(Block{})
+)
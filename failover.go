@@ -0,0 +1,218 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultFailoverCooldown is the time an endpoint is skipped after a
+	// failure. It doubles on consecutive failures up to DefaultFailoverMaxCooldown.
+	DefaultFailoverCooldown    = 10 * time.Second
+	DefaultFailoverMaxCooldown = 5 * time.Minute
+)
+
+// EndpointStatus reports the health of a single API endpoint.
+type EndpointStatus struct {
+	Url      string    `json:"url"`
+	Healthy  bool      `json:"healthy"`
+	Failures int       `json:"failures"`
+	RetryAt  time.Time `json:"retry_at,omitempty"`
+}
+
+// NewFailoverClient creates a client which sends requests to the first
+// URL and fails over to the next healthy URL in order on connection errors
+// or 5xx responses.
+func NewFailoverClient(urls []string, httpClient *http.Client) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("missing API url")
+	}
+	c, err := NewClient(urls[0], httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.UseFailover(urls[1:]...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UseFailover adds fallback endpoints after the client's primary URL.
+// Failed endpoints are skipped for a cooldown period, after which they are
+// tried again so the primary recovers automatically once it is back.
+func (c *Client) UseFailover(urls ...string) error {
+	if len(urls) == 0 {
+		c.failover = nil
+		return nil
+	}
+	f := &failover{
+		bases:  []string{endpointBase(c.params)},
+		health: make([]EndpointStatus, 1, len(urls)+1),
+	}
+	for _, u := range urls {
+		p, err := ParseParams(u)
+		if err != nil {
+			return err
+		}
+		f.bases = append(f.bases, endpointBase(p))
+		f.health = append(f.health, EndpointStatus{})
+	}
+	for i := range f.health {
+		f.health[i].Url = f.bases[i]
+		f.health[i].Healthy = true
+	}
+	c.failover = f
+	return nil
+}
+
+// Endpoints returns the current health of all configured endpoints.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.failover == nil {
+		return []EndpointStatus{{Url: endpointBase(c.params), Healthy: true}}
+	}
+	c.failover.mu.Lock()
+	defer c.failover.mu.Unlock()
+	return append([]EndpointStatus(nil), c.failover.health...)
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.hedgeDelay > 0 && isHedgeable(req) {
+		return c.doHedged(req)
+	}
+	return c.doOnce(req)
+}
+
+func (c *Client) doOnce(req *http.Request) (*http.Response, error) {
+	if c.failover == nil {
+		return c.httpClient.Do(req)
+	}
+	return c.failover.do(c.httpClient, req)
+}
+
+func endpointBase(p Params) string {
+	if p.Prefix != "" {
+		return p.Server + "/" + p.Prefix
+	}
+	return p.Server
+}
+
+type failover struct {
+	mu     sync.Mutex
+	bases  []string
+	health []EndpointStatus
+}
+
+// order returns endpoint indexes to try, healthy ones first. Endpoints
+// whose cooldown has passed count as healthy again.
+func (f *failover) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	up := make([]int, 0, len(f.health))
+	down := make([]int, 0)
+	for i, h := range f.health {
+		if h.Healthy || now.After(h.RetryAt) {
+			up = append(up, i)
+		} else {
+			down = append(down, i)
+		}
+	}
+	return append(up, down...)
+}
+
+func (f *failover) up(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := &f.health[i]
+	if !h.Healthy {
+		log.Infof("API endpoint %s recovered", h.Url)
+	}
+	h.Healthy = true
+	h.Failures = 0
+	h.RetryAt = time.Time{}
+}
+
+func (f *failover) down(i int, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := &f.health[i]
+	d := DefaultFailoverCooldown << uint(min(h.Failures, 16))
+	if d > DefaultFailoverMaxCooldown || d <= 0 {
+		d = DefaultFailoverMaxCooldown
+	}
+	h.Healthy = false
+	h.Failures++
+	h.RetryAt = time.Now().Add(d)
+	log.Warnf("API endpoint %s failed (%s), retry in %s", h.Url, reason, d)
+}
+
+func (f *failover) do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	// requests for other servers like IPFS gateways are sent as is
+	u := req.URL.String()
+	if !strings.HasPrefix(u, f.bases[0]) {
+		return hc.Do(req)
+	}
+	var (
+		resp *http.Response
+		err  error
+	)
+	order := f.order()
+	for n, i := range order {
+		r, rerr := f.rewrite(req, u, i, n > 0)
+		if rerr != nil {
+			if resp == nil && err == nil {
+				err = rerr
+			}
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = hc.Do(r)
+		if err == nil && resp.StatusCode < 500 {
+			f.up(i)
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		if err != nil {
+			f.down(i, err.Error())
+		} else {
+			f.down(i, resp.Status)
+		}
+	}
+	return resp, err
+}
+
+// rewrite returns a copy of req for endpoint i. Retries need a fresh body.
+func (f *failover) rewrite(req *http.Request, u string, i int, retry bool) (*http.Request, error) {
+	if i == 0 && !retry {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	if retry && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body cannot be replayed")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	nu, err := url.Parse(f.bases[i] + u[len(f.bases[0]):])
+	if err != nil {
+		return nil, err
+	}
+	r.URL = nu
+	r.Host = nu.Host
+	return r, nil
+}
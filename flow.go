@@ -0,0 +1,173 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// Flow categories used when counterparties are collapsed.
+const (
+	FlowCategoryExchange = "exchange"
+	FlowCategoryBaker    = "baker"
+	FlowCategoryContract = "contract"
+	FlowCategoryOther    = "other"
+)
+
+// FlowOptions controls how AggregateFlows groups counterparties.
+type FlowOptions struct {
+	// Collapse replaces counterparties outside the requested address set
+	// with their metadata category (exchange, baker, contract or other).
+	Collapse bool
+
+	// Types lists operation types to include, defaults to transaction.
+	Types []string
+}
+
+// Flow is the total volume moved from source to target.
+type Flow struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Volume float64 `json:"volume"`
+	Count  int     `json:"count"`
+}
+
+// FlowGraph contains nodes and edges of aggregated money flows ready for
+// sankey or graph visualization. Nodes are addresses or categories.
+type FlowGraph struct {
+	Nodes []string `json:"nodes"`
+	Flows []Flow   `json:"flows"`
+}
+
+// Matrix returns flow volumes as source x target matrix indexed like Nodes.
+func (g *FlowGraph) Matrix() [][]float64 {
+	idx := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		idx[n] = i
+	}
+	m := make([][]float64, len(g.Nodes))
+	for i := range m {
+		m[i] = make([]float64, len(g.Nodes))
+	}
+	for _, f := range g.Flows {
+		m[idx[f.Source]][idx[f.Target]] += f.Volume
+	}
+	return m
+}
+
+// AggregateFlows sums the volume of successful operations sent or received
+// by addrs between block heights from and to (0 = up to now).
+func (c *Client) AggregateFlows(ctx context.Context, addrs []tezos.Address, from, to int64, opts FlowOptions) (*FlowGraph, error) {
+	types := opts.Types
+	if len(types) == 0 {
+		types = []string{"transaction"}
+	}
+	keys := make([]string, len(addrs))
+	self := make(map[string]bool, len(addrs))
+	for i, a := range addrs {
+		keys[i] = a.String()
+		self[keys[i]] = true
+	}
+
+	var categories map[string]string
+	if opts.Collapse {
+		md, err := c.ListMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		categories = flowCategories(md)
+	}
+	node := func(a tezos.Address) string {
+		s := a.String()
+		if !opts.Collapse || self[s] {
+			return s
+		}
+		if cat, ok := categories[s]; ok {
+			return cat
+		}
+		if a.Type == tezos.AddressTypeContract {
+			return FlowCategoryContract
+		}
+		return FlowCategoryOther
+	}
+
+	edges := make(map[[2]string]*Flow)
+	seen := make(map[uint64]struct{})
+	for _, col := range []string{"sender", "receiver"} {
+		for start, end := 0, 0; start < len(keys); start = end {
+			end = start + nextBatch(len(keys)-start, func(i int) int {
+				return len(keys[start+i])
+			})
+			q := c.NewOpQuery()
+			q.WithFilter(FilterModeIn, "type", types).
+				WithFilter(FilterModeIn, col, keys[start:end]).
+				WithFilter(FilterModeEqual, "status", "applied").
+				WithFilter(FilterModeGt, "volume", 0).
+				WithColumns("id", "sender", "receiver", "volume")
+			if to > 0 {
+				q.WithFilter(FilterModeRange, "height", from, to)
+			} else {
+				q.WithFilter(FilterModeGte, "height", from)
+			}
+			err := q.Each(ctx, func(ops *OpList) error {
+				for _, o := range ops.Rows {
+					if _, ok := seen[o.Id]; ok {
+						continue
+					}
+					seen[o.Id] = struct{}{}
+					k := [2]string{node(o.Sender), node(o.Receiver)}
+					e, ok := edges[k]
+					if !ok {
+						e = &Flow{Source: k[0], Target: k[1]}
+						edges[k] = e
+					}
+					e.Volume += o.Volume
+					e.Count++
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	g := &FlowGraph{
+		Flows: make([]Flow, 0, len(edges)),
+	}
+	nodes := make(map[string]struct{})
+	for _, e := range edges {
+		g.Flows = append(g.Flows, *e)
+		nodes[e.Source] = struct{}{}
+		nodes[e.Target] = struct{}{}
+	}
+	for n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Strings(g.Nodes)
+	sort.Slice(g.Flows, func(i, j int) bool {
+		return g.Flows[i].Volume > g.Flows[j].Volume
+	})
+	return g, nil
+}
+
+func flowCategories(md []Metadata) map[string]string {
+	m := make(map[string]string)
+	for _, v := range md {
+		if v.AssetId != nil {
+			continue
+		}
+		switch {
+		case v.Baker != nil:
+			m[v.Address.String()] = FlowCategoryBaker
+		case v.Alias != nil && v.Alias.Category != "":
+			m[v.Address.String()] = strings.ToLower(v.Alias.Category)
+		}
+	}
+	return m
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"time"
+)
+
+var DefaultFollowInterval = 10 * time.Second
+
+// DefaultFollowReorgDepth is the number of processed blocks a follower
+// remembers to find the fork point of a reorg.
+var DefaultFollowReorgDepth = 64
+
+// Follower tails the block and op tables and writes new data to a sink.
+// After each flushed batch it stores the last processed block height in a
+// checkpoint store, so a restarted follower continues from there and
+// delivers data at least once.
+type Follower struct {
+	client   *Client
+	sink     Sink
+	store    CheckpointStore
+	name     string
+	interval time.Duration
+	batch    int
+	last     BlockId
+	history  []BlockId // recently processed blocks, ascending
+	depth    int
+	onReorg  func(BlockId)
+}
+
+func (c *Client) NewFollower(name string, sink Sink) *Follower {
+	return &Follower{
+		client:   c,
+		sink:     sink,
+		store:    NewMemoryCheckpointStore(),
+		name:     name,
+		interval: DefaultFollowInterval,
+		batch:    100,
+		depth:    DefaultFollowReorgDepth,
+	}
+}
+
+func (f *Follower) WithCheckpoints(s CheckpointStore) *Follower {
+	f.store = s
+	return f
+}
+
+func (f *Follower) WithInterval(d time.Duration) *Follower {
+	f.interval = d
+	return f
+}
+
+// WithBatchSize sets the number of blocks processed per batch.
+func (f *Follower) WithBatchSize(n int) *Follower {
+	f.batch = n
+	return f
+}
+
+// WithStartHeight sets the height after which to start when no checkpoint
+// has been stored yet.
+func (f *Follower) WithStartHeight(h int64) *Follower {
+	f.last.Height = h
+	return f
+}
+
+// WithReorgDepth sets the number of processed blocks the follower
+// remembers to detect reorgs. Reorgs deeper than n blocks rewind to the
+// oldest remembered block.
+func (f *Follower) WithReorgDepth(n int) *Follower {
+	f.depth = n
+	return f
+}
+
+// OnReorg registers a callback that is called with the last processed
+// block when the follower detects a chain reorganization. The follower
+// walks back to the last block which is still part of the chain and
+// continues after it.
+func (f *Follower) OnReorg(fn func(BlockId)) *Follower {
+	f.onReorg = fn
+	return f
+}
+
+func (f *Follower) Height() int64 {
+	return f.last.Height
+}
+
+func (f *Follower) Run(ctx context.Context) error {
+	h, err := f.store.LoadCheckpoint(ctx, f.name)
+	if err != nil {
+		return err
+	}
+	if h > 0 {
+		f.last = BlockId{Height: int64(h)}
+	}
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		n, err := f.Step(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step processes the next batch of blocks and returns the number of blocks
+// written to the sink.
+func (f *Follower) Step(ctx context.Context) (int, error) {
+	bq := f.client.NewBlockQuery()
+	bq.WithColumns(append(bq.Columns, "predecessor")...).
+		WithFilter(FilterModeGt, "height", f.last.Height).
+		WithLimit(f.batch)
+	blocks, err := bq.Run(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if blocks.Len() == 0 {
+		return 0, nil
+	}
+
+	// check the first block connects to our last block
+	if first := blocks.Rows[0]; f.last.Hash.IsValid() && !f.last.IsNextBlock(first) {
+		log.Warnf("follower %s: reorg detected at height %d", f.name, first.Height)
+		if f.onReorg != nil {
+			f.onReorg(f.last)
+		}
+		if err := f.rewind(ctx); err != nil {
+			return 0, err
+		}
+		return 0, f.store.SaveCheckpoint(ctx, f.name, uint64(f.last.Height))
+	}
+
+	from, to := blocks.Rows[0].Height, blocks.Rows[blocks.Len()-1].Height
+	oq := f.client.NewOpQuery()
+	oq.WithFilter(FilterModeRange, "height", from, to)
+	err = oq.Each(ctx, func(ops *OpList) error {
+		if ops.Len() == 0 {
+			return nil
+		}
+		return f.sink.WriteOps(ctx, ops.Rows)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := f.sink.WriteBlocks(ctx, blocks.Rows); err != nil {
+		return 0, err
+	}
+	if err := f.sink.Flush(ctx); err != nil {
+		return 0, err
+	}
+	for _, b := range blocks.Rows {
+		f.history = append(f.history, b.BlockId())
+	}
+	if n := len(f.history) - f.depth; n > 0 {
+		f.history = append(f.history[:0], f.history[n:]...)
+	}
+	f.last = blocks.Rows[blocks.Len()-1].BlockId()
+	if err := f.store.SaveCheckpoint(ctx, f.name, uint64(f.last.Height)); err != nil {
+		return 0, err
+	}
+	return blocks.Len(), nil
+}
+
+// rewind moves the follower back to the last processed block whose hash
+// still matches the block at the same height.
+func (f *Follower) rewind(ctx context.Context) error {
+	if len(f.history) == 0 {
+		f.last = BlockId{Height: f.last.Height - 1}
+		return nil
+	}
+	first := f.history[0]
+	bq := f.client.NewBlockQuery()
+	bq.WithColumns("height", "hash").
+		WithFilter(FilterModeRange, "height", first.Height, f.last.Height).
+		WithLimit(len(f.history) + 1)
+	blocks, err := bq.Run(ctx)
+	if err != nil {
+		return err
+	}
+	byHeight := make(map[int64]*Block, blocks.Len())
+	for _, b := range blocks.Rows {
+		byHeight[b.Height] = b
+	}
+	for i := len(f.history) - 1; i >= 0; i-- {
+		id := f.history[i]
+		if b, ok := byHeight[id.Height]; ok && b.Hash.Equal(id.Hash) {
+			f.last = id
+			f.history = f.history[:i+1]
+			log.Infof("follower %s: rewind to block %d %s", f.name, id.Height, id.Hash)
+			return nil
+		}
+	}
+	log.Warnf("follower %s: reorg deeper than %d blocks", f.name, len(f.history))
+	f.last = BlockId{Height: first.Height - 1}
+	f.history = f.history[:0]
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale controls how numbers, amounts and times are rendered for humans.
+type Locale struct {
+	Name        string
+	Decimal     string            // decimal separator
+	Group       string            // thousands separator
+	Symbol      string            // currency symbol for tez amounts
+	SymbolFirst bool              // write symbol before the amount
+	DateLayout  string            // time.Format layout for absolute times
+	Now         string            // relative time below one second
+	Past        string            // relative past time, %s is replaced by the duration
+	Future      string            // relative future time
+	Units       [4][2]string      // second, minute, hour, day in singular and plural
+	Fee         string            // fee label in summaries
+	OpVerbs     map[OpType]string // optional op type names for summaries
+}
+
+var (
+	LocaleEN = Locale{
+		Name:       "en",
+		Decimal:    ".",
+		Group:      ",",
+		Symbol:     "ꜩ",
+		DateLayout: "Jan 2, 2006 15:04 MST",
+		Now:        "just now",
+		Past:       "%s ago",
+		Future:     "in %s",
+		Units:      [4][2]string{{"second", "seconds"}, {"minute", "minutes"}, {"hour", "hours"}, {"day", "days"}},
+		Fee:        "fee",
+	}
+	LocaleDE = Locale{
+		Name:       "de",
+		Decimal:    ",",
+		Group:      ".",
+		Symbol:     "ꜩ",
+		DateLayout: "02.01.2006 15:04 MST",
+		Now:        "gerade eben",
+		Past:       "vor %s",
+		Future:     "in %s",
+		Units:      [4][2]string{{"Sekunde", "Sekunden"}, {"Minute", "Minuten"}, {"Stunde", "Stunden"}, {"Tag", "Tagen"}},
+		Fee:        "Gebühr",
+	}
+	LocaleFR = Locale{
+		Name:       "fr",
+		Decimal:    ",",
+		Group:      " ",
+		Symbol:     "ꜩ",
+		DateLayout: "02/01/2006 15:04 MST",
+		Now:        "à l'instant",
+		Past:       "il y a %s",
+		Future:     "dans %s",
+		Units:      [4][2]string{{"seconde", "secondes"}, {"minute", "minutes"}, {"heure", "heures"}, {"jour", "jours"}},
+		Fee:        "frais",
+	}
+	LocaleES = Locale{
+		Name:       "es",
+		Decimal:    ",",
+		Group:      ".",
+		Symbol:     "ꜩ",
+		DateLayout: "02/01/2006 15:04 MST",
+		Now:        "ahora mismo",
+		Past:       "hace %s",
+		Future:     "en %s",
+		Units:      [4][2]string{{"segundo", "segundos"}, {"minuto", "minutos"}, {"hora", "horas"}, {"día", "días"}},
+		Fee:        "comisión",
+	}
+
+	// DefaultLocale is used by Op.Summary and other helpers without
+	// explicit locale.
+	DefaultLocale = LocaleEN
+)
+
+// FormatNumber renders v with at most prec decimals. Trailing zeros are
+// removed.
+func (l Locale) FormatNumber(v float64, prec int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return l.localize(strconv.FormatFloat(v, 'f', prec, 64))
+}
+
+// FormatAmount renders a tez amount with currency symbol.
+func (l Locale) FormatAmount(v float64) string {
+	return l.withSymbol(l.FormatNumber(v, 6), l.Symbol)
+}
+
+// FormatTokenAmount renders a raw token amount scaled by decimals followed
+// or preceded by symbol.
+func (l Locale) FormatTokenAmount(v *big.Int, decimals int, symbol string) string {
+	return l.withSymbol(l.localize(FormatTokenAmount(v, decimals)), symbol)
+}
+
+func (l Locale) FormatTime(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// FormatRelative renders t relative to now, e.g. "5 minutes ago".
+func (l Locale) FormatRelative(t, now time.Time) string {
+	d := now.Sub(t)
+	layout := l.Past
+	if d < 0 {
+		d, layout = -d, l.Future
+	}
+	if d < time.Second {
+		return l.Now
+	}
+	var n int64
+	var unit int
+	switch {
+	case d < time.Minute:
+		n, unit = int64(d/time.Second), 0
+	case d < time.Hour:
+		n, unit = int64(d/time.Minute), 1
+	case d < 24*time.Hour:
+		n, unit = int64(d/time.Hour), 2
+	default:
+		n, unit = int64(d/(24*time.Hour)), 3
+	}
+	name := l.Units[unit][1]
+	if n == 1 {
+		name = l.Units[unit][0]
+	}
+	return fmt.Sprintf(layout, strconv.FormatInt(n, 10)+" "+name)
+}
+
+func (l Locale) withSymbol(s, symbol string) string {
+	switch {
+	case symbol == "":
+		return s
+	case l.SymbolFirst:
+		return symbol + " " + s
+	default:
+		return s + " " + symbol
+	}
+}
+
+// localize converts a plain decimal string like "-1234.50" into locale
+// format. Trailing fractional zeros are removed.
+func (l Locale) localize(s string) string {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], strings.TrimRight(s[i+1:], "0")
+	}
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	for i := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteByte(whole[i])
+	}
+	if frac != "" {
+		b.WriteString(l.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Summary returns a short human readable description of the operation
+// using DefaultLocale, e.g.
+//
+//	transaction 1,234.5 ꜩ tz1irJKk...4yhk → KT1PWx2m...Xitn (fee 0.001 ꜩ), 5 minutes ago
+func (o *Op) Summary() string {
+	return o.SummaryLocale(DefaultLocale, time.Now())
+}
+
+// SummaryLocale returns a summary in locale l with times relative to now.
+func (o *Op) SummaryLocale(l Locale, now time.Time) string {
+	var b strings.Builder
+	verb := o.Type.String()
+	if v, ok := l.OpVerbs[o.Type]; ok {
+		verb = v
+	}
+	b.WriteString(verb)
+	if o.Volume != 0 {
+		b.WriteString(" ")
+		b.WriteString(l.FormatAmount(o.Volume))
+	}
+	if o.Sender.IsValid() {
+		b.WriteString(" ")
+		b.WriteString(o.Sender.Short())
+	}
+	if o.Receiver.IsValid() {
+		b.WriteString(" → ")
+		b.WriteString(o.Receiver.Short())
+	}
+	if o.Fee != 0 {
+		b.WriteString(" (")
+		b.WriteString(l.Fee)
+		b.WriteString(" ")
+		b.WriteString(l.FormatAmount(o.Fee))
+		b.WriteString(")")
+	}
+	if !o.Timestamp.IsZero() {
+		b.WriteString(", ")
+		b.WriteString(l.FormatRelative(o.Timestamp, now))
+	}
+	return b.String()
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"reflect"
+	"time"
+)
+
+// Frame is a column oriented copy of table rows for analytics in Go.
+// Column data is a typed slice: []int64, []uint64, []float64, []bool,
+// []string, []time.Time, [][]byte or [][]string. Hashes, addresses and
+// enums are strings, contract data is JSON text.
+//
+//	l, _ := q.Run(ctx)
+//	f, _ := l.Frame()
+//	var total float64
+//	for _, v := range f.Float64s("fee") {
+//		total += v
+//	}
+type Frame struct {
+	Columns []FrameColumn
+	Len     int
+}
+
+// FrameColumn holds values of one column. Null is set for rows with a
+// null value, it is nil when the column has no null values.
+type FrameColumn struct {
+	Name string
+	Data interface{}
+	Null []bool
+}
+
+// IsNull returns true when row i is null.
+func (c FrameColumn) IsNull(i int) bool {
+	return c.Null != nil && c.Null[i]
+}
+
+// NewFrame converts rows of table into a Frame with columns cols, or all
+// table columns when cols is empty. Rows may be a list like OpList, a
+// slice of row pointers or a single row.
+func NewFrame(table string, rows interface{}, cols ...string) (*Frame, error) {
+	ecols, err := exportColumns(table, cols)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := exportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	f := &Frame{
+		Columns: make([]FrameColumn, len(ecols)),
+		Len:     len(vals),
+	}
+	for i, c := range ecols {
+		col := FrameColumn{
+			Name: c.Name,
+			Data: makeFrameData(c.Kind, len(vals)),
+		}
+		data := reflect.ValueOf(col.Data)
+		for j, row := range vals {
+			v, err := c.value(row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				if col.Null == nil {
+					col.Null = make([]bool, len(vals))
+				}
+				col.Null[j] = true
+				continue
+			}
+			data.Index(j).Set(reflect.ValueOf(v))
+		}
+		f.Columns[i] = col
+	}
+	return f, nil
+}
+
+func makeFrameData(k exportKind, n int) interface{} {
+	switch k {
+	case exportInt:
+		return make([]int64, n)
+	case exportUint:
+		return make([]uint64, n)
+	case exportFloat:
+		return make([]float64, n)
+	case exportBool:
+		return make([]bool, n)
+	case exportTime:
+		return make([]time.Time, n)
+	case exportBytes:
+		return make([][]byte, n)
+	case exportStrings:
+		return make([][]string, n)
+	default:
+		return make([]string, n)
+	}
+}
+
+// Frame converts the list into a Frame with the list's query columns.
+func (l *OpList) Frame() (*Frame, error) {
+	return NewFrame("op", l, l.columns...)
+}
+
+// Frame converts the list into a Frame with the list's query columns.
+func (l *BlockList) Frame() (*Frame, error) {
+	return NewFrame("block", l, l.columns...)
+}
+
+// Names returns the column names.
+func (f *Frame) Names() []string {
+	names := make([]string, len(f.Columns))
+	for i, c := range f.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Column returns the named column.
+func (f *Frame) Column(name string) (FrameColumn, bool) {
+	for _, c := range f.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return FrameColumn{}, false
+}
+
+func (f *Frame) data(name string) interface{} {
+	c, _ := f.Column(name)
+	return c.Data
+}
+
+// Int64s returns the data of an integer column or nil.
+func (f *Frame) Int64s(name string) []int64 {
+	v, _ := f.data(name).([]int64)
+	return v
+}
+
+// Uint64s returns the data of a row id column or nil.
+func (f *Frame) Uint64s(name string) []uint64 {
+	v, _ := f.data(name).([]uint64)
+	return v
+}
+
+// Float64s returns the data of a float column or nil.
+func (f *Frame) Float64s(name string) []float64 {
+	v, _ := f.data(name).([]float64)
+	return v
+}
+
+// Bools returns the data of a bool column or nil.
+func (f *Frame) Bools(name string) []bool {
+	v, _ := f.data(name).([]bool)
+	return v
+}
+
+// Strings returns the data of a string column or nil.
+func (f *Frame) Strings(name string) []string {
+	v, _ := f.data(name).([]string)
+	return v
+}
+
+// Times returns the data of a time column or nil.
+func (f *Frame) Times(name string) []time.Time {
+	v, _ := f.data(name).([]time.Time)
+	return v
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build gota
+// +build gota
+
+package tzstats
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// DataFrame converts the frame into a gota DataFrame. Row ids become float
+// columns since gota has no unsigned type, times RFC3339 strings, binary
+// data hex strings and string lists comma separated strings. Null values
+// are NaN.
+func (f *Frame) DataFrame() dataframe.DataFrame {
+	cols := make([]series.Series, len(f.Columns))
+	for i, c := range f.Columns {
+		cols[i] = gotaSeries(c, f.Len)
+	}
+	return dataframe.New(cols...)
+}
+
+func gotaSeries(c FrameColumn, n int) series.Series {
+	var typ series.Type
+	switch c.Data.(type) {
+	case []int64:
+		typ = series.Int
+	case []uint64, []float64:
+		typ = series.Float
+	case []bool:
+		typ = series.Bool
+	default:
+		typ = series.String
+	}
+	data := reflect.ValueOf(c.Data)
+	vals := make([]interface{}, n)
+	for i := range vals {
+		if c.IsNull(i) {
+			continue
+		}
+		switch v := data.Index(i).Interface().(type) {
+		case int64:
+			vals[i] = int(v)
+		case uint64:
+			vals[i] = float64(v)
+		case time.Time:
+			vals[i] = v.Format(time.RFC3339)
+		case []byte:
+			vals[i] = hex.EncodeToString(v)
+		case []string:
+			vals[i] = strings.Join(v, ",")
+		default:
+			vals[i] = v
+		}
+	}
+	return series.New(vals, typ, c.Name)
+}
+
+// DataFrame converts the list into a gota DataFrame with the list's query
+// columns.
+func (l *OpList) DataFrame() (dataframe.DataFrame, error) {
+	f, err := l.Frame()
+	if err != nil {
+		return dataframe.DataFrame{}, err
+	}
+	return f.DataFrame(), nil
+}
+
+// DataFrame converts the list into a gota DataFrame with the list's query
+// columns.
+func (l *BlockList) DataFrame() (dataframe.DataFrame, error) {
+	f, err := l.Frame()
+	if err != nil {
+		return dataframe.DataFrame{}, err
+	}
+	return f.DataFrame(), nil
+}
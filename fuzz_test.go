@@ -0,0 +1,221 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build go1.18
+// +build go1.18
+
+package tzstats_test
+
+// Fuzz targets for the table row decoders.
+//
+//	go test -fuzz FuzzOpBrief
+//
+// Seeds are the API response rows in testdata/fuzz, the synthetic pages in
+// tzstatsbench and a set of malformed rows (short rows, wrong value types,
+// corrupt binary data). Decoders must return an error for bad input, any
+// panic is a bug.
+//
+// The BriefVerbose targets check that brief row decoders agree with the
+// verbose JSON decoders. Row values are converted into a JSON object and
+// both decoded results are compared.
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzstats-go"
+	"blockwatch.cc/tzstats-go/tzstatsbench"
+)
+
+// malformed rows which previously caused panics
+var badRows = []string{
+	`[]`,
+	`[1]`,
+	`["1","2","3"]`,
+	`[null,null,null]`,
+	`[{},[],true,false]`,
+	`[1,"oo",2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25,26,"00ff"]`,
+}
+
+// FuzzOpBrief fuzzes Op.UnmarshalJSONBrief with all columns of synthetic
+// op pages including bigmap updates.
+func FuzzOpBrief(f *testing.F) {
+	addRows(f, tzstatsbench.OpsPage(16, 0))
+	addRows(f, tzstatsbench.OpsPage(16, 3))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		op := (&tzstats.Op{}).WithColumns(tzstatsbench.OpColumns...)
+		_ = op.UnmarshalJSONBrief(data)
+	})
+}
+
+// FuzzBlockBrief fuzzes Block.UnmarshalJSONBrief.
+func FuzzBlockBrief(f *testing.F) {
+	addRows(f, tzstatsbench.BlocksPage(16))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		b := (&tzstats.Block{}).WithColumns(tzstatsbench.BlockColumns...)
+		_ = b.UnmarshalJSONBrief(data)
+	})
+}
+
+// FuzzBigmapDiff fuzzes binary bigmap diff decoding as used for the op
+// table's big_map_diff column.
+func FuzzBigmapDiff(f *testing.F) {
+	var rows [][]interface{}
+	_ = json.Unmarshal(tzstatsbench.OpsPage(16, 5), &rows)
+	for _, r := range rows {
+		if s, ok := r[len(r)-1].(string); ok {
+			if buf, err := hex.DecodeString(s); err == nil {
+				f.Add(buf)
+			}
+		}
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 0, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		row, _ := json.Marshal([]string{hex.EncodeToString(data)})
+		op := (&tzstats.Op{}).WithColumns("big_map_diff")
+		_ = op.UnmarshalJSONBrief(row)
+	})
+}
+
+// FuzzOpBriefVerbose compares Op brief and verbose decoding.
+func FuzzOpBriefVerbose(f *testing.F) {
+	addRows(f, tzstatsbench.OpsPage(16, 0))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkBriefVerbose(t, tzstatsbench.OpColumns, data, func(cols []string) briefModel {
+			return (&tzstats.Op{}).WithColumns(cols...)
+		})
+	})
+}
+
+// FuzzBlockBriefVerbose compares Block brief and verbose decoding.
+func FuzzBlockBriefVerbose(f *testing.F) {
+	addRows(f, tzstatsbench.BlocksPage(16))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkBriefVerbose(t, tzstatsbench.BlockColumns, data, func(cols []string) briefModel {
+			return (&tzstats.Block{}).WithColumns(cols...)
+		})
+	})
+}
+
+type briefModel interface {
+	UnmarshalJSONBrief([]byte) error
+	UnmarshalJSON([]byte) error
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// checkBriefVerbose decodes a brief row and the same values as verbose JSON
+// object and fails when both results differ. Columns with binary or list
+// encodings which have no verbose counterpart are skipped.
+func checkBriefVerbose(t *testing.T, cols []string, data []byte, fn func([]string) briefModel) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var row []interface{}
+	if err := dec.Decode(&row); err != nil || len(row) < len(cols) {
+		return
+	}
+	typ := reflect.Indirect(reflect.ValueOf(fn(nil))).Type()
+	var (
+		briefCols []string
+		briefRow  []interface{}
+		obj       = make(map[string]interface{})
+	)
+	for i, col := range cols {
+		field, name, ok := briefField(typ, col)
+		if !ok {
+			continue
+		}
+		v := row[i]
+		switch {
+		case v == nil:
+		case field == timeType:
+			n, ok := v.(json.Number)
+			ms, err := n.Int64()
+			if !ok || err != nil {
+				return
+			}
+			obj[name] = time.Unix(0, ms*1000000).UTC()
+		case field.Kind() == reflect.Bool:
+			n, ok := v.(json.Number)
+			b, err := strconv.ParseBool(n.String())
+			if !ok || err != nil {
+				return
+			}
+			obj[name] = b
+		default:
+			obj[name] = v
+		}
+		briefCols = append(briefCols, col)
+		briefRow = append(briefRow, v)
+	}
+	brief := fn(briefCols)
+	buf, _ := json.Marshal(briefRow)
+	if err := brief.UnmarshalJSONBrief(buf); err != nil {
+		return
+	}
+	verbose := fn(nil)
+	buf, _ = json.Marshal(obj)
+	if err := verbose.UnmarshalJSON(buf); err != nil {
+		// brief decoders accept some values verbose decoders reject
+		return
+	}
+	b1, _ := json.Marshal(brief)
+	b2, _ := json.Marshal(verbose)
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("brief and verbose rows differ\nbrief:   %s\nverbose: %s", b1, b2)
+	}
+}
+
+// briefField returns the type and json name of the model field decoded from
+// brief column col if it has a plain verbose encoding.
+func briefField(typ reflect.Type, col string) (reflect.Type, string, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if b, ok := f.Tag.Lookup("brief"); ok {
+			if b != col {
+				continue
+			}
+		} else if name != col {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft == timeType, ft == rawMessageType:
+		case reflect.PtrTo(ft).Implements(textUnmarshalerType):
+		case ft.Kind() >= reflect.Bool && ft.Kind() <= reflect.Float64, ft.Kind() == reflect.String:
+		default:
+			return nil, "", false
+		}
+		return ft, name, true
+	}
+	return nil, "", false
+}
+
+func addRows(f *testing.F, page []byte) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(page, &rows); err != nil {
+		f.Fatal(err)
+	}
+	for _, r := range rows {
+		f.Add([]byte(r))
+	}
+	for _, r := range badRows {
+		f.Add([]byte(r))
+	}
+}
@@ -4,8 +4,14 @@ go 1.16
 
 require (
 	blockwatch.cc/tzgo v1.13.1
+	github.com/apache/arrow/go/v9 v9.0.0
 	github.com/daviddengcn/go-colortext v1.0.0
 	github.com/echa/code v0.0.0-20201118130056-1878364e4ad4
-	github.com/echa/log v1.1.0
+	github.com/echa/log v1.2.0 // minimum required by blockwatch.cc/tzgo v1.13.1
+	github.com/go-gota/gota v0.12.0
 	github.com/hashicorp/golang-lru v0.5.4
+	github.com/nats-io/nats.go v1.16.0
+	github.com/prometheus/client_golang v1.12.2
+	github.com/segmentio/kafka-go v0.4.32
+	google.golang.org/protobuf v1.28.1
 )
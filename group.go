@@ -0,0 +1,196 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GroupErrorPolicy defines how a QueryGroup reacts to failed queries.
+type GroupErrorPolicy int
+
+const (
+	// GroupFailFast cancels all pending queries on the first error.
+	GroupFailFast GroupErrorPolicy = iota
+	// GroupCollectErrors runs all queries and reports every error.
+	GroupCollectErrors
+)
+
+// QueryGroupError lists the errors of all failed queries by name.
+type QueryGroupError struct {
+	Errors map[string]error
+}
+
+func (e QueryGroupError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for n := range e.Errors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	s := make([]string, len(names))
+	for i, n := range names {
+		s[i] = n + ": " + e.Errors[n].Error()
+	}
+	return fmt.Sprintf("%d queries failed: %s", len(s), strings.Join(s, "; "))
+}
+
+func IsQueryGroupError(err error) (QueryGroupError, bool) {
+	e, ok := err.(QueryGroupError)
+	return e, ok
+}
+
+// QueryResults holds the results of a QueryGroup by query name. Failed
+// queries have no result.
+type QueryResults map[string]interface{}
+
+func (r QueryResults) Ops(name string) *OpList {
+	v, _ := r[name].(*OpList)
+	return v
+}
+
+func (r QueryResults) Blocks(name string) *BlockList {
+	v, _ := r[name].(*BlockList)
+	return v
+}
+
+func (r QueryResults) Accounts(name string) *AccountList {
+	v, _ := r[name].(*AccountList)
+	return v
+}
+
+func (r QueryResults) Contracts(name string) *ContractList {
+	v, _ := r[name].(*ContractList)
+	return v
+}
+
+// QueryGroup runs several queries concurrently under one context and
+// returns all results together. Queries share an optional concurrency and
+// rate limit budget in addition to the client's own limits.
+type QueryGroup struct {
+	client  *Client
+	ctx     context.Context
+	policy  GroupErrorPolicy
+	limiter Limiter
+	maxConc int
+	names   []string
+	tasks   []func(context.Context) (interface{}, error)
+}
+
+func (c *Client) NewQueryGroup(ctx context.Context) *QueryGroup {
+	return &QueryGroup{
+		client: c,
+		ctx:    ctx,
+	}
+}
+
+func (g *QueryGroup) WithPolicy(p GroupErrorPolicy) *QueryGroup {
+	g.policy = p
+	return g
+}
+
+// WithLimiter makes each query in the group wait for l before it starts.
+func (g *QueryGroup) WithLimiter(l Limiter) *QueryGroup {
+	g.limiter = l
+	return g
+}
+
+// WithMaxConcurrency limits how many queries of the group run at a time.
+func (g *QueryGroup) WithMaxConcurrency(n int) *QueryGroup {
+	g.maxConc = n
+	return g
+}
+
+// Go adds a custom query function. Names must be unique within the group.
+func (g *QueryGroup) Go(name string, fn func(context.Context) (interface{}, error)) *QueryGroup {
+	g.names = append(g.names, name)
+	g.tasks = append(g.tasks, fn)
+	return g
+}
+
+func (g *QueryGroup) AddOps(name string, q OpQuery) *QueryGroup {
+	return g.Go(name, func(ctx context.Context) (interface{}, error) { return q.Run(ctx) })
+}
+
+func (g *QueryGroup) AddBlocks(name string, q BlockQuery) *QueryGroup {
+	return g.Go(name, func(ctx context.Context) (interface{}, error) { return q.Run(ctx) })
+}
+
+func (g *QueryGroup) AddAccounts(name string, q AccountQuery) *QueryGroup {
+	return g.Go(name, func(ctx context.Context) (interface{}, error) { return q.Run(ctx) })
+}
+
+func (g *QueryGroup) AddContracts(name string, q ContractQuery) *QueryGroup {
+	return g.Go(name, func(ctx context.Context) (interface{}, error) { return q.Run(ctx) })
+}
+
+// Wait runs all queries and waits for them to finish. Results of
+// successful queries are returned even when others failed. With
+// GroupFailFast the first error is returned, otherwise a QueryGroupError.
+func (g *QueryGroup) Wait() (QueryResults, error) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		sem   chan struct{}
+		first error
+		res   = make(QueryResults)
+		errs  = make(map[string]error)
+	)
+	if g.maxConc > 0 {
+		sem = make(chan struct{}, g.maxConc)
+	}
+	for i := range g.tasks {
+		wg.Add(1)
+		go func(name string, fn func(context.Context) (interface{}, error)) {
+			defer wg.Done()
+			v, err := g.run(ctx, sem, fn)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[name] = err
+				if first == nil {
+					first = err
+					if g.policy == GroupFailFast {
+						cancel()
+					}
+				}
+				return
+			}
+			res[name] = v
+		}(g.names[i], g.tasks[i])
+	}
+	wg.Wait()
+
+	switch {
+	case len(errs) == 0:
+		return res, nil
+	case g.policy == GroupFailFast:
+		return res, first
+	default:
+		return res, QueryGroupError{Errors: errs}
+	}
+}
+
+func (g *QueryGroup) run(ctx context.Context, sem chan struct{}, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if g.limiter != nil {
+		if err := g.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return fn(ctx)
+}
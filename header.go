@@ -0,0 +1,83 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// RawBlockHeader is a signed block header in decoded and binary form.
+// The indexer does not keep raw headers, they are loaded from a node.
+type RawBlockHeader struct {
+	Hash         tezos.BlockHash   `json:"hash"`
+	Header       codec.BlockHeader `json:"header"`
+	Bytes        []byte            `json:"bytes"`
+	Signature    tezos.Signature   `json:"signature"`
+	PayloadRound int               `json:"payload_round"`
+}
+
+func GetBlockHeaderRaw(ctx context.Context, node *rpc.Client, hash tezos.BlockHash) (*RawBlockHeader, error) {
+	var h codec.BlockHeader
+	if err := node.Get(ctx, "chains/main/blocks/"+hash.String()+"/header", &h); err != nil {
+		return nil, err
+	}
+	return &RawBlockHeader{
+		Hash:         hash,
+		Header:       h,
+		Bytes:        h.Bytes(),
+		Signature:    h.Signature,
+		PayloadRound: h.PayloadRound,
+	}, nil
+}
+
+// VerifyHash checks that the binary header hashes to the block hash.
+func (h RawBlockHeader) VerifyHash() error {
+	d := tezos.Digest(h.Bytes)
+	if hash := tezos.NewBlockHash(d[:]); !hash.Equal(h.Hash) {
+		return fmt.Errorf("header hash %s does not match block %s", hash, h.Hash)
+	}
+	return nil
+}
+
+// VerifySignature checks the header signature against the public key of
+// the block's baker. Signatures commit to the chain id.
+func (h RawBlockHeader) VerifySignature(key tezos.Key, chain tezos.ChainIdHash) error {
+	unsigned := h.Header
+	unsigned.Signature = tezos.Signature{}
+	unsigned.WithChainId(chain)
+	return key.Verify(unsigned.Digest(), h.Signature)
+}
+
+// VerifyBlockAuthor loads a block header from a node and verifies its hash
+// and that it was signed by the baker the indexer reports for this block.
+func (c *Client) VerifyBlockAuthor(ctx context.Context, node *rpc.Client, hash tezos.BlockHash) error {
+	h, err := GetBlockHeaderRaw(ctx, node, hash)
+	if err != nil {
+		return err
+	}
+	if err := h.VerifyHash(); err != nil {
+		return err
+	}
+	b, err := c.GetBlock(ctx, hash, NewBlockParams())
+	if err != nil {
+		return err
+	}
+	acc, err := c.GetAccount(ctx, b.Baker, NewAccountParams())
+	if err != nil {
+		return err
+	}
+	chain, err := node.GetChainId(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.VerifySignature(acc.Pubkey, chain); err != nil {
+		return fmt.Errorf("block %s: baker %s signature: %w", hash, b.Baker, err)
+	}
+	return nil
+}
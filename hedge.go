@@ -0,0 +1,125 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UseHedging enables request hedging for GET requests. When a response
+// does not arrive within delay a second request is sent, to the next
+// failover endpoint if configured or to the same endpoint otherwise, and
+// the first successful response is used. Use zero to disable.
+func (c *Client) UseHedging(delay time.Duration) {
+	c.hedgeDelay = delay
+}
+
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	hedged bool
+}
+
+func (r hedgeResult) ok() bool {
+	return r.err == nil && r.resp.StatusCode < 500
+}
+
+// finish hands the response to the caller. The request context is
+// canceled when the body is closed.
+func (r hedgeResult) finish() (*http.Response, error) {
+	if r.resp == nil {
+		r.cancel()
+		return nil, r.err
+	}
+	r.resp.Body = &cancelBody{r.resp.Body, r.cancel}
+	return r.resp, r.err
+}
+
+func (r hedgeResult) discard() {
+	r.cancel()
+	if r.resp != nil {
+		r.resp.Body.Close()
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isHedgeable(req *http.Request) bool {
+	return req.Method == http.MethodGet && (req.Body == nil || req.Body == http.NoBody)
+}
+
+func (c *Client) doHedged(req *http.Request) (*http.Response, error) {
+	ch := make(chan hedgeResult, 2)
+	ctx1, cancel1 := context.WithCancel(req.Context())
+	go func() {
+		resp, err := c.doOnce(req.WithContext(ctx1))
+		ch <- hedgeResult{resp, err, cancel1, false}
+	}()
+
+	t := time.NewTimer(c.hedgeDelay)
+	defer t.Stop()
+	select {
+	case r := <-ch:
+		return r.finish()
+	case <-t.C:
+	}
+
+	ctx2, cancel2 := context.WithCancel(req.Context())
+	hreq := c.hedgeRequest(ctx2, req)
+	log.Debugf("hedging %s %s", req.Method, redactAuth(hreq.URL))
+	go func() {
+		resp, err := c.httpClient.Do(hreq)
+		ch <- hedgeResult{resp, err, cancel2, true}
+	}()
+
+	r := <-ch
+	if !r.ok() {
+		// wait for the other request and keep the better result
+		o := <-ch
+		if o.ok() {
+			r.discard()
+			return o.finish()
+		}
+		o.discard()
+		return r.finish()
+	}
+	// abort the slower request right away
+	if r.hedged {
+		cancel1()
+	} else {
+		cancel2()
+	}
+	go func() {
+		o := <-ch
+		o.discard()
+	}()
+	return r.finish()
+}
+
+// hedgeRequest clones req for the next failover endpoint or the same URL.
+func (c *Client) hedgeRequest(ctx context.Context, req *http.Request) *http.Request {
+	if f := c.failover; f != nil {
+		u := req.URL.String()
+		if order := f.order(); len(order) > 1 && strings.HasPrefix(u, f.bases[0]) {
+			if r, err := f.rewrite(req, u, order[1], false); err == nil {
+				return r.WithContext(ctx)
+			}
+		}
+	}
+	return req.Clone(ctx)
+}
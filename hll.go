@@ -0,0 +1,181 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// DefaultHLLPrecision is the number of index bits used by new HyperLogLog
+// counters. 14 bits use 16kB of memory and have a standard error of ~0.8%.
+var DefaultHLLPrecision uint8 = 14
+
+// HyperLogLog is a probabilistic cardinality counter which estimates the
+// number of distinct values added using constant memory. It is not safe
+// for concurrent use.
+type HyperLogLog struct {
+	p   uint8
+	reg []uint8
+}
+
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < 4 || precision > 18 {
+		return nil, fmt.Errorf("hll: precision %d out of range [4,18]", precision)
+	}
+	return &HyperLogLog{
+		p:   precision,
+		reg: make([]uint8, 1<<precision),
+	}, nil
+}
+
+func (h *HyperLogLog) Add(buf []byte) {
+	f := fnv.New64a()
+	f.Write(buf)
+	h.addHash(mix64(f.Sum64()))
+}
+
+func (h *HyperLogLog) AddString(s string) {
+	h.Add([]byte(s))
+}
+
+func (h *HyperLogLog) AddAddress(a tezos.Address) {
+	h.Add(a.Bytes22())
+}
+
+func (h *HyperLogLog) addHash(x uint64) {
+	idx := x >> (64 - h.p)
+	rho := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1))) + 1
+	if rho > h.reg[idx] {
+		h.reg[idx] = rho
+	}
+}
+
+// Merge adds all values counted by another counter of equal precision.
+func (h *HyperLogLog) Merge(o *HyperLogLog) error {
+	if h.p != o.p {
+		return fmt.Errorf("hll: precision mismatch %d != %d", h.p, o.p)
+	}
+	for i, v := range o.reg {
+		if v > h.reg[i] {
+			h.reg[i] = v
+		}
+	}
+	return nil
+}
+
+// Count returns the estimated number of distinct values.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.reg))
+	var (
+		sum   float64
+		zeros int
+	)
+	for _, v := range h.reg {
+		sum += 1 / float64(uint64(1)<<v)
+		if v == 0 {
+			zeros++
+		}
+	}
+	e := hllAlpha(len(h.reg)) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// small range correction with linear counting
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(e + 0.5)
+}
+
+func hllAlpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
+
+// mix64 improves bit distribution of FNV hashes (splitmix64 finalizer).
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// CountUniqueCallers estimates the number of distinct senders of calls to
+// a contract in the height range [from, to]. Use to = 0 for no upper bound.
+// Ops are streamed page by page, so memory use is independent of the
+// number of calls. When the context deadline leaves no time for another
+// page, the count so far is returned with an ErrPartialResult.
+func (c *Client) CountUniqueCallers(ctx context.Context, contract tezos.Address, from, to int64) (uint64, error) {
+	h, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		return 0, err
+	}
+	q := c.NewOpQuery()
+	q.WithFilter(FilterModeEqual, "type", "transaction").
+		WithFilter(FilterModeEqual, "receiver", contract).
+		WithColumns("id", "sender")
+	if to > 0 {
+		q.WithFilter(FilterModeRange, "height", from, to)
+	} else {
+		q.WithFilter(FilterModeGte, "height", from)
+	}
+	err = q.Each(ctx, func(ops *OpList) error {
+		for _, o := range ops.Rows {
+			h.AddAddress(o.Sender)
+		}
+		return nil
+	})
+	return hllResult(h, err)
+}
+
+// CountUniqueBigmapKeys estimates the number of distinct keys ever written
+// to a bigmap in the height range [from, to], e.g. all token holders of a
+// ledger. Use to = 0 for no upper bound. Like CountUniqueCallers it returns
+// a partial count when the context deadline is near.
+func (c *Client) CountUniqueBigmapKeys(ctx context.Context, id int64, from, to int64) (uint64, error) {
+	h, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		return 0, err
+	}
+	q := c.NewBigmapUpdateQuery()
+	q.WithFilter(FilterModeEqual, "bigmap_id", id).
+		WithColumns("row_id", "hash")
+	if to > 0 {
+		q.WithFilter(FilterModeRange, "height", from, to)
+	} else {
+		q.WithFilter(FilterModeGte, "height", from)
+	}
+	err = q.Each(ctx, func(list *BigmapUpdateRowList) error {
+		for _, u := range list.Rows {
+			h.Add(u.Hash.Bytes())
+		}
+		return nil
+	})
+	return hllResult(h, err)
+}
+
+// hllResult returns the count of h after a cursor loop. Interrupted loops
+// return the count so far with their ErrPartialResult.
+func hllResult(h *HyperLogLog, err error) (uint64, error) {
+	if err == nil {
+		return h.Count(), nil
+	}
+	if _, ok := IsErrPartialResult(err); ok {
+		return h.Count(), err
+	}
+	return 0, err
+}
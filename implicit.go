@@ -0,0 +1,133 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// ImplicitOpTypes lists operation types the protocol injects without a
+// signed operation, like baking rewards, liquidity baking subsidies,
+// deposits and migration balance updates.
+var ImplicitOpTypes = []OpType{
+	OpTypeBake,
+	OpTypeUnfreeze,
+	OpTypeInvoice,
+	OpTypeAirdrop,
+	OpTypeSeedSlash,
+	OpTypeMigration,
+	OpTypeSubsidy,
+	OpTypeDeposit,
+	OpTypeBonus,
+	OpTypeReward,
+}
+
+// IsImplicit returns true for protocol injected operation types.
+func (t OpType) IsImplicit() bool {
+	for _, v := range ImplicitOpTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ImplicitOp is a decoded protocol injected balance update. Implicit
+// operations have no hash, they are identified by row id and block.
+type ImplicitOp struct {
+	Id        uint64          `json:"id"`
+	Type      OpType          `json:"type"`
+	Block     tezos.BlockHash `json:"block"`
+	Height    int64           `json:"height"`
+	Cycle     int64           `json:"cycle"`
+	Timestamp time.Time       `json:"time"`
+	OpN       int             `json:"op_n"`
+	Account   tezos.Address   `json:"account"` // receiver of the balance update
+	Source    tezos.Address   `json:"source"`  // sender, if any
+	Volume    float64         `json:"volume"`
+	Reward    float64         `json:"reward"`
+	Deposit   float64         `json:"deposit"`
+	Fee       float64         `json:"fee"`
+	Burned    float64         `json:"burned"`
+}
+
+func NewImplicitOp(o *Op) *ImplicitOp {
+	op := &ImplicitOp{
+		Id:        o.Id,
+		Type:      o.Type,
+		Block:     o.Block,
+		Height:    o.Height,
+		Cycle:     o.Cycle,
+		Timestamp: o.Timestamp,
+		OpN:       o.OpN,
+		Account:   o.Receiver,
+		Source:    o.Sender,
+		Volume:    o.Volume,
+		Reward:    o.Reward,
+		Deposit:   o.Deposit,
+		Fee:       o.Fee,
+		Burned:    o.Burned,
+	}
+	if !op.Account.IsValid() {
+		op.Account, op.Source = o.Sender, tezos.Address{}
+	}
+	return op
+}
+
+// Total returns the net balance change of the account.
+func (o *ImplicitOp) Total() float64 {
+	return o.Volume + o.Reward + o.Deposit + o.Fee - o.Burned
+}
+
+var ImplicitOpColumns = []string{
+	"id",
+	"type",
+	"block",
+	"height",
+	"cycle",
+	"time",
+	"op_n",
+	"sender",
+	"receiver",
+	"volume",
+	"reward",
+	"deposit",
+	"fee",
+	"burned",
+}
+
+// GetBlockImplicitOps returns all protocol injected operations of a block.
+func (c *Client) GetBlockImplicitOps(ctx context.Context, height int64) ([]*ImplicitOp, error) {
+	return c.ListImplicitOps(ctx, height, height)
+}
+
+// ListImplicitOps returns protocol injected operations in a block range,
+// optionally filtered by type.
+func (c *Client) ListImplicitOps(ctx context.Context, from, to int64, typs ...OpType) ([]*ImplicitOp, error) {
+	if len(typs) == 0 {
+		typs = ImplicitOpTypes
+	}
+	names := make([]string, len(typs))
+	for i, t := range typs {
+		names[i] = t.String()
+	}
+	q := c.NewOpQuery()
+	q.WithColumns(ImplicitOpColumns...).
+		WithFilter(FilterModeRange, "height", from, to).
+		WithFilter(FilterModeIn, "type", names)
+	res := make([]*ImplicitOp, 0)
+	err := q.Each(ctx, func(ops *OpList) error {
+		for _, o := range ops.Rows {
+			res = append(res, NewImplicitOp(o))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// ContentResult is the on-chain outcome of one content of a locally built
+// operation.
+type ContentResult struct {
+	Index       int             `json:"index"`
+	Kind        tezos.OpType    `json:"kind"`
+	Counter     int64           `json:"counter"`
+	Found       bool            `json:"found"`
+	Status      tezos.OpStatus  `json:"status"`
+	IsSuccess   bool            `json:"is_success"`
+	GasLimit    int64           `json:"gas_limit"`
+	GasUsed     int64           `json:"gas_used"`
+	StoragePaid int64           `json:"storage_paid"`
+	Fee         float64         `json:"fee"`
+	Errors      json.RawMessage `json:"errors,omitempty"`
+	Op          *Op             `json:"op,omitempty"`
+	Internal    []*Op           `json:"internal,omitempty"`
+}
+
+// InjectionResult correlates a locally built operation with the indexed
+// rows created after its inclusion.
+type InjectionResult struct {
+	Hash     tezos.OpHash    `json:"hash"`
+	Block    tezos.BlockHash `json:"block"`
+	Height   int64           `json:"height"`
+	Contents []ContentResult `json:"contents"`
+}
+
+// IsSuccess returns true when all contents were found and applied.
+func (r *InjectionResult) IsSuccess() bool {
+	for _, v := range r.Contents {
+		if !v.Found || !v.IsSuccess {
+			return false
+		}
+	}
+	return len(r.Contents) > 0
+}
+
+// GasUsed returns the total gas consumed by all contents including
+// internal operations.
+func (r *InjectionResult) GasUsed() int64 {
+	var n int64
+	for _, v := range r.Contents {
+		n += v.GasUsed
+		for _, i := range v.Internal {
+			n += i.GasUsed
+		}
+	}
+	return n
+}
+
+// OpHashOf returns the hash of a signed operation.
+func OpHashOf(op *codec.Op) (tezos.OpHash, error) {
+	if !op.Signature.IsValid() {
+		return tezos.OpHash{}, fmt.Errorf("operation is not signed")
+	}
+	buf := op.Bytes()
+	if buf == nil {
+		return tezos.OpHash{}, fmt.Errorf("operation has no branch or contents")
+	}
+	h := tezos.Digest(buf)
+	return tezos.NewOpHash(h[:]), nil
+}
+
+// CorrelateOp loads the indexed rows of an injected operation and matches
+// them with its contents. Returns ErrNotFound while the operation is not
+// yet included.
+func (c *Client) CorrelateOp(ctx context.Context, op *codec.Op) (*InjectionResult, error) {
+	hash, err := OpHashOf(op)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.GetOp(ctx, hash, NewOpParams())
+	if err != nil {
+		return nil, err
+	}
+	return MatchOpContents(op, rows)
+}
+
+// MatchOpContents matches contents of a locally built operation with
+// indexed rows. Manager operations are matched by counter, all others by
+// their position in the contents list. Internal operations are assigned
+// to the content which emitted them.
+func MatchOpContents(op *codec.Op, rows []*Op) (*InjectionResult, error) {
+	if len(rows) == 0 {
+		return nil, ErrNotFound
+	}
+	// top level contents in order, internal rows which are not nested
+	// below their content are assigned by content position
+	var (
+		contents []*Op
+		internal []*Op
+	)
+	for _, r := range rows {
+		switch {
+		case r.IsInternal:
+			internal = append(internal, r)
+		case r.IsBatch && len(r.Batch) > 0:
+			contents = append(contents, r.Batch...)
+		default:
+			contents = append(contents, r)
+		}
+	}
+	if len(contents) == 0 {
+		return nil, ErrNotFound
+	}
+	// servers which send content positions set them on all but the first
+	hasPos := false
+	for _, r := range contents {
+		hasPos = hasPos || r.OpC > 0
+	}
+	res := &InjectionResult{
+		Hash:     contents[0].Hash,
+		Block:    contents[0].Block,
+		Height:   contents[0].Height,
+		Contents: make([]ContentResult, len(op.Contents)),
+	}
+	for i, v := range op.Contents {
+		cr := ContentResult{
+			Index:   i,
+			Kind:    v.Kind(),
+			Counter: v.GetCounter(),
+		}
+		for j, r := range contents {
+			pos := j
+			if hasPos {
+				pos = r.OpC
+			}
+			if cr.Counter > 0 && r.Counter != cr.Counter {
+				continue
+			}
+			if cr.Counter == 0 && pos != i {
+				continue
+			}
+			cr.Found = true
+			cr.Op = r
+			cr.Status = r.Status
+			cr.IsSuccess = r.IsSuccess
+			cr.GasLimit = r.GasLimit
+			cr.GasUsed = r.GasUsed
+			cr.StoragePaid = r.StoragePaid
+			cr.Fee = r.Fee
+			cr.Errors = r.Errors
+			cr.Internal = append(cr.Internal, r.Internal...)
+			for _, x := range internal {
+				// without positions only a single content is unambiguous
+				if (hasPos && x.OpC == r.OpC) || (!hasPos && len(contents) == 1) {
+					cr.Internal = append(cr.Internal, x)
+				}
+			}
+			sort.SliceStable(cr.Internal, func(a, b int) bool {
+				return cr.Internal[a].OpI < cr.Internal[b].OpI
+			})
+			break
+		}
+		res.Contents[i] = cr
+	}
+	return res, nil
+}
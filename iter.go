@@ -0,0 +1,80 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build go1.23
+// +build go1.23
+
+package tzstats
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// errStopIter ends the page loop of an iterator when the caller stops.
+var errStopIter = errors.New("iterator stopped")
+
+// All returns an iterator over all operations in the list.
+func (l OpList) All() iter.Seq[*Op] {
+	return func(yield func(*Op) bool) {
+		for _, v := range l.Rows {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over all blocks in the list.
+func (l BlockList) All() iter.Seq[*Block] {
+	return func(yield func(*Block) bool) {
+		for _, v := range l.Rows {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Iter runs the query and returns an iterator over all matching operations.
+// Result pages are fetched on demand while the loop advances. On failure
+// the iterator yields the error and stops. When the context deadline
+// leaves no time for another page it yields an ErrPartialResult. The query
+// is not modified.
+func (q OpQuery) Iter(ctx context.Context) iter.Seq2[*Op, error] {
+	return func(yield func(*Op, error) bool) {
+		err := q.Each(ctx, func(l *OpList) error {
+			for _, v := range l.Rows {
+				if !yield(v, nil) {
+					return errStopIter
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIter) {
+			yield(nil, err)
+		}
+	}
+}
+
+// Iter runs the query and returns an iterator over all matching blocks.
+// Result pages are fetched on demand while the loop advances. On failure
+// the iterator yields the error and stops. When the context deadline
+// leaves no time for another page it yields an ErrPartialResult. The query
+// is not modified.
+func (q BlockQuery) Iter(ctx context.Context) iter.Seq2[*Block, error] {
+	return func(yield func(*Block, error) bool) {
+		err := q.Each(ctx, func(l *BlockList) error {
+			for _, v := range l.Rows {
+				if !yield(v, nil) {
+					return errStopIter
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIter) {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// DefaultLineageDepth limits how many origination levels GetContractLineage
+// follows up and down from the requested contract.
+var DefaultLineageDepth = 16
+
+// ContractLineage describes where a contract comes from and what it has
+// originated. Path lists factory contracts from the originating implicit
+// account (Root) down to the contract's direct creator. Tree contains the
+// contract itself and all contracts it originated, directly or through
+// internal originations of its children.
+type ContractLineage struct {
+	Root tezos.Address   `json:"root"`
+	Path []tezos.Address `json:"path"`
+	Tree *LineageNode    `json:"tree"`
+}
+
+type LineageNode struct {
+	Address       tezos.Address  `json:"address"`
+	Creator       tezos.Address  `json:"creator"`
+	FirstSeen     int64          `json:"first_seen"`
+	FirstSeenTime time.Time      `json:"first_seen_time"`
+	CodeHash      string         `json:"code_hash"`
+	Children      []*LineageNode `json:"children,omitempty"`
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order.
+func (n *LineageNode) Walk(fn func(*LineageNode, int) error) error {
+	return n.walk(fn, 0)
+}
+
+func (n *LineageNode) walk(fn func(*LineageNode, int) error, depth int) error {
+	if err := fn(n, depth); err != nil {
+		return err
+	}
+	for _, v := range n.Children {
+		if err := v.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newLineageNode(c *Contract) *LineageNode {
+	return &LineageNode{
+		Address:       c.Address,
+		Creator:       c.Creator,
+		FirstSeen:     c.FirstSeen,
+		FirstSeenTime: c.FirstSeenTime,
+		CodeHash:      c.CodeHash,
+	}
+}
+
+// GetContractLineage walks originations from a contract up to the implicit
+// account which started the chain and down through all contracts it
+// created, which is useful to analyze deployments of factory contracts.
+func (c *Client) GetContractLineage(ctx context.Context, addr tezos.Address) (*ContractLineage, error) {
+	cc, err := c.GetContract(ctx, addr, NewContractParams())
+	if err != nil {
+		return nil, err
+	}
+	l := &ContractLineage{
+		Root: cc.Creator,
+		Path: make([]tezos.Address, 0),
+		Tree: newLineageNode(cc),
+	}
+
+	// walk up while the creator is a contract
+	for i := 0; i < DefaultLineageDepth && l.Root.Type == tezos.AddressTypeContract; i++ {
+		l.Path = append([]tezos.Address{l.Root}, l.Path...)
+		p, err := c.GetContract(ctx, l.Root, NewContractParams())
+		if err != nil {
+			return nil, err
+		}
+		l.Root = p.Creator
+	}
+
+	// walk down level by level
+	level := []*LineageNode{l.Tree}
+	for i := 0; i < DefaultLineageDepth && len(level) > 0; i++ {
+		next := make([]*LineageNode, 0)
+		for _, n := range level {
+			children, err := c.listOriginated(ctx, n.Address)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = children
+			next = append(next, children...)
+		}
+		level = next
+	}
+	return l, nil
+}
+
+func (c *Client) listOriginated(ctx context.Context, creator tezos.Address) ([]*LineageNode, error) {
+	q := c.NewContractQuery()
+	q.WithColumns("row_id", "address", "creator", "first_seen", "first_seen_time", "code_hash").
+		WithFilter(FilterModeEqual, "creator", creator)
+	res := make([]*LineageNode, 0)
+	err := q.Each(ctx, func(list *ContractList) error {
+		for _, v := range list.Rows {
+			res = append(res, newLineageNode(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
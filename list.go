@@ -0,0 +1,109 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"sort"
+)
+
+// SortByTime sorts operations in place by time, oldest first. Operations
+// in the same block keep their order.
+func (l OpList) SortByTime() {
+	sort.SliceStable(l.Rows, func(i, j int) bool {
+		return l.Rows[i].Timestamp.Before(l.Rows[j].Timestamp)
+	})
+}
+
+// FilterFunc returns a new list with all operations for which fn is true.
+func (l OpList) FilterFunc(fn func(*Op) bool) OpList {
+	res := OpList{
+		Rows:     make([]*Op, 0),
+		withPrim: l.withPrim,
+		columns:  l.columns,
+	}
+	for _, v := range l.Rows {
+		if fn(v) {
+			res.Rows = append(res.Rows, v)
+		}
+	}
+	return res
+}
+
+// GroupByCycle splits operations into lists per cycle.
+func (l OpList) GroupByCycle() map[int64]OpList {
+	res := make(map[int64]OpList)
+	for _, v := range l.Rows {
+		g := res[v.Cycle]
+		g.Rows = append(g.Rows, v)
+		g.withPrim = l.withPrim
+		g.columns = l.columns
+		res[v.Cycle] = g
+	}
+	return res
+}
+
+func (l OpList) SumVolume() float64 {
+	var sum float64
+	for _, v := range l.Rows {
+		sum += v.Volume
+	}
+	return sum
+}
+
+func (l OpList) SumFee() float64 {
+	var sum float64
+	for _, v := range l.Rows {
+		sum += v.Fee
+	}
+	return sum
+}
+
+// SortByTime sorts blocks in place by time, oldest first.
+func (l BlockList) SortByTime() {
+	sort.SliceStable(l.Rows, func(i, j int) bool {
+		return l.Rows[i].Timestamp.Before(l.Rows[j].Timestamp)
+	})
+}
+
+// FilterFunc returns a new list with all blocks for which fn is true.
+func (l BlockList) FilterFunc(fn func(*Block) bool) BlockList {
+	res := BlockList{
+		Rows:    make([]*Block, 0),
+		columns: l.columns,
+	}
+	for _, v := range l.Rows {
+		if fn(v) {
+			res.Rows = append(res.Rows, v)
+		}
+	}
+	return res
+}
+
+// GroupByCycle splits blocks into lists per cycle.
+func (l BlockList) GroupByCycle() map[int64]BlockList {
+	res := make(map[int64]BlockList)
+	for _, v := range l.Rows {
+		g := res[v.Cycle]
+		g.Rows = append(g.Rows, v)
+		g.columns = l.columns
+		res[v.Cycle] = g
+	}
+	return res
+}
+
+func (l BlockList) SumVolume() float64 {
+	var sum float64
+	for _, v := range l.Rows {
+		sum += v.Volume
+	}
+	return sum
+}
+
+func (l BlockList) SumFee() float64 {
+	var sum float64
+	for _, v := range l.Rows {
+		sum += v.Fee
+	}
+	return sum
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+// Logger is a structured leveled logger. Arguments are alternating keys and
+// values. A *slog.Logger from the standard library satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// UseLogger enables structured logging of requests, retries, decode errors
+// and cache events for this client. It is independent of the package-level
+// logger set by the UseLogger function.
+func (c *Client) UseLogger(l Logger) {
+	c.logger = l
+}
+
+func (c *Client) logDebug(msg string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Debug(msg, args...)
+	}
+}
+
+func (c *Client) logWarn(msg string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Warn(msg, args...)
+	}
+}
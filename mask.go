@@ -0,0 +1,183 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+type MaskMode byte
+
+const (
+	// MaskStrip resets a field to its zero value so it is omitted or empty
+	// in encoded output.
+	MaskStrip MaskMode = iota
+
+	// MaskHash replaces a field with a keyed hash of its value. Equal values
+	// map to equal hashes, so masked data can still be joined and grouped.
+	// Addresses are replaced by a pseudonymous address of the same type,
+	// strings by a hex encoded hash. Fields of other types are stripped.
+	MaskHash
+)
+
+// Mask removes or pseudonymizes selected fields from decoded rows before
+// they are shared. Fields are selected by JSON name, e.g. "sender" or
+// "metadata". Masks never modify their input, they return copies.
+type Mask struct {
+	key    []byte
+	fields map[string]MaskMode
+}
+
+// NewMask creates an empty mask. The key is used for hashing, keep it
+// secret to prevent recovering hashed values by brute force.
+func NewMask(key []byte) *Mask {
+	return &Mask{
+		key:    key,
+		fields: make(map[string]MaskMode),
+	}
+}
+
+func (m *Mask) Strip(fields ...string) *Mask {
+	for _, f := range fields {
+		m.fields[f] = MaskStrip
+	}
+	return m
+}
+
+func (m *Mask) Hash(fields ...string) *Mask {
+	for _, f := range fields {
+		m.fields[f] = MaskHash
+	}
+	return m
+}
+
+// MaskOps returns masked copies of ops.
+func (m *Mask) MaskOps(ops []*Op) []*Op {
+	res := make([]*Op, len(ops))
+	for i, o := range ops {
+		cp := *o
+		m.apply(reflect.ValueOf(&cp).Elem(), true)
+		res[i] = &cp
+	}
+	return res
+}
+
+// MaskBlocks returns masked copies of blocks.
+func (m *Mask) MaskBlocks(blocks []*Block) []*Block {
+	res := make([]*Block, len(blocks))
+	for i, b := range blocks {
+		cp := *b
+		m.apply(reflect.ValueOf(&cp).Elem(), true)
+		res[i] = &cp
+	}
+	return res
+}
+
+// Apply masks fields of a row and its nested rows like batch and internal
+// operations in place. Row must be a pointer to struct.
+func (m *Mask) Apply(row interface{}) {
+	v := reflect.ValueOf(row)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	m.apply(v.Elem(), false)
+}
+
+// apply masks fields of v and recurses into lists of nested rows of the
+// same type. When clone is true nested rows are copied before masking.
+func (m *Mask) apply(v reflect.Value, clone bool) {
+	idx := jsonFieldIndex(v.Type())
+	for name, mode := range m.fields {
+		i, ok := idx[name]
+		if !ok {
+			continue
+		}
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		if mode == MaskHash && m.hash(f) {
+			continue
+		}
+		f.Set(reflect.Zero(f.Type()))
+	}
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.Slice || f.Type().Elem() != reflect.PtrTo(typ) || f.Len() == 0 || !f.CanSet() {
+			continue
+		}
+		if clone {
+			cp := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+			for j := 0; j < f.Len(); j++ {
+				if el := f.Index(j); !el.IsNil() {
+					n := reflect.New(typ)
+					n.Elem().Set(el.Elem())
+					cp.Index(j).Set(n)
+				}
+			}
+			f.Set(cp)
+		}
+		for j := 0; j < f.Len(); j++ {
+			if el := f.Index(j); !el.IsNil() {
+				m.apply(el.Elem(), clone)
+			}
+		}
+	}
+}
+
+func (m *Mask) hash(f reflect.Value) bool {
+	switch val := f.Interface().(type) {
+	case tezos.Address:
+		if !val.IsValid() {
+			return true
+		}
+		h := m.sum(val.Bytes())
+		f.Set(reflect.ValueOf(tezos.NewAddress(val.Type, h[:20])))
+		return true
+	case string:
+		if val == "" {
+			return true
+		}
+		h := m.sum([]byte(val))
+		f.SetString(hex.EncodeToString(h))
+		return true
+	}
+	return false
+}
+
+func (m *Mask) sum(buf []byte) []byte {
+	h := hmac.New(sha256.New, m.key)
+	h.Write(buf)
+	return h.Sum(nil)
+}
+
+// MaskSink forwards masked copies of operations and blocks to the wrapped
+// sink.
+type MaskSink struct {
+	sink Sink
+	mask *Mask
+}
+
+func NewMaskSink(s Sink, m *Mask) *MaskSink {
+	return &MaskSink{sink: s, mask: m}
+}
+
+func (s *MaskSink) WriteOps(ctx context.Context, ops []*Op) error {
+	return s.sink.WriteOps(ctx, s.mask.MaskOps(ops))
+}
+
+func (s *MaskSink) WriteBlocks(ctx context.Context, blocks []*Block) error {
+	return s.sink.WriteBlocks(ctx, s.mask.MaskBlocks(blocks))
+}
+
+func (s *MaskSink) Flush(ctx context.Context) error {
+	return s.sink.Flush(ctx)
+}
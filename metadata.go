@@ -7,7 +7,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"blockwatch.cc/tzgo/micheline"
@@ -405,3 +408,53 @@ func (c *Client) Describe(ctx context.Context, ident string) (MetadataDescriptor
 	}
 	return resp, nil
 }
+
+// UseStrictMetadata controls how requests with metadata enrichment (meta=1)
+// behave when metadata is unavailable. By default such requests are
+// repeated without metadata and a warning is added to Warnings. In strict
+// mode the error is returned instead.
+func (c *Client) UseStrictMetadata(strict bool) {
+	c.strictMeta = strict
+}
+
+// isMetadataError returns true when err is a server error caused by the
+// metadata service, i.e. a 5xx API error with metadata scope or a message
+// which names metadata. Other server errors are not repeated.
+func isMetadataError(err error) bool {
+	mentions := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), "metadata")
+	}
+	switch e := err.(type) {
+	case ApiErrors:
+		for _, v := range e.Errors {
+			if v.Status >= 500 && (v.Scope == "metadata" || mentions(v.Message) || mentions(v.Detail)) {
+				return true
+			}
+		}
+	case HttpError:
+		return e.Status >= 500 && mentions(e.Data)
+	}
+	return false
+}
+
+// withoutMetadata returns path without metadata enrichment when a failed
+// GET request can be repeated without it.
+func (c *Client) withoutMetadata(method, path string, err error) (string, bool) {
+	if err == nil || c.strictMeta || method != http.MethodGet {
+		return "", false
+	}
+	if !isMetadataError(err) {
+		return "", false
+	}
+	u, perr := url.Parse(path)
+	if perr != nil {
+		return "", false
+	}
+	q := u.Query()
+	if q.Get("meta") == "" {
+		return "", false
+	}
+	q.Del("meta")
+	u.RawQuery = q.Encode()
+	return u.String(), true
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Metrics receives client instrumentation. Endpoint labels are short API
+// paths like "explorer/account" or "tables/op" without ids so they can be
+// used as metric labels. Status is zero when no response was received.
+type Metrics interface {
+	ObserveRequest(endpoint string, status int, latency time.Duration)
+	ObserveRows(table string, rows int)
+	SetCacheSize(n int)
+}
+
+// UseMetrics enables client instrumentation.
+func (c *Client) UseMetrics(m Metrics) {
+	c.metrics = m
+}
+
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	var status int
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(endpointLabel(c.params, req.URL.Path), status, time.Since(start))
+	if c.cache != nil {
+		c.metrics.SetCacheSize(c.cache.Len())
+	}
+}
+
+func (c *Client) observeRows(q TableQuery, result interface{}) {
+	if c.metrics == nil {
+		return
+	}
+	l, ok := result.(interface{ Len() int })
+	if !ok {
+		return
+	}
+	var table string
+	if tq, ok := q.(*tableQuery); ok {
+		table = tq.Table
+	}
+	c.metrics.ObserveRows(table, l.Len())
+}
+
+// endpointLabel reduces a request path to its first two segments after the
+// API prefix and strips table format suffixes.
+func endpointLabel(p Params, path string) string {
+	path = strings.Trim(path, "/")
+	if p.Prefix != "" {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, p.Prefix), "/")
+	}
+	fields := strings.SplitN(path, "/", 3)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	if len(fields) == 2 && fields[0] == "tables" {
+		fields[1] = strings.SplitN(fields[1], ".", 2)[0]
+	}
+	return strings.Join(fields, "/")
+}
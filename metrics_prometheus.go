@@ -0,0 +1,74 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build prometheus
+// +build prometheus
+
+package tzstats
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusMetrics exports client metrics as Prometheus collectors.
+type PrometheusMetrics struct {
+	requests  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	rows      *prometheus.HistogramVec
+	cacheSize prometheus.Gauge
+}
+
+// NewPrometheusMetrics creates collectors in namespace ns and registers
+// them with reg.
+func NewPrometheusMetrics(ns string, reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "tzstats",
+			Name:      "requests_total",
+			Help:      "Number of API requests by endpoint and HTTP status.",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "tzstats",
+			Name:      "request_duration_seconds",
+			Help:      "API request latency by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		rows: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "tzstats",
+			Name:      "rows_decoded",
+			Help:      "Number of rows decoded per table query.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"table"}),
+		cacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "tzstats",
+			Name:      "script_cache_entries",
+			Help:      "Number of entries in the contract script cache.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.latency, m.rows, m.cacheSize} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *PrometheusMetrics) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	m.requests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	m.latency.WithLabelValues(endpoint).Observe(latency.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveRows(table string, rows int) {
+	m.rows.WithLabelValues(table).Observe(float64(rows))
+}
+
+func (m *PrometheusMetrics) SetCacheSize(n int) {
+	m.cacheSize.Set(float64(n))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the HTTP transport used by a client to inspect or
+// modify requests and responses.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Use adds middlewares to the client's transport. Middlewares run in the
+// order they were added for requests and in reverse order for responses.
+// The http.Client passed to NewClient is not modified.
+func (c *Client) Use(mw ...Middleware) {
+	if len(mw) == 0 {
+		return
+	}
+	c.middleware = append(c.middleware, mw...)
+	c.applyTransport()
+}
+
+// BeforeRequest returns a middleware calling fn before each request is
+// sent. Requests fail with the error fn returns.
+func BeforeRequest(fn func(*http.Request) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if err := fn(r); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// AfterResponse returns a middleware calling fn for each response
+// received. The response body must not be consumed by fn.
+func AfterResponse(fn func(*http.Response) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(r)
+			if err != nil {
+				return resp, err
+			}
+			if err := fn(resp); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		})
+	}
+}
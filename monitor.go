@@ -0,0 +1,182 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+var DefaultMonitorInterval = 30 * time.Second
+
+// Denunciation is a decoded double baking, double endorsement or double
+// preendorsement operation. On the op table the accuser is stored as sender
+// and the offender as receiver.
+type Denunciation struct {
+	Id         uint64          `json:"id"`
+	Hash       tezos.OpHash    `json:"hash"`
+	Type       OpType          `json:"type"`
+	Block      tezos.BlockHash `json:"block"`
+	Height     int64           `json:"height"`
+	Cycle      int64           `json:"cycle"`
+	Timestamp  time.Time       `json:"time"`
+	Offender   tezos.Address   `json:"offender"`
+	Accuser    tezos.Address   `json:"accuser"`
+	Lost       float64         `json:"lost"`   // total amount lost by offender
+	Reward     float64         `json:"reward"` // accuser reward
+	Burned     float64         `json:"burned"` // burned part of the loss
+	Delegators []Delegator     `json:"delegators,omitempty"`
+}
+
+func NewDenunciation(o *Op) *Denunciation {
+	return &Denunciation{
+		Id:        o.Id,
+		Hash:      o.Hash,
+		Type:      o.Type,
+		Block:     o.Block,
+		Height:    o.Height,
+		Cycle:     o.Cycle,
+		Timestamp: o.Timestamp,
+		Offender:  o.Receiver,
+		Accuser:   o.Sender,
+		Lost:      o.Volume,
+		Reward:    o.Reward,
+		Burned:    o.Burned,
+	}
+}
+
+type DenunciationHandler func(*Denunciation) error
+
+// DenunciationMonitor polls the op table for new double baking and double
+// endorsing evidence and forwards decoded denunciations to a handler.
+type DenunciationMonitor struct {
+	client         *Client
+	interval       time.Duration
+	cursor         uint64
+	withDelegators bool
+	throttle       *Throttle
+	failed         uint64 // op id the Run handler failed on, already passed the throttle
+}
+
+var DenunciationColumns = []string{
+	"id",
+	"hash",
+	"type",
+	"block",
+	"height",
+	"cycle",
+	"time",
+	"volume",
+	"reward",
+	"burned",
+	"sender",
+	"receiver",
+}
+
+func (c *Client) NewDenunciationMonitor() *DenunciationMonitor {
+	return &DenunciationMonitor{
+		client:   c,
+		interval: DefaultMonitorInterval,
+	}
+}
+
+func (m *DenunciationMonitor) WithInterval(d time.Duration) *DenunciationMonitor {
+	m.interval = d
+	return m
+}
+
+// WithCursor sets the op row id after which the monitor starts. Use this
+// to resume from the last seen denunciation.
+func (m *DenunciationMonitor) WithCursor(c uint64) *DenunciationMonitor {
+	m.cursor = c
+	return m
+}
+
+// WithDelegators enables loading the offender's delegators from the stake
+// snapshot of the denunciation cycle.
+func (m *DenunciationMonitor) WithDelegators(b bool) *DenunciationMonitor {
+	m.withDelegators = b
+	return m
+}
+
+// WithThrottle suppresses repeated notifications in Run. The rule is the
+// denunciation type and the key is the offender address.
+func (m *DenunciationMonitor) WithThrottle(t *Throttle) *DenunciationMonitor {
+	m.throttle = t
+	return m
+}
+
+func (m *DenunciationMonitor) Cursor() uint64 {
+	return m.cursor
+}
+
+// Poll fetches all denunciations after the current cursor and advances it
+// past the returned ones. When a request fails, denunciations loaded
+// before the failure are returned with the error.
+func (m *DenunciationMonitor) Poll(ctx context.Context) ([]*Denunciation, error) {
+	res, err := m.fetch(ctx)
+	if len(res) > 0 {
+		m.cursor = res[len(res)-1].Id
+	}
+	return res, err
+}
+
+// fetch loads denunciations after the cursor without advancing it.
+func (m *DenunciationMonitor) fetch(ctx context.Context) ([]*Denunciation, error) {
+	q := m.client.NewOpQuery()
+	q.WithColumns(DenunciationColumns...).
+		WithFilter(FilterModeIn, "type",
+			OpTypeDoubleBaking,
+			OpTypeDoubleEndorsement,
+			OpTypeDoublePreendorsement,
+		)
+	q.Cursor = m.cursor
+	res := make([]*Denunciation, 0)
+	err := q.Each(ctx, func(ops *OpList) error {
+		for _, o := range ops.Rows {
+			d := NewDenunciation(o)
+			if m.withDelegators {
+				snap, err := m.client.GetBakerSnapshot(ctx, d.Offender, d.Cycle, NewBakerParams())
+				if err != nil {
+					return err
+				}
+				d.Delegators = snap.Delegators
+			}
+			res = append(res, d)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Run polls for new denunciations until the context is canceled or the
+// handler returns an error. The cursor advances past each denunciation
+// once the handler accepted it, so a restarted Run continues with the
+// denunciation the handler failed on.
+func (m *DenunciationMonitor) Run(ctx context.Context, fn DenunciationHandler) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		list, err := m.fetch(ctx)
+		for _, v := range list {
+			if v.Id == m.failed || m.throttle == nil || m.throttle.Allow(v.Type.String(), v.Offender.String()) {
+				if err := fn(v); err != nil {
+					m.failed = v.Id
+					return err
+				}
+			}
+			m.cursor = v.Id
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
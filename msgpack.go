@@ -0,0 +1,326 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+)
+
+// Table queries can negotiate MessagePack encoded responses. Rows are sent
+// as arrays of values in the same layout as brief JSON rows. Op and block
+// rows are decoded from native values directly which avoids formatting and
+// parsing numbers, other lists receive rows converted to brief JSON. Servers which don't
+// support MessagePack answer with JSON as before.
+//
+//	c.UseMsgpack(true)
+//	ops, err := c.NewOpQuery().Run(ctx)
+const (
+	msgpackAccept    = "application/msgpack, application/json;q=0.9"
+	msgpackMaxDepth  = 64
+	msgpackMaxLength = 1 << 26
+)
+
+// UseMsgpack enables or disables MessagePack encoded table responses.
+func (c *Client) UseMsgpack(enable bool) {
+	c.msgpack = enable
+}
+
+func isMsgpack(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return true
+	}
+	return false
+}
+
+// valueDecoder is implemented by lists which decode rows of values without
+// a JSON round trip.
+type valueDecoder interface {
+	decodeValues(row []interface{}) error
+}
+
+// contentTypeSetter receives the response content type before the body is
+// decoded.
+type contentTypeSetter interface {
+	setContentType(string)
+}
+
+// msgpackResult decodes a negotiated table response into a list.
+type msgpackResult struct {
+	contentType string
+	result      interface{}
+}
+
+func newMsgpackResult(result interface{}) *msgpackResult {
+	return &msgpackResult{result: result}
+}
+
+func (r *msgpackResult) setContentType(s string) {
+	r.contentType = s
+}
+
+func (r *msgpackResult) decodeStream(rd io.Reader) error {
+	if !isMsgpack(r.contentType) {
+		if dec, ok := r.result.(streamDecoder); ok {
+			return dec.decodeStream(rd)
+		}
+		return json.NewDecoder(rd).Decode(r.result)
+	}
+	var fn func([]interface{}) error
+	done := func() error { return nil }
+	if dec, ok := r.result.(valueDecoder); ok {
+		fn = dec.decodeValues
+	} else {
+		var add func(json.RawMessage) error
+		add, done = rowSink(r.result)
+		fn = func(row []interface{}) error {
+			buf, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			return add(buf)
+		}
+	}
+	if err := decodeMsgpackRows(rd, fn); err != nil {
+		return err
+	}
+	return done()
+}
+
+// decodeMsgpackRows reads an array of row arrays and calls fn for each row.
+// Integers become int64 or uint64, floats become float64 and bools become
+// 0 or 1 like in brief JSON rows. Binary values become hex strings.
+func decodeMsgpackRows(r io.Reader, fn func([]interface{}) error) error {
+	mr := &msgpackReader{r: bufio.NewReader(r)}
+	b, err := mr.r.ReadByte()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if b == 0xc0 {
+		return nil
+	}
+	n, ok, err := mr.arrayLen(b)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("msgpack: expected array of rows, got type 0x%02x", b)
+	}
+	for i := 0; i < n; i++ {
+		v, err := mr.value(1)
+		if err != nil {
+			return err
+		}
+		row, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("msgpack: row %d is not an array", i)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type msgpackReader struct {
+	r   *bufio.Reader
+	buf [8]byte
+}
+
+func (m *msgpackReader) uint(n int) (uint64, error) {
+	if _, err := io.ReadFull(m.r, m.buf[:n]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	switch n {
+	case 1:
+		return uint64(m.buf[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(m.buf[:2])), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(m.buf[:4])), nil
+	default:
+		return binary.BigEndian.Uint64(m.buf[:8]), nil
+	}
+}
+
+func (m *msgpackReader) length(n int) (int, error) {
+	l, err := m.uint(n)
+	if err != nil {
+		return 0, err
+	}
+	if l > msgpackMaxLength {
+		return 0, fmt.Errorf("msgpack: length %d exceeds limit", l)
+	}
+	return int(l), nil
+}
+
+func (m *msgpackReader) bytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(m.r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+}
+
+// arrayLen returns the length of an array with type byte b.
+func (m *msgpackReader) arrayLen(b byte) (int, bool, error) {
+	var (
+		n   int
+		err error
+	)
+	switch {
+	case b&0xf0 == 0x90:
+		n = int(b & 0x0f)
+	case b == 0xdc:
+		n, err = m.length(2)
+	case b == 0xdd:
+		n, err = m.length(4)
+	default:
+		return 0, false, nil
+	}
+	return n, true, err
+}
+
+func (m *msgpackReader) value(depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, fmt.Errorf("msgpack: nesting exceeds %d levels", msgpackMaxDepth)
+	}
+	b, err := m.r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if n, ok, err := m.arrayLen(b); ok {
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]interface{}, 0, min(n, 64))
+		for i := 0; i < n; i++ {
+			v, err := m.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return m.mapValue(int(b&0x0f), depth)
+	case b&0xe0 == 0xa0:
+		return m.str(int(b & 0x1f))
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return int64(0), nil
+	case 0xc3:
+		return int64(1), nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := m.length(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		buf, err := m.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(buf), nil
+	case 0xca:
+		v, err := m.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return checkFloat(float64(math.Float32frombits(uint32(v))))
+	case 0xcb:
+		v, err := m.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return checkFloat(math.Float64frombits(v))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := m.uint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (b - 0xd0)
+		v, err := m.uint(n)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend
+		shift := uint(64 - 8*n)
+		return int64(v<<shift) >> shift, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := m.length(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return m.str(n)
+	case 0xde, 0xdf:
+		n, err := m.length(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return m.mapValue(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", b)
+}
+
+func (m *msgpackReader) str(n int) (string, error) {
+	buf, err := m.bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (m *msgpackReader) mapValue(n, depth int) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, min(n, 64))
+	for i := 0; i < n; i++ {
+		k, err := m.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key of type %T", k)
+		}
+		if res[key], err = m.value(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func checkFloat(f float64) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("msgpack: invalid number %v", f)
+	}
+	return f, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
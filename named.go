@@ -0,0 +1,226 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NamedQuery is a reusable table query definition. Filter and param values
+// starting with '$' are placeholders which are replaced by run time
+// arguments, e.g. {Mode: "gte", Column: "height", Value: "$from"}.
+type NamedQuery struct {
+	Name    string            `json:"name"`
+	Table   string            `json:"table"`
+	Columns []string          `json:"columns,omitempty"`
+	Filter  []NamedFilter     `json:"filter,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Limit   int               `json:"limit,omitempty"`
+	Order   OrderType         `json:"order,omitempty"`
+}
+
+type NamedFilter struct {
+	Mode   FilterMode `json:"mode"`
+	Column string     `json:"column"`
+	Value  string     `json:"value"`
+}
+
+// QueryArgs are placeholder values for a named query, keyed by name
+// without the '$' prefix. Values are converted with ToString.
+type QueryArgs map[string]interface{}
+
+func (q NamedQuery) Check() error {
+	if q.Name == "" {
+		return fmt.Errorf("named query: empty name")
+	}
+	if q.Table == "" {
+		return fmt.Errorf("named query %s: empty table name", q.Name)
+	}
+	if len(q.Columns) == 0 {
+		return fmt.Errorf("named query %s: no columns", q.Name)
+	}
+	for _, f := range q.Filter {
+		if f.Column == "" || f.Mode == "" {
+			return fmt.Errorf("named query %s: invalid filter %q", q.Name, f.Column)
+		}
+	}
+	switch q.Order {
+	case "", OrderAsc, OrderDesc:
+	default:
+		return fmt.Errorf("named query %s: invalid order %q", q.Name, q.Order)
+	}
+	return nil
+}
+
+// Placeholders returns the sorted names of all placeholders used.
+func (q NamedQuery) Placeholders() []string {
+	seen := make(map[string]bool)
+	for _, f := range q.Filter {
+		if name, ok := placeholder(f.Value); ok {
+			seen[name] = true
+		}
+	}
+	for _, v := range q.Params {
+		if name, ok := placeholder(v); ok {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func placeholder(s string) (string, bool) {
+	if len(s) < 2 || s[0] != '$' {
+		return "", false
+	}
+	return s[1:], true
+}
+
+func (q NamedQuery) bind(s string, args QueryArgs) (string, error) {
+	name, ok := placeholder(s)
+	if !ok {
+		return s, nil
+	}
+	v, ok := args[name]
+	if !ok {
+		return "", fmt.Errorf("named query %s: missing argument %q", q.Name, name)
+	}
+	return ToString(v), nil
+}
+
+// QueryRegistry holds named queries shared by all users of a client.
+type QueryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]NamedQuery
+}
+
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{
+		queries: make(map[string]NamedQuery),
+	}
+}
+
+// LoadQueryRegistry reads a JSON array of query definitions.
+func LoadQueryRegistry(r io.Reader) (*QueryRegistry, error) {
+	var list []NamedQuery
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("named query: %w", err)
+	}
+	reg := NewQueryRegistry()
+	if err := reg.Register(list...); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Register adds or replaces query definitions. No query is registered
+// when any definition is invalid.
+func (r *QueryRegistry) Register(qs ...NamedQuery) error {
+	for _, q := range qs {
+		if err := q.Check(); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, q := range qs {
+		r.queries[q.Name] = q
+	}
+	return nil
+}
+
+func (r *QueryRegistry) Lookup(name string) (NamedQuery, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	q, ok := r.queries[name]
+	return q, ok
+}
+
+// Names returns the sorted names of all registered queries.
+func (r *QueryRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.queries))
+	for n := range r.queries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Client) UseQueryRegistry(r *QueryRegistry) {
+	c.queries = r
+}
+
+func (c *Client) QueryRegistry() *QueryRegistry {
+	return c.queries
+}
+
+// NewNamedQuery builds a raw query from a registered definition and binds
+// its placeholders to args.
+func (c *Client) NewNamedQuery(name string, args QueryArgs) (RawQuery, error) {
+	def, ok := c.queries.Lookup(name)
+	if !ok {
+		return RawQuery{}, fmt.Errorf("named query %s: %w", name, ErrNotFound)
+	}
+	q := c.NewRawQuery(def.Table)
+	q.Columns = append([]string(nil), def.Columns...)
+	if def.Limit > 0 {
+		q.Limit = def.Limit
+	}
+	if def.Order != "" {
+		q.Order = def.Order
+	}
+	for _, f := range def.Filter {
+		v, err := def.bind(f.Value, args)
+		if err != nil {
+			return RawQuery{}, err
+		}
+		q.Filter.Add(f.Mode, f.Column, v)
+	}
+	for k, v := range def.Params {
+		v, err := def.bind(v, args)
+		if err != nil {
+			return RawQuery{}, err
+		}
+		q.Params = q.Params.With(k, v)
+	}
+	return q, nil
+}
+
+// RunNamed executes a registered query and returns one page of rows.
+func (c *Client) RunNamed(ctx context.Context, name string, args QueryArgs) (*RawList, error) {
+	q, err := c.NewNamedQuery(name, args)
+	if err != nil {
+		return nil, err
+	}
+	return q.Run(ctx)
+}
+
+// String returns the query definition in a compact form for logs.
+func (q NamedQuery) String() string {
+	var b strings.Builder
+	b.WriteString(q.Name)
+	b.WriteString(": ")
+	b.WriteString(q.Table)
+	for _, f := range q.Filter {
+		b.WriteString(" ")
+		b.WriteString(f.Column)
+		b.WriteString(".")
+		b.WriteString(string(f.Mode))
+		b.WriteString("=")
+		b.WriteString(f.Value)
+	}
+	return b.String()
+}
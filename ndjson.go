@@ -0,0 +1,89 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ndjsonWriter converts a JSON table response written to it into newline
+// delimited JSON objects. Rows are converted while the response is read.
+type ndjsonWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+	once sync.Once
+	err  error
+}
+
+func newNDJSONWriter(w io.Writer, cols []string) *ndjsonWriter {
+	pr, pw := io.Pipe()
+	nw := &ndjsonWriter{
+		pw:   pw,
+		done: make(chan error, 1),
+	}
+	go func() {
+		bw := bufio.NewWriter(w)
+		err := decodeRows(pr, "ndjson", func(v json.RawMessage) error {
+			line, err := ndjsonLine(v, cols)
+			if err != nil {
+				return err
+			}
+			if _, err := bw.Write(line); err != nil {
+				return err
+			}
+			return bw.WriteByte('\n')
+		})
+		if err == nil {
+			err = bw.Flush()
+		}
+		// fail pending writes and drain the pipe
+		pr.CloseWithError(err)
+		nw.done <- err
+	}()
+	return nw
+}
+
+func (w *ndjsonWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close waits until all rows are written and returns the first error.
+// Streamed responses are closed by the client, so Close may be called
+// more than once.
+func (w *ndjsonWriter) Close() error {
+	w.once.Do(func() {
+		w.pw.Close()
+		w.err = <-w.done
+	})
+	return w.err
+}
+
+// ndjsonLine turns a brief row into an object keyed by cols. Rows which
+// don't match cols are kept as array.
+func ndjsonLine(row json.RawMessage, cols []string) ([]byte, error) {
+	if len(cols) == 0 {
+		return row, nil
+	}
+	var vals []json.RawMessage
+	if err := json.Unmarshal(row, &vals); err != nil {
+		return nil, err
+	}
+	if len(vals) != len(cols) {
+		return row, nil
+	}
+	buf := make([]byte, 0, len(row)+len(cols)*16)
+	buf = append(buf, '{')
+	for i, v := range vals {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, cols[i])
+		buf = append(buf, ':')
+		buf = append(buf, v...)
+	}
+	return append(buf, '}'), nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// Network is a preset of API URL and expected chain id.
+type Network struct {
+	Name    string
+	Url     string
+	ChainId tezos.ChainIdHash
+}
+
+// Networks contains known public networks. Add entries to register custom
+// networks for NewClientForNetwork.
+var Networks = map[string]Network{
+	"mainnet":    {"mainnet", "https://api.tzstats.com", tezos.Mainnet},
+	"ghostnet":   {"ghostnet", "https://api.ghost.tzstats.com", tezos.Ghostnet},
+	"jakartanet": {"jakartanet", "https://api.jakarta.tzstats.com", tezos.Jakartanet},
+}
+
+// ErrChainMismatch is returned when an API serves a different chain than
+// expected.
+type ErrChainMismatch struct {
+	Network  string
+	Url      string
+	Expected tezos.ChainIdHash
+	Actual   string
+}
+
+func (e ErrChainMismatch) Error() string {
+	return fmt.Sprintf("chain id mismatch for %s at %s: expected %s, got %s", e.Network, e.Url, e.Expected, e.Actual)
+}
+
+func IsErrChainMismatch(err error) (ErrChainMismatch, bool) {
+	e, ok := err.(ErrChainMismatch)
+	return e, ok
+}
+
+// NewClientForNetwork creates a client for a known network and verifies
+// the API serves the expected chain, so testnet and mainnet data are
+// never mixed by accident.
+func NewClientForNetwork(ctx context.Context, name string, httpClient *http.Client) (*Client, error) {
+	n, ok := Networks[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q", name)
+	}
+	c, err := NewClient(n.Url, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.VerifyChainId(ctx, n.ChainId); err != nil {
+		if e, ok := IsErrChainMismatch(err); ok {
+			e.Network = n.Name
+			return nil, e
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// VerifyChainId checks the chain id reported by the API config.
+func (c *Client) VerifyChainId(ctx context.Context, id tezos.ChainIdHash) error {
+	config, err := c.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.ChainId != id.String() {
+		return ErrChainMismatch{
+			Url:      c.params.Server,
+			Expected: id,
+			Actual:   config.ChainId,
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -18,59 +19,62 @@ import (
 )
 
 type Op struct {
-	Id            uint64              `json:"id"`
-	Hash          tezos.OpHash        `json:"hash"`
-	Type          OpType              `json:"type"`
-	Block         tezos.BlockHash     `json:"block"`
-	Timestamp     time.Time           `json:"time"`
-	Height        int64               `json:"height"`
-	Cycle         int64               `json:"cycle"`
-	Counter       int64               `json:"counter"`
-	OpN           int                 `json:"op_n"`
-	OpP           int                 `json:"op_p"`
-	Status        tezos.OpStatus      `json:"status"`
-	IsSuccess     bool                `json:"is_success"`
-	IsContract    bool                `json:"is_contract"`
-	IsBatch       bool                `json:"is_batch,omitempty"`
-	IsEvent       bool                `json:"is_event"`
-	IsInternal    bool                `json:"is_internal"`
-	GasLimit      int64               `json:"gas_limit"`
-	GasUsed       int64               `json:"gas_used"`
-	StorageLimit  int64               `json:"storage_limit"`
-	StoragePaid   int64               `json:"storage_paid"`
-	Volume        float64             `json:"volume"`
-	Fee           float64             `json:"fee"`
-	Reward        float64             `json:"reward"`
-	Deposit       float64             `json:"deposit"`
-	Burned        float64             `json:"burned"`
-	TDD           float64             `json:"days_destroyed"`
-	SenderId      uint64              `json:"sender_id"`
-	ReceiverId    uint64              `json:"receiver_id"`
-	CreatorId     uint64              `json:"creator_id"`
-	BakerId       uint64              `json:"baker_id"`
-	Sender        tezos.Address       `json:"sender"`
-	Receiver      tezos.Address       `json:"receiver"`
-	Creator       tezos.Address       `json:"creator"`                // origination
-	Baker         tezos.Address       `json:"baker"`                  // delegation, origination
-	PrevBaker     tezos.Address       `json:"previous_baker,notable"` // delegation
-	Source        tezos.Address       `json:"source,notable"`         // internal operations
-	Offender      tezos.Address       `json:"offender,notable"`       // double_x
-	Accuser       tezos.Address       `json:"accuser,notable"`        // double_x
-	Data          json.RawMessage     `json:"data,omitempty"`
-	Errors        json.RawMessage     `json:"errors,omitempty"`
-	Parameters    *ContractParameters `json:"parameters,omitempty"`   // transaction
-	Storage       *ContractValue      `json:"storage,omitempty"`      // transaction, origination
-	BigmapDiff    []BigmapUpdate      `json:"big_map_diff,omitempty"` // transaction, origination
-	Value         micheline.Prim      `json:"value,omitempty"`        // register_constant
-	Power         int                 `json:"power,omitempty"`        // endorsement
-	Limit         *float64            `json:"limit,omitempty"`        // set deposits limit
-	Confirmations int64               `json:"confirmations,notable"`
-	BatchVolume   float64             `json:"batch_volume,omitempty,notable"`
-	Entrypoint    string              `json:"entrypoint,omitempty,notable"`
-	NOps          int                 `json:"n_ops,omitempty,notable"`
-	Batch         []*Op               `json:"batch,omitempty,notable"`
-	Internal      []*Op               `json:"internal,omitempty,notable"`
-	Metadata      map[string]Metadata `json:"metadata,omitempty,notable"`
+	Id            uint64                     `json:"id" preset:"light,fees"`
+	Hash          tezos.OpHash               `json:"hash" preset:"light,fees"`
+	Type          OpType                     `json:"type" preset:"light,fees"`
+	Block         tezos.BlockHash            `json:"block"`
+	Timestamp     time.Time                  `json:"time" preset:"light,fees"`
+	Height        int64                      `json:"height" preset:"light,fees"`
+	Cycle         int64                      `json:"cycle"`
+	Counter       int64                      `json:"counter"`
+	OpN           int                        `json:"op_n"`
+	OpP           int                        `json:"op_p"`
+	OpC           int                        `json:"op_c,notable"` // content position in the operation
+	OpI           int                        `json:"op_i,notable"` // internal operation position in the content
+	Status        tezos.OpStatus             `json:"status" preset:"light"`
+	IsSuccess     bool                       `json:"is_success"`
+	IsContract    bool                       `json:"is_contract"`
+	IsBatch       bool                       `json:"is_batch,omitempty"`
+	IsEvent       bool                       `json:"is_event"`
+	IsInternal    bool                       `json:"is_internal"`
+	GasLimit      int64                      `json:"gas_limit" preset:"fees"`
+	GasUsed       int64                      `json:"gas_used" preset:"fees"`
+	StorageLimit  int64                      `json:"storage_limit" preset:"fees"`
+	StoragePaid   int64                      `json:"storage_paid" preset:"fees"`
+	Volume        float64                    `json:"volume" preset:"light"`
+	Fee           float64                    `json:"fee" preset:"light,fees"`
+	Reward        float64                    `json:"reward"`
+	Deposit       float64                    `json:"deposit"`
+	Burned        float64                    `json:"burned" preset:"fees"`
+	TDD           float64                    `json:"days_destroyed"`
+	SenderId      uint64                     `json:"sender_id"`
+	ReceiverId    uint64                     `json:"receiver_id"`
+	CreatorId     uint64                     `json:"creator_id"`
+	BakerId       uint64                     `json:"baker_id"`
+	Sender        tezos.Address              `json:"sender" preset:"light,fees"`
+	Receiver      tezos.Address              `json:"receiver" preset:"light"`
+	Creator       tezos.Address              `json:"creator"`                // origination
+	Baker         tezos.Address              `json:"baker"`                  // delegation, origination
+	PrevBaker     tezos.Address              `json:"previous_baker,notable"` // delegation
+	Source        tezos.Address              `json:"source,notable"`         // internal operations
+	Offender      tezos.Address              `json:"offender,notable"`       // double_x
+	Accuser       tezos.Address              `json:"accuser,notable"`        // double_x
+	Data          json.RawMessage            `json:"data,omitempty"`
+	Errors        json.RawMessage            `json:"errors,omitempty"`
+	Parameters    *ContractParameters        `json:"parameters,omitempty"`   // transaction
+	Storage       *ContractValue             `json:"storage,omitempty"`      // transaction, origination
+	BigmapDiff    []BigmapUpdate             `json:"big_map_diff,omitempty"` // transaction, origination
+	Value         micheline.Prim             `json:"value,omitempty"`        // register_constant
+	Power         int                        `json:"power,omitempty"`        // endorsement
+	Limit         *float64                   `json:"limit,omitempty"`        // set deposits limit
+	Confirmations int64                      `json:"confirmations,notable"`
+	BatchVolume   float64                    `json:"batch_volume,omitempty,notable"`
+	Entrypoint    string                     `json:"entrypoint,omitempty,notable" brief:"-"`
+	NOps          int                        `json:"n_ops,omitempty,notable"`
+	Batch         []*Op                      `json:"batch,omitempty,notable"`
+	Internal      []*Op                      `json:"internal,omitempty,notable"`
+	Metadata      map[string]Metadata        `json:"metadata,omitempty,notable"`
+	Extra         map[string]json.RawMessage `json:"-"` // fields unknown to this SDK version
 
 	columns  []string                 // optional, for decoding bulk arrays
 	param    micheline.Type           // optional, may be decoded from script
@@ -164,6 +168,7 @@ type OpList struct {
 	columns  []string
 	ctx      context.Context
 	client   *Client
+	onRow    func(*Op) error // optional, rows are not retained when set
 }
 
 func (l OpList) Len() int {
@@ -185,38 +190,97 @@ func (l *OpList) UnmarshalJSON(data []byte) error {
 	if data[0] != '[' {
 		return fmt.Errorf("OpList: expected JSON array")
 	}
-	array := make([]json.RawMessage, 0)
-	if err := json.Unmarshal(data, &array); err != nil {
+	return l.decodeStream(bytes.NewReader(data))
+}
+
+func (l *OpList) decodeStream(r io.Reader) error {
+	return decodeRows(r, "OpList", l.decodeRow)
+}
+
+func (l *OpList) decodeRow(v json.RawMessage) error {
+	op, err := l.client.decodeOpRow(l.ctx, l.columns, l.withPrim, v)
+	if err != nil {
 		return err
 	}
-	for _, v := range array {
-		op := &Op{
-			withPrim: l.withPrim,
-			columns:  l.columns,
-		}
-		// we may need contract scripts
-		if is, ok := getTableColumn(v, l.columns, "is_contract"); ok && is == "1" {
-			recv, ok := getTableColumn(v, l.columns, "receiver")
-			if ok && recv != "" && recv != "null" {
-				addr, err := tezos.ParseAddress(recv)
-				if err != nil {
-					return fmt.Errorf("decode: invalid receiver address %s: %v", recv, err)
-				}
-				// load contract type info (required for decoding storage/param data)
-				script, err := l.client.loadCachedContractScript(l.ctx, addr)
-				if err != nil {
-					return err
-				}
-				op = op.WithScript(script)
-			}
+	return l.addRow(op)
+}
+
+func (l *OpList) decodeValues(row []interface{}) error {
+	op, err := l.client.decodeOpValues(l.ctx, l.columns, l.withPrim, row)
+	if err != nil {
+		return err
+	}
+	return l.addRow(op)
+}
+
+func (l *OpList) addRow(op *Op) error {
+	if l.onRow != nil {
+		return l.onRow(op)
+	}
+	l.Rows = append(l.Rows, op)
+	return nil
+}
+
+// decodeOpRow decodes a single op table row and loads contract scripts
+// required to decode parameters and storage.
+func (c *Client) decodeOpRow(ctx context.Context, cols []string, withPrim bool, v []byte) (*Op, error) {
+	is, _ := getTableColumn(v, cols, "is_contract")
+	recv, _ := getTableColumn(v, cols, "receiver")
+	op, err := c.newOpRow(ctx, cols, withPrim, is == "1", recv)
+	if err != nil {
+		return nil, err
+	}
+	if err := op.UnmarshalJSON(v); err != nil {
+		return nil, err
+	}
+	op.columns = nil
+	return op, nil
+}
+
+// decodeOpValues is like decodeOpRow for rows of decoded values.
+func (c *Client) decodeOpValues(ctx context.Context, cols []string, withPrim bool, row []interface{}) (*Op, error) {
+	var (
+		is   bool
+		recv string
+	)
+	if i := colIndex(cols, "is_contract"); i >= 0 && i < len(row) {
+		is, _ = briefBool(row[i])
+	}
+	if i := colIndex(cols, "receiver"); i >= 0 && i < len(row) {
+		recv = jsonString(row[i])
+	}
+	op, err := c.newOpRow(ctx, cols, withPrim, is, recv)
+	if err != nil {
+		return nil, err
+	}
+	if err := op.decodeBriefValues(row); err != nil {
+		return nil, err
+	}
+	op.columns = nil
+	return op, nil
+}
+
+// newOpRow prepares an op for decoding and loads the receiver's script
+// for contract calls.
+func (c *Client) newOpRow(ctx context.Context, cols []string, withPrim, isContract bool, recv string) (*Op, error) {
+	op := &Op{
+		withPrim: withPrim,
+		columns:  cols,
+	}
+	// we may need contract scripts
+	if isContract && recv != "" && recv != "null" {
+		addr, err := tezos.ParseAddress(recv)
+		if err != nil {
+			return nil, fmt.Errorf("decode: invalid receiver address %s: %v", recv, err)
 		}
-		if err := op.UnmarshalJSON(v); err != nil {
-			return err
+		// load contract type info (required for decoding storage/param data)
+		script, err := c.loadCachedContractScript(ctx, addr)
+		if err != nil {
+			return nil, err
 		}
-		op.columns = nil
-		l.Rows = append(l.Rows, op)
+		op = op.WithScript(script)
 	}
-	return nil
+	return op, nil
 }
 
 func (o *Op) UnmarshalJSON(data []byte) error {
@@ -229,220 +293,176 @@ func (o *Op) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return o.UnmarshalJSONBrief(data)
 	}
-	type Alias *Op
-	return json.Unmarshal(data, Alias(o))
+	type Alias Op
+	if err := json.Unmarshal(data, (*Alias)(o)); err != nil {
+		return err
+	}
+	o.Extra = extraFields(data, opModelType)
+	return nil
 }
 
 func (o *Op) UnmarshalJSONBrief(data []byte) error {
-	op := Op{}
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
 	unpacked := make([]interface{}, 0)
-	err := dec.Decode(&unpacked)
-	if err != nil {
+	if err := dec.Decode(&unpacked); err != nil {
 		return err
 	}
+	return o.decodeBriefValues(unpacked)
+}
+
+// decodeBriefValues decodes a brief row from JSON values as produced by
+// json.Decoder.UseNumber.
+func (o *Op) decodeBriefValues(unpacked []interface{}) error {
+	if err := checkRowLen(unpacked, o.columns); err != nil {
+		return err
+	}
+	var (
+		op  Op
+		err error
+	)
 	for i, v := range o.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "id":
-			op.Id, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "hash":
-			op.Hash, err = tezos.ParseOpHash(f.(string))
-		case "type":
-			op.Type = ParseOpType(f.(string))
-		case "block":
-			op.Block, err = tezos.ParseBlockHash(f.(string))
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				op.Timestamp = time.Unix(0, ts*1000000).UTC()
+		if ok, err := op.decodeBriefColumn(v, f); ok {
+			if err != nil {
+				return err
 			}
-		case "height":
-			op.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "cycle":
-			op.Cycle, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "counter":
-			op.Counter, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "op_n":
-			op.OpN, err = strconv.Atoi(f.(json.Number).String())
-		case "op_p":
-			op.OpP, err = strconv.Atoi(f.(json.Number).String())
-		case "status":
-			op.Status = tezos.ParseOpStatus(f.(string))
-		case "is_success":
-			op.IsSuccess, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_contract":
-			op.IsContract, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_batch":
-			op.IsBatch, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_event":
-			op.IsEvent, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_internal":
-			op.IsInternal, err = strconv.ParseBool(f.(json.Number).String())
-		case "gas_limit":
-			op.GasLimit, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "gas_used":
-			op.GasUsed, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "storage_limit":
-			op.StorageLimit, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "storage_paid":
-			op.StoragePaid, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "volume":
-			op.Volume, err = f.(json.Number).Float64()
-		case "fee":
-			op.Fee, err = f.(json.Number).Float64()
-		case "reward":
-			op.Reward, err = f.(json.Number).Float64()
-		case "deposit":
-			op.Deposit, err = f.(json.Number).Float64()
-		case "burned":
-			op.Burned, err = f.(json.Number).Float64()
-		case "days_destroyed":
-			op.TDD, err = f.(json.Number).Float64()
-		case "sender_id":
-			op.SenderId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "receiver_id":
-			op.ReceiverId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "creator_id":
-			op.CreatorId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "baker_id":
-			op.BakerId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "sender":
-			op.Sender, err = tezos.ParseAddress(f.(string))
-		case "receiver":
-			op.Receiver, err = tezos.ParseAddress(f.(string))
-		case "creator":
-			op.Creator, err = tezos.ParseAddress(f.(string))
-		case "baker":
-			op.Baker, err = tezos.ParseAddress(f.(string))
-		case "data":
-			op.Data, err = json.Marshal(f)
-		case "errors":
-			op.Errors, err = json.Marshal(f)
+			continue
+		}
+		switch v {
 		case "entrypoint":
 			if op.Parameters == nil {
 				op.Parameters = &ContractParameters{}
 			}
-			op.Parameters.Entrypoint = f.(string)
-			op.Entrypoint = f.(string)
+			op.Parameters.Entrypoint = jsonString(f)
+			op.Entrypoint = jsonString(f)
 		case "parameters":
-			var buf []byte
-			if buf, err = hex.DecodeString(f.(string)); err == nil && len(buf) > 0 {
-				params := &micheline.Parameters{}
-				err = params.UnmarshalBinary(buf)
-				if err == nil {
-					op.Parameters = &ContractParameters{
-						Entrypoint: params.Entrypoint,
-					}
-					ep, prim, _ := params.MapEntrypoint(o.param)
-					if o.withPrim {
-						op.Parameters.ContractValue.Prim = &prim
-					}
-					val := micheline.NewValue(ep.Type(), prim)
-					val.Render = o.onError
-					op.Parameters.ContractValue.Value, err = val.Map()
-					if err != nil {
-						err = fmt.Errorf("decoding params %s: %w", f.(string), err)
-					}
-				}
-			}
-		case "storage":
-			var buf []byte
-			if buf, err = hex.DecodeString(f.(string)); err == nil && len(buf) > 0 {
-				prim := micheline.Prim{}
-				err = prim.UnmarshalBinary(buf)
-				if err == nil {
-					op.Storage = &ContractValue{}
-					if o.withPrim {
-						op.Storage.Prim = &prim
-					}
-					if o.store.IsValid() {
-						val := micheline.NewValue(o.store, prim)
+			err = safeDecode("parameters", func() (err error) {
+				var buf []byte
+				if buf, err = hex.DecodeString(jsonString(f)); err == nil && len(buf) > 0 {
+					params := &micheline.Parameters{}
+					err = params.UnmarshalBinary(buf)
+					if err == nil {
+						op.Parameters = &ContractParameters{
+							Entrypoint: params.Entrypoint,
+						}
+						ep, prim, _ := params.MapEntrypoint(o.param)
+						if o.withPrim {
+							op.Parameters.ContractValue.Prim = &prim
+						}
+						val := micheline.NewValue(ep.Type(), prim)
 						val.Render = o.onError
-						op.Storage.Value, err = val.Map()
+						op.Parameters.ContractValue.Value, err = val.Map()
 						if err != nil {
-							err = fmt.Errorf("decoding storage %s: %w", f.(string), err)
+							err = fmt.Errorf("decoding params %s: %w", jsonString(f), err)
 						}
 					}
 				}
-			}
-		case "big_map_diff":
-			var buf []byte
-			if buf, err = hex.DecodeString(f.(string)); err == nil && len(buf) > 0 {
-				bmd := make(micheline.BigmapEvents, 0)
-				err = bmd.UnmarshalBinary(buf)
-				if err == nil {
-					op.BigmapDiff = make([]BigmapUpdate, len(bmd))
-					for i, v := range bmd {
-						var ktyp, vtyp micheline.Type
-						if typ, ok := o.bigmaps[v.Id]; ok {
-							ktyp, vtyp = typ.Left(), typ.Right()
-						} else {
-							ktyp = v.Key.BuildType()
+				return
+			})
+		case "storage":
+			err = safeDecode("storage", func() (err error) {
+				var buf []byte
+				if buf, err = hex.DecodeString(jsonString(f)); err == nil && len(buf) > 0 {
+					prim := micheline.Prim{}
+					err = prim.UnmarshalBinary(buf)
+					if err == nil {
+						op.Storage = &ContractValue{}
+						if o.withPrim {
+							op.Storage.Prim = &prim
 						}
-						op.BigmapDiff[i] = BigmapUpdate{
-							Action:   v.Action,
-							BigmapId: v.Id,
+						if o.store.IsValid() {
+							val := micheline.NewValue(o.store, prim)
+							val.Render = o.onError
+							op.Storage.Value, err = val.Map()
+							if err != nil {
+								err = fmt.Errorf("decoding storage %s: %w", jsonString(f), err)
+							}
 						}
-						switch v.Action {
-						case micheline.DiffActionAlloc, micheline.DiffActionCopy:
-							// alloc/copy only
-							op.BigmapDiff[i].KeyType = micheline.Type{Prim: v.KeyType}.TypedefPtr("@key")
-							op.BigmapDiff[i].ValueType = micheline.Type{Prim: v.ValueType}.TypedefPtr("@value")
-							op.BigmapDiff[i].SourceId = v.SourceId
-							op.BigmapDiff[i].DestId = v.DestId
-							if op.withPrim {
-								op.BigmapDiff[i].KeyTypePrim = &v.KeyType
-								op.BigmapDiff[i].ValueTypePrim = &v.ValueType
+					}
+				}
+				return
+			})
+		case "big_map_diff":
+			err = safeDecode("bigmap diff", func() (err error) {
+				var buf []byte
+				if buf, err = hex.DecodeString(jsonString(f)); err == nil && len(buf) > 0 {
+					bmd := make(micheline.BigmapEvents, 0)
+					err = bmd.UnmarshalBinary(buf)
+					if err == nil {
+						op.BigmapDiff = make([]BigmapUpdate, len(bmd))
+						for i, v := range bmd {
+							var ktyp, vtyp micheline.Type
+							if typ, ok := o.bigmaps[v.Id]; ok {
+								ktyp, vtyp = typ.Left(), typ.Right()
+							} else {
+								ktyp = v.Key.BuildType()
 							}
-						default:
-							// update/remove only
-							op.BigmapDiff[i].BigmapValue = BigmapValue{}
-							if !v.Key.IsEmptyBigmap() {
-								keybuf, _ := v.GetKey(ktyp).MarshalJSON()
-								mk := MultiKey{}
-								_ = mk.UnmarshalJSON(keybuf)
-								op.BigmapDiff[i].BigmapValue.Key = mk
-								op.BigmapDiff[i].BigmapValue.Hash = v.KeyHash
+							op.BigmapDiff[i] = BigmapUpdate{
+								Action:   v.Action,
+								BigmapId: v.Id,
 							}
-							if o.withMeta {
-								op.BigmapDiff[i].BigmapValue.Meta = &BigmapMeta{
-									Contract:     op.Receiver,
-									BigmapId:     v.Id,
-									UpdateTime:   op.Timestamp,
-									UpdateHeight: op.Height,
+							switch v.Action {
+							case micheline.DiffActionAlloc, micheline.DiffActionCopy:
+								// alloc/copy only
+								op.BigmapDiff[i].KeyType = micheline.Type{Prim: v.KeyType}.TypedefPtr("@key")
+								op.BigmapDiff[i].ValueType = micheline.Type{Prim: v.ValueType}.TypedefPtr("@value")
+								op.BigmapDiff[i].SourceId = v.SourceId
+								op.BigmapDiff[i].DestId = v.DestId
+								if op.withPrim {
+									op.BigmapDiff[i].KeyTypePrim = &v.KeyType
+									op.BigmapDiff[i].ValueTypePrim = &v.ValueType
+								}
+							default:
+								// update/remove only
+								op.BigmapDiff[i].BigmapValue = BigmapValue{}
+								if !v.Key.IsEmptyBigmap() {
+									keybuf, _ := v.GetKey(ktyp).MarshalJSON()
+									mk := MultiKey{}
+									_ = mk.UnmarshalJSON(keybuf)
+									op.BigmapDiff[i].BigmapValue.Key = mk
+									op.BigmapDiff[i].BigmapValue.Hash = v.KeyHash
+								}
+								if o.withMeta {
+									op.BigmapDiff[i].BigmapValue.Meta = &BigmapMeta{
+										Contract:     op.Receiver,
+										BigmapId:     v.Id,
+										UpdateTime:   op.Timestamp,
+										UpdateHeight: op.Height,
+									}
 								}
-							}
-							if o.withPrim {
-								op.BigmapDiff[i].BigmapValue.KeyPrim = &v.Key
-							}
-							if v.Action == micheline.DiffActionUpdate {
-								// update only
 								if o.withPrim {
-									op.BigmapDiff[i].BigmapValue.ValuePrim = &v.Value
+									op.BigmapDiff[i].BigmapValue.KeyPrim = &v.Key
 								}
-								// unpack value if type is known
-								if vtyp.IsValid() {
-									val := micheline.NewValue(vtyp, v.Value)
-									val.Render = o.onError
-									op.BigmapDiff[i].BigmapValue.Value, err = val.Map()
-									if err != nil {
-										err = fmt.Errorf("decoding bigmap %d/%s: %w", v.Id, v.KeyHash, err)
+								if v.Action == micheline.DiffActionUpdate {
+									// update only
+									if o.withPrim {
+										op.BigmapDiff[i].BigmapValue.ValuePrim = &v.Value
+									}
+									// unpack value if type is known
+									if vtyp.IsValid() {
+										val := micheline.NewValue(vtyp, v.Value)
+										val.Render = o.onError
+										op.BigmapDiff[i].BigmapValue.Value, err = val.Map()
+										if err != nil {
+											err = fmt.Errorf("decoding bigmap %d/%s: %w", v.Id, v.KeyHash, err)
+										}
 									}
 								}
 							}
-						}
-						if err != nil {
-							break
+							if err != nil {
+								break
+							}
 						}
 					}
 				}
-			}
+				return
+			})
+		default:
+			op.Extra = addExtra(op.Extra, v, f)
 		}
 		if err != nil {
 			return err
@@ -474,6 +494,87 @@ func (c *Client) NewOpQuery() OpQuery {
 	return OpQuery{q}
 }
 
+// RunFunc runs the query and calls fn for each row while the response is
+// read. Rows are not retained, so pages of any size decode with bounded
+// memory. With an address resolver rows are resolved in chunks of
+// DefaultResolverChunkSize before they are passed to fn. Returns the
+// number of rows fn accepted and the cursor after the last of them, also
+// when fn or the request fails.
+func (q OpQuery) RunFunc(ctx context.Context, fn func(*Op) error) (int, uint64, error) {
+	var (
+		n       int
+		cursor  uint64
+		fnErr   error
+		pending []*Op
+	)
+	deliver := func(o *Op) error {
+		if fnErr = fn(o); fnErr != nil {
+			return fnErr
+		}
+		n++
+		cursor = o.Id
+		return nil
+	}
+	r := q.client.resolver
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := r.ResolveOps(ctx, pending); err != nil {
+			return err
+		}
+		for _, o := range pending {
+			if err := deliver(o); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+	result := &OpList{
+		columns:  q.Columns,
+		ctx:      ctx,
+		client:   q.client,
+		withPrim: q.Prim,
+		onRow: func(o *Op) error {
+			if r == nil {
+				return deliver(o)
+			}
+			pending = append(pending, o)
+			if len(pending) < DefaultResolverChunkSize {
+				return nil
+			}
+			return flush()
+		},
+	}
+	// merging filter expression branches needs all rows
+	if bs, err := q.filterBranches(); err == nil && len(bs) > 1 {
+		l, err := q.Run(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return n, cursor, err
+			}
+			n++
+			cursor = v.Id
+		}
+		return n, cursor, nil
+	}
+	err := q.client.QueryTable(ctx, &q.tableQuery, result)
+	if fnErr == nil {
+		// rows decoded before a request error are still delivered
+		if ferr := flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if fnErr != nil {
+		return n, cursor, fnErr
+	}
+	return n, cursor, err
+}
+
 func (q OpQuery) Run(ctx context.Context) (*OpList, error) {
 	result := &OpList{
 		columns:  q.Columns,
@@ -484,6 +585,11 @@ func (q OpQuery) Run(ctx context.Context) (*OpList, error) {
 	if err := q.client.QueryTable(ctx, &q.tableQuery, result); err != nil {
 		return nil, err
 	}
+	if r := q.client.resolver; r != nil {
+		if err := r.ResolveOps(ctx, result.Rows); err != nil {
+			return nil, err
+		}
+	}
 	return result, nil
 }
 
@@ -500,78 +606,89 @@ func (c *Client) QueryOps(ctx context.Context, filter FilterList, cols []string)
 
 type OpParams struct {
 	Params
+	dedup bool // client-side only
 }
 
 func NewOpParams() OpParams {
-	return OpParams{NewParams()}
+	return OpParams{Params: NewParams()}
 }
 
 func (p OpParams) WithLimit(v uint) OpParams {
-	p.Query.Set("limit", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("limit", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p OpParams) WithOffset(v uint) OpParams {
-	p.Query.Set("offset", strconv.Itoa(int(v)))
+	p.Params = p.Params.With("offset", strconv.Itoa(int(v)))
 	return p
 }
 
 func (p OpParams) WithCursor(v uint64) OpParams {
-	p.Query.Set("cursor", strconv.FormatUint(v, 10))
+	p.Params = p.Params.With("cursor", strconv.FormatUint(v, 10))
 	return p
 }
 
 func (p OpParams) WithOrder(v OrderType) OpParams {
-	p.Query.Set("order", string(v))
+	p.Params = p.Params.With("order", string(v))
 	return p
 }
 
 func (p OpParams) WithType(mode FilterMode, typs ...string) OpParams {
 	if mode != "" {
-		p.Query.Set("type."+string(mode), strings.Join(typs, ","))
+		p.Params = p.Params.With("type."+string(mode), strings.Join(typs, ","))
 	} else {
-		p.Query.Del("type")
+		p.Params = p.Params.Without("type")
 	}
 	return p
 }
 
 func (p OpParams) WithBlock(v string) OpParams {
-	p.Query.Set("block", v)
+	p.Params = p.Params.With("block", v)
 	return p
 }
 
 func (p OpParams) WithSince(v string) OpParams {
-	p.Query.Set("since", v)
+	p.Params = p.Params.With("since", v)
 	return p
 }
 
 func (p OpParams) WithUnpack() OpParams {
-	p.Query.Set("unpack", "1")
+	p.Params = p.Params.With("unpack", "1")
 	return p
 }
 
 func (p OpParams) WithPrim() OpParams {
-	p.Query.Set("prim", "1")
+	p.Params = p.Params.With("prim", "1")
 	return p
 }
 
 func (p OpParams) WithMeta() OpParams {
-	p.Query.Set("meta", "1")
+	p.Params = p.Params.With("meta", "1")
 	return p
 }
 
 func (p OpParams) WithRights() OpParams {
-	p.Query.Set("rights", "1")
+	p.Params = p.Params.With("rights", "1")
 	return p
 }
 
 func (p OpParams) WithMerge() OpParams {
-	p.Query.Set("merge", "1")
+	p.Params = p.Params.With("merge", "1")
 	return p
 }
 
 func (p OpParams) WithStorage() OpParams {
-	p.Query.Set("storage", "1")
+	p.Params = p.Params.With("storage", "1")
+	return p
+}
+
+// WithDeduplicate removes operations from the results of GetOp,
+// GetBlockOps, GetAccountOps and the baker op lists which are also
+// contained in a batch or internal list of another result. See
+// Deduplicate. Table queries like OpQuery return one flat row per content
+// and internal operation and use WithDedup to drop repeated rows instead.
+func (p OpParams) WithDeduplicate() OpParams {
+	p.dedup = true
 	return p
 }
 
@@ -581,5 +698,79 @@ func (c *Client) GetOp(ctx context.Context, hash tezos.OpHash, params OpParams)
 	if err := c.get(ctx, u, nil, &o); err != nil {
 		return nil, err
 	}
+	if params.dedup {
+		o = Deduplicate(o)
+	}
 	return o, nil
 }
+
+// FindOpInBlock looks up all rows of an operation in a block, including
+// batch contents and internal operations, with their position in op_n and
+// op_p. The op table is filtered by hash and block, so rows of the same
+// operation in other blocks, e.g. after a reorg, are not loaded.
+func (c *Client) FindOpInBlock(ctx context.Context, block tezos.BlockHash, hash tezos.OpHash) ([]*Op, error) {
+	q := c.NewOpQuery()
+	q.WithFilter(FilterModeEqual, "hash", hash).
+		WithFilter(FilterModeEqual, "block", block)
+	ops, err := q.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Op, 0, ops.Len())
+	for _, o := range ops.Rows {
+		if o.Block.Equal(block) {
+			res = append(res, o)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("op %s in block %s: %w", hash, block, ErrNotFound)
+	}
+	return res, nil
+}
+
+// Deduplicate removes operations which appear both on their own and as
+// content of a batch container or internal operation list, as may happen
+// when listing with merge enabled. Order is preserved and ops is not
+// modified.
+func Deduplicate(ops []*Op) []*Op {
+	nested := make(map[string]struct{})
+	for _, o := range ops {
+		for _, v := range o.Batch {
+			nested[opKey(v)] = struct{}{}
+			for _, vv := range v.Internal {
+				nested[opKey(vv)] = struct{}{}
+			}
+		}
+		for _, v := range o.Internal {
+			nested[opKey(v)] = struct{}{}
+		}
+	}
+	seen := make(map[string]struct{}, len(ops))
+	res := make([]*Op, 0, len(ops))
+	for _, o := range ops {
+		k := opKey(o)
+		if len(o.Batch) > 0 {
+			k = "batch/" + k
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		if len(o.Batch) == 0 && len(o.Internal) == 0 {
+			if _, ok := nested[k]; ok {
+				continue
+			}
+		}
+		res = append(res, o)
+	}
+	return res
+}
+
+// opKey identifies an operation by row id or, when not available, by hash,
+// position and type.
+func opKey(o *Op) string {
+	if o.Id > 0 {
+		return strconv.FormatUint(o.Id, 10)
+	}
+	return fmt.Sprintf("%s/%d/%d/%s/%t", o.Hash, o.OpN, o.OpP, o.Type, o.IsBatch)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// DefaultOpCacheTipInterval limits how often an OpCache checks the indexer
+// for new blocks.
+var DefaultOpCacheTipInterval = 2 * time.Second
+
+// OpCache makes repeated GetOp calls cheap while waiting for confirmations.
+// Polls only reach the API after the indexer has processed a new block.
+// Once an operation is included its rows are kept and only confirmations
+// are updated, so rows must be invalidated when the chain reorganizes,
+// e.g. by registering OnReorg with a Follower:
+//
+//	cache := c.NewOpCache()
+//	follower.OnReorg(cache.OnReorg)
+//	for {
+//		ops, err := cache.GetOp(ctx, hash, tzstats.NewOpParams())
+//		...
+//	}
+type OpCache struct {
+	client   *Client
+	interval time.Duration
+	mu       sync.Mutex
+	entries  map[string]*opCacheEntry
+	height   int64
+	checked  time.Time
+}
+
+type opCacheEntry struct {
+	ops    []*Op
+	height int64 // indexer height at last fetch
+}
+
+func (c *Client) NewOpCache() *OpCache {
+	return &OpCache{
+		client:   c,
+		interval: DefaultOpCacheTipInterval,
+		entries:  make(map[string]*opCacheEntry),
+	}
+}
+
+// WithTipInterval sets the minimum time between indexer height checks.
+func (oc *OpCache) WithTipInterval(d time.Duration) *OpCache {
+	oc.interval = d
+	return oc
+}
+
+// GetOp returns the rows of an operation like Client.GetOp. Operations not
+// yet included return ErrNotFound until the indexer height changes.
+func (oc *OpCache) GetOp(ctx context.Context, hash tezos.OpHash, params OpParams) ([]*Op, error) {
+	height, err := oc.tipHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := params.AppendQuery(hash.String())
+	if params.dedup {
+		key += "#dedup"
+	}
+	oc.mu.Lock()
+	e, ok := oc.entries[key]
+	oc.mu.Unlock()
+	if ok {
+		switch {
+		case len(e.ops) > 0:
+			return confirmedCopy(e.ops, height), nil
+		case e.height == height:
+			return nil, ErrNotFound
+		}
+	}
+	ops, err := oc.client.GetOp(ctx, hash, params)
+	if err != nil && ErrorStatus(err) != 404 {
+		return nil, err
+	}
+	oc.mu.Lock()
+	oc.entries[key] = &opCacheEntry{ops: ops, height: height}
+	oc.mu.Unlock()
+	if len(ops) == 0 {
+		return nil, ErrNotFound
+	}
+	return confirmedCopy(ops, height), nil
+}
+
+// Invalidate removes all cached results for an operation.
+func (oc *OpCache) Invalidate(hash tezos.OpHash) {
+	prefix := hash.String()
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	for k := range oc.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(oc.entries, k)
+		}
+	}
+}
+
+// InvalidateFrom removes operations included at or above height and forces
+// a new height check on the next poll.
+func (oc *OpCache) InvalidateFrom(height int64) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	for k, e := range oc.entries {
+		if len(e.ops) == 0 || e.ops[0].Height >= height {
+			delete(oc.entries, k)
+		}
+	}
+	oc.height = 0
+	oc.checked = time.Time{}
+}
+
+// OnReorg invalidates operations in the orphaned block and above. It can
+// be registered with Follower.OnReorg.
+func (oc *OpCache) OnReorg(b BlockId) {
+	oc.InvalidateFrom(b.Height)
+}
+
+// Reset clears the cache.
+func (oc *OpCache) Reset() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.entries = make(map[string]*opCacheEntry)
+	oc.height = 0
+	oc.checked = time.Time{}
+}
+
+func (oc *OpCache) tipHeight(ctx context.Context) (int64, error) {
+	oc.mu.Lock()
+	if !oc.checked.IsZero() && time.Since(oc.checked) < oc.interval {
+		h := oc.height
+		oc.mu.Unlock()
+		return h, nil
+	}
+	oc.mu.Unlock()
+	s, err := oc.client.GetStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if s.Indexed > oc.height {
+		oc.height = s.Indexed
+	}
+	oc.checked = time.Now()
+	return oc.height, nil
+}
+
+// confirmedCopy returns copies of ops with confirmations updated to height.
+func confirmedCopy(ops []*Op, height int64) []*Op {
+	res := make([]*Op, len(ops))
+	for i, o := range ops {
+		cp := *o
+		if height >= cp.Height {
+			cp.Confirmations = height - cp.Height
+		}
+		res[i] = &cp
+	}
+	return res
+}
@@ -10,16 +10,19 @@ import (
 	"strings"
 )
 
+// Params holds server and query arguments for an API call. Params are
+// immutable: all With* methods on Params and derived types return a new
+// value and never change the receiver. Query arguments are copied on write
+// and shared between values until then, so a base Params value can safely
+// be used from many goroutines and copied cheaply.
 type Params struct {
 	Server string
 	Prefix string
-	Query  url.Values
+	query  url.Values // never modified after construction
 }
 
 func NewParams() Params {
-	return Params{
-		Query: url.Values{},
-	}
+	return Params{}
 }
 
 func (p Params) Check() error {
@@ -29,19 +32,71 @@ func (p Params) Check() error {
 	return nil
 }
 
+// Copy returns p. It is kept for compatibility, Params are immutable and
+// can be copied by assignment.
 func (p Params) Copy() Params {
-	np := NewParams()
-	np.Server = p.Server
-	np.Prefix = p.Prefix
-	for n, v := range p.Query {
-		np.Query[n] = v
+	return p
+}
+
+// With returns a copy of p with query argument key set to val.
+func (p Params) With(key, val string) Params {
+	q := p.Values()
+	q.Set(key, val)
+	p.query = q
+	return p
+}
+
+// WithValues returns a copy of p with all arguments in vals set.
+func (p Params) WithValues(vals url.Values) Params {
+	if len(vals) == 0 {
+		return p
 	}
-	return np
+	q := p.Values()
+	for n, v := range vals {
+		q[n] = append([]string(nil), v...)
+	}
+	p.query = q
+	return p
+}
+
+// Without returns a copy of p with query argument key removed.
+func (p Params) Without(key string) Params {
+	if !p.Has(key) {
+		return p
+	}
+	q := p.Values()
+	q.Del(key)
+	p.query = q
+	return p
+}
+
+// Get returns the first value of query argument key.
+func (p Params) Get(key string) string {
+	return p.query.Get(key)
+}
+
+func (p Params) Has(key string) bool {
+	_, ok := p.query[key]
+	return ok
+}
+
+// Values returns a copy of all query arguments which callers may modify.
+func (p Params) Values() url.Values {
+	q := make(url.Values, len(p.query)+1)
+	for n, v := range p.query {
+		q[n] = append([]string(nil), v...)
+	}
+	return q
+}
+
+// Encode returns query arguments in URL encoded form sorted by key.
+func (p Params) Encode() string {
+	return p.query.Encode()
 }
 
 func (p Params) AppendQuery(path string) string {
-	if len(p.Query) > 0 {
-		return path + "?" + p.Query.Encode()
+	if len(p.query) > 0 {
+		return path + "?" + p.query.Encode()
 	}
 	return path
 }
@@ -57,12 +112,12 @@ func (p Params) Url(actions ...string) string {
 			fields = append(fields, strings.Trim(v, "/"))
 		}
 	}
-	if len(p.Query) == 0 {
+	if len(p.query) == 0 {
 		return strings.Join(fields, "/")
 	}
 	return strings.Join([]string{
 		strings.Join(fields, "/"),
-		p.Query.Encode(),
+		p.query.Encode(),
 	}, "?")
 }
 
@@ -80,7 +135,9 @@ func ParseParams(urlString string) (Params, error) {
 	if err != nil {
 		return p, err
 	}
-	p.Query = u.Query()
+	if q := u.Query(); len(q) > 0 {
+		p.query = q
+	}
 	if u.Scheme == "" {
 		u.Scheme = "https"
 	}
@@ -0,0 +1,145 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestParamsCopyUnchanged(t *testing.T) {
+	base := NewParams().With("limit", "10").With("type", "transaction")
+	want := base.Encode()
+	tests := []struct {
+		name   string
+		mutate func(p Params) Params
+		want   string
+	}{
+		{"copy", func(p Params) Params { return p.Copy().With("limit", "20") }, "limit=20&type=transaction"},
+		{"assign", func(p Params) Params { q := p; return q.With("cursor", "1") }, "cursor=1&limit=10&type=transaction"},
+		{"with", func(p Params) Params { return p.With("type", "delegation") }, "limit=10&type=delegation"},
+		{"without", func(p Params) Params { return p.Without("type") }, "limit=10"},
+		{"values", func(p Params) Params {
+			v := url.Values{"order": {"desc"}}
+			q := p.WithValues(v)
+			v.Set("order", "asc")
+			return q
+		}, "limit=10&order=desc&type=transaction"},
+		{"modify values", func(p Params) Params {
+			v := p.Values()
+			v.Set("limit", "99")
+			v.Del("type")
+			return p
+		}, "limit=10&type=transaction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.mutate(base)
+			if s := base.Encode(); s != want {
+				t.Errorf("original changed: got %q, want %q", s, want)
+			}
+			if s := got.Encode(); s != tt.want {
+				t.Errorf("copy: got %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamsWithCopyOnWrite(t *testing.T) {
+	// each case returns a base value and a func deriving a new value from
+	// it, which returns the derived query
+	type encoder interface{ Encode() string }
+	tests := []struct {
+		name string
+		fn   func() (encoder, func() string)
+		want string
+	}{
+		{
+			name: "op",
+			fn: func() (encoder, func() string) {
+				b := NewOpParams().WithLimit(10)
+				return b, func() string {
+					return b.WithCursor(5).
+						WithType(FilterModeIn, "transaction", "delegation").
+						WithMeta().
+						Encode()
+				}
+			},
+			want: "cursor=5&limit=10&meta=1&type.in=transaction%2Cdelegation",
+		},
+		{
+			name: "block",
+			fn: func() (encoder, func() string) {
+				b := NewBlockParams().WithLimit(10)
+				return b, func() string { return b.WithOrder(OrderDesc).WithRights().Encode() }
+			},
+			want: "limit=10&order=desc&rights=1",
+		},
+		{
+			name: "account",
+			fn: func() (encoder, func() string) {
+				b := NewAccountParams().WithLimit(10)
+				return b, func() string { return b.WithOffset(20).WithMeta().Encode() }
+			},
+			want: "limit=10&meta=1&offset=20",
+		},
+		{
+			name: "contract",
+			fn: func() (encoder, func() string) {
+				b := NewContractParams().WithLimit(10)
+				return b, func() string { return b.WithPrim().WithStorage().Encode() }
+			},
+			want: "limit=10&prim=1&storage=1",
+		},
+		{
+			name: "baker",
+			fn: func() (encoder, func() string) {
+				b := NewBakerParams().WithLimit(10)
+				return b, func() string { return b.WithCursor(7).Encode() }
+			},
+			want: "cursor=7&limit=10",
+		},
+		{
+			name: "constant",
+			fn: func() (encoder, func() string) {
+				b := NewConstantParams().WithLimit(10)
+				return b, func() string { return b.WithOrder(OrderAsc).Encode() }
+			},
+			want: "limit=10&order=asc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, derive := tt.fn()
+			before := base.Encode()
+			derived := derive()
+			if got := base.Encode(); got != before {
+				t.Errorf("base changed: got %q, want %q", got, before)
+			}
+			if derived != tt.want {
+				t.Errorf("derived: got %q, want %q", derived, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamsSharedBase(t *testing.T) {
+	base := NewOpParams().WithLimit(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := base.WithCursor(uint64(i*1000 + j))
+				if p.Get("limit") != "100" || base.Has("cursor") {
+					t.Errorf("shared base modified: %s", base.Encode())
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,479 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Predicate is a client-side filter expression evaluated on decoded rows.
+// It complements server-side filters with conditions the API cannot
+// express, e.g. on rendered contract storage or call parameters.
+//
+// Expressions compare a field path with a literal and can be combined
+// with &&, || and ! and grouped with parentheses:
+//
+//	volume > 100 && (type == "transaction" || type == "origination")
+//	storage.ledger.total_supply >= 1000000 && !(sender == "tz1...")
+//	parameters.entrypoint == "transfer" && entrypoint ~= "mint"
+//
+// Field names are the JSON names of row fields. Paths into contract
+// storage, parameters and other JSON values use dots. Comparison
+// operators are ==, !=, <, <=, >, >= and ~= (substring match). Numbers
+// compare numerically, times compare chronologically when the literal is
+// an RFC3339 timestamp, everything else compares as string. Comparisons
+// on missing fields are false.
+type Predicate struct {
+	expr string
+	root predNode
+}
+
+// ParsePredicate compiles a filter expression.
+func ParsePredicate(expr string) (*Predicate, error) {
+	toks, err := lexPredicate(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &predParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("predicate: unexpected %q at offset %d", p.toks[p.pos].val, p.toks[p.pos].off)
+	}
+	return &Predicate{expr: expr, root: root}, nil
+}
+
+func MustParsePredicate(expr string) *Predicate {
+	p, err := ParsePredicate(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Predicate) String() string {
+	return p.expr
+}
+
+// Match evaluates the predicate on a row, typically a *Op, *Block or other
+// decoded table row.
+func (p *Predicate) Match(row interface{}) bool {
+	return p.root.eval(reflect.ValueOf(row))
+}
+
+// FilterOps returns all operations matching the predicate.
+func (p *Predicate) FilterOps(ops []*Op) []*Op {
+	res := make([]*Op, 0, len(ops))
+	for _, o := range ops {
+		if p.Match(o) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// FilterBlocks returns all blocks matching the predicate.
+func (p *Predicate) FilterBlocks(blocks []*Block) []*Block {
+	res := make([]*Block, 0, len(blocks))
+	for _, b := range blocks {
+		if p.Match(b) {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// PredicateSink forwards only matching operations and blocks to the
+// wrapped sink. A nil predicate passes all rows of its kind.
+type PredicateSink struct {
+	sink   Sink
+	ops    *Predicate
+	blocks *Predicate
+}
+
+func NewPredicateSink(s Sink, ops, blocks *Predicate) *PredicateSink {
+	return &PredicateSink{sink: s, ops: ops, blocks: blocks}
+}
+
+func (s *PredicateSink) WriteOps(ctx context.Context, ops []*Op) error {
+	if s.ops != nil {
+		ops = s.ops.FilterOps(ops)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return s.sink.WriteOps(ctx, ops)
+}
+
+func (s *PredicateSink) WriteBlocks(ctx context.Context, blocks []*Block) error {
+	if s.blocks != nil {
+		blocks = s.blocks.FilterBlocks(blocks)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return s.sink.WriteBlocks(ctx, blocks)
+}
+
+func (s *PredicateSink) Flush(ctx context.Context) error {
+	return s.sink.Flush(ctx)
+}
+
+// evaluation
+
+type predNode interface {
+	eval(row reflect.Value) bool
+}
+
+type predAnd struct{ l, r predNode }
+type predOr struct{ l, r predNode }
+type predNot struct{ n predNode }
+
+type predCmp struct {
+	path []string
+	op   string
+	lit  predLiteral
+}
+
+func (n predAnd) eval(row reflect.Value) bool { return n.l.eval(row) && n.r.eval(row) }
+func (n predOr) eval(row reflect.Value) bool  { return n.l.eval(row) || n.r.eval(row) }
+func (n predNot) eval(row reflect.Value) bool { return !n.n.eval(row) }
+
+func (n predCmp) eval(row reflect.Value) bool {
+	v, ok := resolvePath(row, n.path)
+	if !ok {
+		return false
+	}
+	if n.op == "~=" {
+		return strings.Contains(valueString(v), n.lit.str)
+	}
+	c, ok := compareValue(v, n.lit)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+type predLiteral struct {
+	str  string
+	num  *big.Float
+	time time.Time
+}
+
+func newPredLiteral(s string) predLiteral {
+	l := predLiteral{str: s}
+	if f, ok := new(big.Float).SetString(s); ok {
+		l.num = f
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		l.time = t
+	}
+	return l
+}
+
+// compareValue compares a row value with a literal and returns -1, 0 or 1.
+func compareValue(v interface{}, lit predLiteral) (int, bool) {
+	if t, ok := v.(time.Time); ok && !lit.time.IsZero() {
+		switch {
+		case t.Before(lit.time):
+			return -1, true
+		case t.After(lit.time):
+			return 1, true
+		}
+		return 0, true
+	}
+	s := valueString(v)
+	if lit.num != nil {
+		if f, ok := new(big.Float).SetString(s); ok {
+			return f.Cmp(lit.num), true
+		}
+	}
+	return strings.Compare(s, lit.str), true
+}
+
+func valueString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case fmt.Stringer:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	}
+	return ToString(v)
+}
+
+// field resolution
+
+var predFieldCache sync.Map // reflect.Type -> map[string]int
+
+func jsonFieldIndex(typ reflect.Type) map[string]int {
+	if m, ok := predFieldCache.Load(typ); ok {
+		return m.(map[string]int)
+	}
+	m := make(map[string]int)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		m[name] = i
+	}
+	predFieldCache.Store(typ, m)
+	return m
+}
+
+func resolvePath(v reflect.Value, path []string) (interface{}, bool) {
+	for len(path) > 0 {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+		if v.CanInterface() {
+			switch t := v.Interface().(type) {
+			case ContractParameters:
+				if path[0] == "entrypoint" && len(path) == 1 {
+					return t.Entrypoint, true
+				}
+				return getPathValue(t.Value, strings.Join(path, "."))
+			case ContractValue:
+				return getPathValue(t.Value, strings.Join(path, "."))
+			case map[string]interface{}:
+				return getPathValue(t, strings.Join(path, "."))
+			case json.RawMessage:
+				var val interface{}
+				if err := json.Unmarshal(t, &val); err != nil {
+					return nil, false
+				}
+				return getPathValue(val, strings.Join(path, "."))
+			}
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, false
+		}
+		idx := jsonFieldIndex(v.Type())
+		i, ok := idx[path[0]]
+		if !ok {
+			// search embedded structs
+			for j := 0; j < v.NumField(); j++ {
+				if f := v.Type().Field(j); f.Anonymous {
+					if res, ok := resolvePath(v.Field(j), path); ok {
+						return res, true
+					}
+				}
+			}
+			return nil, false
+		}
+		v = v.Field(i)
+		path = path[1:]
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// parsing
+
+type predToken struct {
+	kind byte // 'i' ident, 'l' literal, 'o' operator
+	val  string
+	off  int
+}
+
+func lexPredicate(s string) ([]predToken, error) {
+	toks := make([]predToken, 0)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, predToken{'o', string(c), i})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("predicate: unterminated string at offset %d", i)
+			}
+			toks = append(toks, predToken{'l', b.String(), i})
+			i = j + 1
+		case strings.ContainsRune("=!<>~&|", rune(c)):
+			op := string(c)
+			if i+1 < len(s) && strings.ContainsRune("=&|", rune(s[i+1])) {
+				op += string(s[i+1])
+			}
+			switch op {
+			case "==", "!=", "<", "<=", ">", ">=", "~=", "&&", "||", "!":
+			default:
+				return nil, fmt.Errorf("predicate: invalid operator %q at offset %d", op, i)
+			}
+			toks = append(toks, predToken{'o', op, i})
+			i += len(op)
+		case c == '-' || c == '+' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("0123456789.eE+-", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, predToken{'l', s[i:j], i})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			word := s[i:j]
+			if word == "true" || word == "false" {
+				toks = append(toks, predToken{'l', word, i})
+			} else {
+				toks = append(toks, predToken{'i', word, i})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("predicate: unexpected character %q at offset %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+type predParser struct {
+	toks []predToken
+	pos  int
+}
+
+func (p *predParser) peek(val string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == 'o' && p.toks[p.pos].val == val
+}
+
+func (p *predParser) parseOr() (predNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("||") {
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = predOr{l, r}
+	}
+	return l, nil
+}
+
+func (p *predParser) parseAnd() (predNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("&&") {
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = predAnd{l, r}
+	}
+	return l, nil
+}
+
+func (p *predParser) parseUnary() (predNode, error) {
+	switch {
+	case p.peek("!"):
+		p.pos++
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return predNot{n}, nil
+	case p.peek("("):
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("predicate: missing closing parenthesis")
+		}
+		p.pos++
+		return n, nil
+	}
+	if p.pos+2 >= len(p.toks) {
+		return nil, fmt.Errorf("predicate: incomplete comparison")
+	}
+	field, op, lit := p.toks[p.pos], p.toks[p.pos+1], p.toks[p.pos+2]
+	if field.kind != 'i' {
+		return nil, fmt.Errorf("predicate: expected field name at offset %d", field.off)
+	}
+	switch op.val {
+	case "==", "!=", "<", "<=", ">", ">=", "~=":
+	default:
+		return nil, fmt.Errorf("predicate: expected comparison at offset %d", op.off)
+	}
+	if lit.kind != 'l' {
+		return nil, fmt.Errorf("predicate: expected literal at offset %d", lit.off)
+	}
+	p.pos += 3
+	return predCmp{
+		path: strings.Split(field.val, "."),
+		op:   op.val,
+		lit:  newPredLiteral(lit.val),
+	}, nil
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Prepared queries are checked and rendered once and can then be run many
+// times with different placeholder values. Filter values created with
+// Placeholder are bound to QueryArgs on each run, all other settings are
+// fixed when the query is prepared.
+//
+//	q := c.NewOpQuery()
+//	q.AndEq("sender", tzstats.Placeholder("addr")).
+//		AndEq("cycle", tzstats.Placeholder("cycle"))
+//	p, err := q.Prepare()
+//	for _, addr := range addrs {
+//		ops, err := p.Run(ctx, tzstats.QueryArgs{"addr": addr, "cycle": 500})
+//	}
+
+// PlaceholderArg is a filter value which is bound when a prepared query
+// runs. Only single value filters can hold a placeholder, Prepare rejects
+// placeholders in in, nin and range lists. Unprepared queries send it as
+// $name like named queries.
+type PlaceholderArg struct {
+	name string
+}
+
+// Placeholder returns a filter value which is bound to QueryArgs[name]
+// when a prepared query runs. Plain values are never treated as
+// placeholders, even when they start with $.
+func Placeholder(name string) PlaceholderArg {
+	return PlaceholderArg{name}
+}
+
+func (a PlaceholderArg) Name() string {
+	return a.name
+}
+
+func (a PlaceholderArg) String() string {
+	return "$" + a.name
+}
+
+// filterValue renders filter values. A single placeholder is kept so
+// prepare can bind it, lists which contain placeholders are kept as is
+// and rejected by prepare.
+func filterValue(vals []interface{}) interface{} {
+	args, n := findPlaceholders(vals)
+	switch {
+	case len(args) == 0:
+		return ToString(vals)
+	case n == 1:
+		return args[0]
+	default:
+		return vals
+	}
+}
+
+// findPlaceholders returns all placeholders in v and the number of values
+// after expanding nested slices.
+func findPlaceholders(v interface{}) ([]PlaceholderArg, int) {
+	switch x := v.(type) {
+	case PlaceholderArg:
+		return []PlaceholderArg{x}, 1
+	case fmt.Stringer, []byte:
+		return nil, 1
+	}
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Slice {
+		return nil, 1
+	}
+	var (
+		args []PlaceholderArg
+		n    int
+	)
+	for i := 0; i < val.Len(); i++ {
+		a, m := findPlaceholders(val.Index(i).Interface())
+		args = append(args, a...)
+		n += m
+	}
+	return args, n
+}
+
+// preparedQuery is a pre-rendered table query url.
+type preparedQuery struct {
+	params Params
+	path   string
+	base   url.Values // all arguments except cursor and placeholders
+	slots  []preparedSlot
+}
+
+type preparedSlot struct {
+	key  string // url query key, e.g. sender.eq
+	name string // placeholder name
+}
+
+func (q tableQuery) prepare() (*preparedQuery, error) {
+	if q.prep != nil {
+		return q.prep, nil
+	}
+	if err := q.Check(); err != nil {
+		return nil, err
+	}
+	bs, err := q.filterBranches()
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) > 1 {
+		return nil, fmt.Errorf("cannot prepare filter expression with %d branches", len(bs))
+	}
+	p := &preparedQuery{
+		params: Params{
+			Server: q.Params.Server,
+			Prefix: q.Params.Prefix,
+		},
+		path: q.path(),
+		base: q.values(bs[0]),
+	}
+	var version ApiVersion
+	if q.client != nil {
+		version = q.client.ApiVersion()
+	}
+	for _, f := range append(q.Filter[:len(q.Filter):len(q.Filter)], bs[0]...) {
+		switch v := f.Value.(type) {
+		case PlaceholderArg:
+			key := f.key(version)
+			p.slots = append(p.slots, preparedSlot{key, v.name})
+			delete(p.base, key)
+		default:
+			if args, _ := findPlaceholders(v); len(args) > 0 {
+				return nil, fmt.Errorf("cannot prepare filter %s.%s: placeholder %s in value list", f.Column, f.Mode, args[0])
+			}
+		}
+	}
+	sort.Slice(p.slots, func(i, j int) bool { return p.slots[i].key < p.slots[j].key })
+	return p, nil
+}
+
+// check ensures all placeholders are bound.
+func (p *preparedQuery) check(args QueryArgs) error {
+	for _, v := range p.slots {
+		if _, ok := args[v.name]; !ok {
+			return fmt.Errorf("prepared query: missing argument %q for %s", v.name, v.key)
+		}
+	}
+	return nil
+}
+
+func (p *preparedQuery) url(cursor uint64, args QueryArgs) string {
+	q := make(url.Values, len(p.base)+len(p.slots)+1)
+	for k, v := range p.base {
+		q[k] = v
+	}
+	for _, v := range p.slots {
+		q.Set(v.key, ToString(args[v.name]))
+	}
+	if cursor > 0 {
+		q.Set("cursor", strconv.FormatUint(cursor, 10))
+	}
+	params := p.params
+	params.query = q
+	return params.Url(p.path)
+}
+
+// placeholders returns the sorted names of all placeholders.
+func (p *preparedQuery) placeholders() []string {
+	names := make([]string, 0, len(p.slots))
+	for _, v := range p.slots {
+		names = append(names, v.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PreparedOpQuery is an op query with pre-rendered filters.
+type PreparedOpQuery struct {
+	q OpQuery
+}
+
+func (q OpQuery) Prepare() (PreparedOpQuery, error) {
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedOpQuery{}, err
+	}
+	q.prep = p
+	return PreparedOpQuery{q}, nil
+}
+
+func (p PreparedOpQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run, Each and Stream.
+func (p PreparedOpQuery) Bind(args QueryArgs) OpQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedOpQuery) Run(ctx context.Context, args QueryArgs) (*OpList, error) {
+	return p.Bind(args).Run(ctx)
+}
+
+// PreparedBlockQuery is a block query with pre-rendered filters.
+type PreparedBlockQuery struct {
+	q BlockQuery
+}
+
+func (q BlockQuery) Prepare() (PreparedBlockQuery, error) {
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedBlockQuery{}, err
+	}
+	q.prep = p
+	return PreparedBlockQuery{q}, nil
+}
+
+func (p PreparedBlockQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run, Each and Stream.
+func (p PreparedBlockQuery) Bind(args QueryArgs) BlockQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedBlockQuery) Run(ctx context.Context, args QueryArgs) (*BlockList, error) {
+	return p.Bind(args).Run(ctx)
+}
+
+// PreparedAccountQuery is an account query with pre-rendered filters.
+type PreparedAccountQuery struct {
+	q AccountQuery
+}
+
+func (q AccountQuery) Prepare() (PreparedAccountQuery, error) {
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedAccountQuery{}, err
+	}
+	q.prep = p
+	return PreparedAccountQuery{q}, nil
+}
+
+func (p PreparedAccountQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run, Each and Stream.
+func (p PreparedAccountQuery) Bind(args QueryArgs) AccountQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedAccountQuery) Run(ctx context.Context, args QueryArgs) (*AccountList, error) {
+	return p.Bind(args).Run(ctx)
+}
+
+// PreparedCycleRightsQuery is a rights query with pre-rendered filters.
+type PreparedCycleRightsQuery struct {
+	q CycleRightsQuery
+}
+
+func (q CycleRightsQuery) Prepare() (PreparedCycleRightsQuery, error) {
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedCycleRightsQuery{}, err
+	}
+	q.prep = p
+	return PreparedCycleRightsQuery{q}, nil
+}
+
+func (p PreparedCycleRightsQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run, Each and Stream.
+func (p PreparedCycleRightsQuery) Bind(args QueryArgs) CycleRightsQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedCycleRightsQuery) Run(ctx context.Context, args QueryArgs) (*CycleRightsList, error) {
+	return p.Bind(args).Run(ctx)
+}
+
+// PreparedSnapshotQuery is a snapshot query with pre-rendered filters.
+type PreparedSnapshotQuery struct {
+	q SnapshotQuery
+}
+
+func (q SnapshotQuery) Prepare() (PreparedSnapshotQuery, error) {
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedSnapshotQuery{}, err
+	}
+	q.prep = p
+	return PreparedSnapshotQuery{q}, nil
+}
+
+func (p PreparedSnapshotQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run, Each and Stream.
+func (p PreparedSnapshotQuery) Bind(args QueryArgs) SnapshotQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedSnapshotQuery) Run(ctx context.Context, args QueryArgs) (*SnapshotList, error) {
+	return p.Bind(args).Run(ctx)
+}
+
+// PreparedRawQuery is a raw query with pre-rendered filters.
+type PreparedRawQuery struct {
+	q RawQuery
+}
+
+func (q RawQuery) Prepare() (PreparedRawQuery, error) {
+	if len(q.Columns) == 0 {
+		return PreparedRawQuery{}, fmt.Errorf("raw query on table %s requires columns", q.Table)
+	}
+	p, err := q.prepare()
+	if err != nil {
+		return PreparedRawQuery{}, err
+	}
+	q.prep = p
+	return PreparedRawQuery{q}, nil
+}
+
+func (p PreparedRawQuery) Placeholders() []string {
+	return p.q.prep.placeholders()
+}
+
+// Bind returns a query for args which supports Run and Each.
+func (p PreparedRawQuery) Bind(args QueryArgs) RawQuery {
+	q := p.q
+	q.args = args
+	return q
+}
+
+func (p PreparedRawQuery) Run(ctx context.Context, args QueryArgs) (*RawList, error) {
+	return p.Bind(args).Run(ctx)
+}
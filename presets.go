@@ -0,0 +1,136 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Column presets are named column sets for table queries. Presets are
+// declared with a preset struct tag on row model fields
+//
+//	Fee float64 `json:"fee" preset:"light,fees"`
+//
+// or registered with RegisterColumnPreset. Every table with a row model
+// also has a "full" preset with all table columns.
+//
+//	q := c.NewOpQuery()
+//	q.WithColumnsPreset("fees")
+const (
+	presetTagName = "preset"
+	PresetFull    = "full"
+)
+
+var (
+	columnPresets     = make(map[string]map[string][]string)
+	columnPresetsLock sync.RWMutex
+)
+
+// RegisterColumnPreset adds or replaces a named column set for table.
+// Columns are checked against the table's row model if known.
+func RegisterColumnPreset(table, name string, cols ...string) error {
+	if table == "" || name == "" {
+		return fmt.Errorf("column preset: empty table or preset name")
+	}
+	if len(cols) == 0 {
+		return fmt.Errorf("column preset %s.%s: empty column list", table, name)
+	}
+	if err := checkColumns(table, cols); err != nil {
+		return fmt.Errorf("column preset %s.%s: %w", table, name, err)
+	}
+	columnPresetsLock.Lock()
+	defer columnPresetsLock.Unlock()
+	m, ok := columnPresets[table]
+	if !ok {
+		m = make(map[string][]string)
+		columnPresets[table] = m
+	}
+	m[name] = append([]string(nil), cols...)
+	return nil
+}
+
+// ColumnPreset returns a copy of the named column set for table.
+// Registered presets take precedence over struct tags.
+func ColumnPreset(table, name string) ([]string, bool) {
+	columnPresetsLock.RLock()
+	cols, ok := columnPresets[table][name]
+	columnPresetsLock.RUnlock()
+	if ok {
+		return append([]string(nil), cols...), true
+	}
+	m, ok := tableModels[table]
+	if !ok {
+		return nil, false
+	}
+	if name == PresetFull {
+		return tableColumnsOf(m), true
+	}
+	cols, ok = taggedPresets(m)[name]
+	return cols, ok
+}
+
+// ColumnPresets lists preset names available for table.
+func ColumnPresets(table string) []string {
+	names := make(map[string]struct{})
+	columnPresetsLock.RLock()
+	for n := range columnPresets[table] {
+		names[n] = struct{}{}
+	}
+	columnPresetsLock.RUnlock()
+	if m, ok := tableModels[table]; ok {
+		names[PresetFull] = struct{}{}
+		for n := range taggedPresets(m) {
+			names[n] = struct{}{}
+		}
+	}
+	res := make([]string, 0, len(names))
+	for n := range names {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func tableColumnsOf(m interface{}) []string {
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil
+	}
+	return tinfo.FilteredAliases("notable")
+}
+
+// taggedPresets returns column sets declared by preset tags on model m.
+func taggedPresets(m interface{}) map[string][]string {
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil
+	}
+	typ := reflect.Indirect(reflect.ValueOf(m)).Type()
+	res := make(map[string][]string)
+	for _, f := range tinfo.Fields {
+		tag := typ.FieldByIndex(f.Idx).Tag.Get(presetTagName)
+		if tag == "" {
+			continue
+		}
+		for _, n := range strings.Split(tag, ",") {
+			res[n] = append(res[n], f.Alias)
+		}
+	}
+	return res
+}
+
+// WithColumnsPreset selects the columns of a named preset. Unknown presets
+// are reported by Check.
+func (q *tableQuery) WithColumnsPreset(name string) TableQuery {
+	cols, ok := ColumnPreset(q.Table, name)
+	if !ok {
+		return q.fail(fmt.Errorf("unknown column preset %q for table %s", name, q.Table))
+	}
+	q.Columns = cols
+	return q
+}
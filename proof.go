@@ -0,0 +1,136 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// InclusionProof contains the signed block header and the hashes of all
+// operations in a block grouped by validation pass. The header commits to
+// the operation hashes via its operations_hash Merkle root and the block
+// hash commits to the header, so a proof can be checked without trusting
+// the indexer. The indexer does not serve proofs, load them from a node
+// with LoadInclusionProof.
+type InclusionProof struct {
+	Header codec.BlockHeader `json:"header"`
+	Ops    [][]tezos.OpHash  `json:"ops"`
+}
+
+func LoadInclusionProof(ctx context.Context, node *rpc.Client, block tezos.BlockHash) (*InclusionProof, error) {
+	h, err := GetBlockHeaderRaw(ctx, node, block)
+	if err != nil {
+		return nil, err
+	}
+	p := &InclusionProof{Header: h.Header}
+	ops, err := node.GetBlockOperationHashes(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	p.Ops = ops
+	return p, nil
+}
+
+// BlockHash returns the hash of the proof's block header.
+func (p InclusionProof) BlockHash() tezos.BlockHash {
+	h := tezos.Digest(p.Header.Bytes())
+	return tezos.NewBlockHash(h[:])
+}
+
+// OperationsHash returns the Merkle root over all operation hashes.
+func (p InclusionProof) OperationsHash() tezos.OpListListHash {
+	lists := make([][32]byte, len(p.Ops))
+	for i, l := range p.Ops {
+		leaves := make([][32]byte, len(l))
+		for j, h := range l {
+			copy(leaves[j][:], h.Bytes())
+		}
+		lists[i] = merkleRoot(leaves)
+	}
+	h := merkleRoot(lists)
+	return tezos.NewOpListListHash(h[:])
+}
+
+// Verify checks that the proof is valid for block and contains op.
+func (p InclusionProof) Verify(block tezos.BlockHash, op tezos.OpHash) error {
+	if h := p.BlockHash(); !h.Equal(block) {
+		return fmt.Errorf("proof: header hash %s does not match block %s", h, block)
+	}
+	if h := p.OperationsHash(); !h.Equal(p.Header.OperationsHash) {
+		return fmt.Errorf("proof: operations hash %s does not match header %s", h, p.Header.OperationsHash)
+	}
+	for _, l := range p.Ops {
+		for _, h := range l {
+			if h.Equal(op) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("proof: op %s not included in block %s", op, block)
+}
+
+// Verify checks that the operation is included in its block.
+func (o *Op) Verify(p *InclusionProof) error {
+	return p.Verify(o.Block, o.Hash)
+}
+
+// Verify checks that the proof's header belongs to this block.
+func (b *Block) Verify(p *InclusionProof) error {
+	if h := p.BlockHash(); !h.Equal(b.Hash) {
+		return fmt.Errorf("proof: header hash %s does not match block %s", h, b.Hash)
+	}
+	if int64(p.Header.Level) != b.Height {
+		return fmt.Errorf("proof: header level %d does not match block height %d", p.Header.Level, b.Height)
+	}
+	if b.ParentHash != nil && !b.ParentHash.Equal(p.Header.Predecessor) {
+		return fmt.Errorf("proof: header predecessor %s does not match block parent %s", p.Header.Predecessor, b.ParentHash)
+	}
+	return nil
+}
+
+// merkleRoot computes a Tezos Blake2b Merkle tree root. Leaves are hashed,
+// odd levels are padded by repeating the last node.
+func merkleRoot(xs [][32]byte) [32]byte {
+	switch len(xs) {
+	case 0:
+		return tezos.Digest(nil)
+	case 1:
+		return tezos.Digest(xs[0][:])
+	}
+	n := len(xs)
+	a := make([][32]byte, n+1)
+	for i, x := range xs {
+		a[i] = tezos.Digest(x[:])
+	}
+	a[n] = a[n-1]
+	return merkleStep(a, n)
+}
+
+func merkleStep(a [][32]byte, n int) [32]byte {
+	m := (n + 1) / 2
+	for i := 0; i < m; i++ {
+		a[i] = merkleNode(a[2*i], a[2*i+1])
+	}
+	a[m] = merkleNode(a[n], a[n])
+	if m == 1 {
+		return a[0]
+	}
+	if m%2 == 0 {
+		return merkleStep(a, m)
+	}
+	a[m+1] = a[m]
+	return merkleStep(a, m+1)
+}
+
+func merkleNode(l, r [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], l[:])
+	copy(buf[32:], r[:])
+	return tezos.Digest(buf[:])
+}
@@ -0,0 +1,552 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build proto
+// +build proto
+
+// Converters between SDK models and their protobuf representations in
+// proto/tzstatspb. Build with `-tags proto`.
+
+package tzstats
+
+import (
+	"encoding/json"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+	"blockwatch.cc/tzstats-go/proto/tzstatspb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (o *Op) Proto() *tzstatspb.Op {
+	p := &tzstatspb.Op{
+		Id:            o.Id,
+		Hash:          o.Hash.String(),
+		Type:          o.Type.String(),
+		Block:         o.Block.String(),
+		Time:          protoTime(o.Timestamp),
+		Height:        o.Height,
+		Cycle:         o.Cycle,
+		Counter:       o.Counter,
+		OpN:           int32(o.OpN),
+		OpP:           int32(o.OpP),
+		Status:        o.Status.String(),
+		IsSuccess:     o.IsSuccess,
+		IsContract:    o.IsContract,
+		IsBatch:       o.IsBatch,
+		IsEvent:       o.IsEvent,
+		IsInternal:    o.IsInternal,
+		GasLimit:      o.GasLimit,
+		GasUsed:       o.GasUsed,
+		StorageLimit:  o.StorageLimit,
+		StoragePaid:   o.StoragePaid,
+		Volume:        o.Volume,
+		Fee:           o.Fee,
+		Reward:        o.Reward,
+		Deposit:       o.Deposit,
+		Burned:        o.Burned,
+		DaysDestroyed: o.TDD,
+		SenderId:      o.SenderId,
+		ReceiverId:    o.ReceiverId,
+		CreatorId:     o.CreatorId,
+		BakerId:       o.BakerId,
+		Sender:        o.Sender.String(),
+		Receiver:      o.Receiver.String(),
+		Creator:       o.Creator.String(),
+		Baker:         o.Baker.String(),
+		PreviousBaker: o.PrevBaker.String(),
+		Source:        o.Source.String(),
+		Offender:      o.Offender.String(),
+		Accuser:       o.Accuser.String(),
+		Entrypoint:    o.Entrypoint,
+		Data:          o.Data,
+		Errors:        o.Errors,
+		Power:         int32(o.Power),
+		Limit:         o.Limit,
+		Confirmations: o.Confirmations,
+	}
+	if o.Parameters != nil {
+		p.Parameters, _ = json.Marshal(o.Parameters)
+	}
+	if o.Storage != nil {
+		p.Storage, _ = json.Marshal(o.Storage)
+	}
+	if o.Value.IsValid() {
+		p.Value, _ = json.Marshal(o.Value)
+	}
+	for i := range o.BigmapDiff {
+		p.BigMapDiff = append(p.BigMapDiff, o.BigmapDiff[i].Proto())
+	}
+	for _, v := range o.Batch {
+		p.Batch = append(p.Batch, v.Proto())
+	}
+	for _, v := range o.Internal {
+		p.Internal = append(p.Internal, v.Proto())
+	}
+	return p
+}
+
+func NewOpFromProto(p *tzstatspb.Op) (*Op, error) {
+	o := &Op{
+		Id:            p.Id,
+		Type:          ParseOpType(p.Type),
+		Timestamp:     fromProtoTime(p.Time),
+		Height:        p.Height,
+		Cycle:         p.Cycle,
+		Counter:       p.Counter,
+		OpN:           int(p.OpN),
+		OpP:           int(p.OpP),
+		Status:        tezos.ParseOpStatus(p.Status),
+		IsSuccess:     p.IsSuccess,
+		IsContract:    p.IsContract,
+		IsBatch:       p.IsBatch,
+		IsEvent:       p.IsEvent,
+		IsInternal:    p.IsInternal,
+		GasLimit:      p.GasLimit,
+		GasUsed:       p.GasUsed,
+		StorageLimit:  p.StorageLimit,
+		StoragePaid:   p.StoragePaid,
+		Volume:        p.Volume,
+		Fee:           p.Fee,
+		Reward:        p.Reward,
+		Deposit:       p.Deposit,
+		Burned:        p.Burned,
+		TDD:           p.DaysDestroyed,
+		SenderId:      p.SenderId,
+		ReceiverId:    p.ReceiverId,
+		CreatorId:     p.CreatorId,
+		BakerId:       p.BakerId,
+		Entrypoint:    p.Entrypoint,
+		Data:          p.Data,
+		Errors:        p.Errors,
+		Power:         int(p.Power),
+		Limit:         p.Limit,
+		Confirmations: p.Confirmations,
+	}
+	var err error
+	if o.Hash, err = parseOpHashOpt(p.Hash); err != nil {
+		return nil, err
+	}
+	if o.Block, err = parseBlockHashOpt(p.Block); err != nil {
+		return nil, err
+	}
+	for _, v := range []struct {
+		a *tezos.Address
+		s string
+	}{
+		{&o.Sender, p.Sender},
+		{&o.Receiver, p.Receiver},
+		{&o.Creator, p.Creator},
+		{&o.Baker, p.Baker},
+		{&o.PrevBaker, p.PreviousBaker},
+		{&o.Source, p.Source},
+		{&o.Offender, p.Offender},
+		{&o.Accuser, p.Accuser},
+	} {
+		if *v.a, err = parseAddressOpt(v.s); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Parameters) > 0 {
+		o.Parameters = &ContractParameters{}
+		if err := json.Unmarshal(p.Parameters, o.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Storage) > 0 {
+		o.Storage = &ContractValue{}
+		if err := json.Unmarshal(p.Storage, o.Storage); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Value) > 0 {
+		if err := json.Unmarshal(p.Value, &o.Value); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range p.BigMapDiff {
+		u, err := NewBigmapUpdateFromProto(v)
+		if err != nil {
+			return nil, err
+		}
+		o.BigmapDiff = append(o.BigmapDiff, *u)
+	}
+	for _, v := range p.Batch {
+		b, err := NewOpFromProto(v)
+		if err != nil {
+			return nil, err
+		}
+		o.Batch = append(o.Batch, b)
+	}
+	for _, v := range p.Internal {
+		i, err := NewOpFromProto(v)
+		if err != nil {
+			return nil, err
+		}
+		o.Internal = append(o.Internal, i)
+	}
+	return o, nil
+}
+
+func (b *Block) Proto() *tzstatspb.Block {
+	p := &tzstatspb.Block{
+		RowId:            b.RowId,
+		Hash:             b.Hash.String(),
+		Time:             protoTime(b.Timestamp),
+		Height:           b.Height,
+		Cycle:            b.Cycle,
+		IsCycleSnapshot:  b.IsCycleSnapshot,
+		Solvetime:        int32(b.Solvetime),
+		Version:          int32(b.Version),
+		Round:            int32(b.Round),
+		Nonce:            b.Nonce,
+		VotingPeriodKind: b.VotingPeriodKind.String(),
+		BakerId:          b.BakerId,
+		Baker:            b.Baker.String(),
+		ProposerId:       b.ProposerId,
+		Proposer:         b.Proposer.String(),
+		NEndorsedSlots:   int32(b.NSlotsEndorsed),
+		NOpsApplied:      int32(b.NOpsApplied),
+		NOpsFailed:       int32(b.NOpsFailed),
+		NCalls:           int32(b.NContractCalls),
+		NEvents:          int32(b.NEvents),
+		Volume:           b.Volume,
+		Fee:              b.Fee,
+		Reward:           b.Reward,
+		Deposit:          b.Deposit,
+		ActivatedSupply:  b.ActivatedSupply,
+		MintedSupply:     b.MintedSupply,
+		BurnedSupply:     b.BurnedSupply,
+		NAccounts:        int32(b.SeenAccounts),
+		NNewAccounts:     int32(b.NewAccounts),
+		NNewContracts:    int32(b.NewContracts),
+		NClearedAccounts: int32(b.ClearedAccounts),
+		NFundedAccounts:  int32(b.FundedAccounts),
+		GasLimit:         b.GasLimit,
+		GasUsed:          b.GasUsed,
+		StoragePaid:      b.StoragePaid,
+		PctAccountReuse:  b.PctAccountReuse,
+		LbEscVote:        b.LbEscapeVote,
+		LbEscEma:         b.LbEscapeEma,
+		Protocol:         b.Protocol.String(),
+	}
+	if b.ParentHash != nil {
+		p.Predecessor = b.ParentHash.String()
+	}
+	if b.FollowerHash != nil {
+		p.Successor = b.FollowerHash.String()
+	}
+	for _, v := range b.Ops {
+		p.Ops = append(p.Ops, v.Proto())
+	}
+	return p
+}
+
+func NewBlockFromProto(p *tzstatspb.Block) (*Block, error) {
+	b := &Block{
+		RowId:            p.RowId,
+		Timestamp:        fromProtoTime(p.Time),
+		Height:           p.Height,
+		Cycle:            p.Cycle,
+		IsCycleSnapshot:  p.IsCycleSnapshot,
+		Solvetime:        int(p.Solvetime),
+		Version:          int(p.Version),
+		Round:            int(p.Round),
+		Nonce:            p.Nonce,
+		VotingPeriodKind: tezos.ParseVotingPeriod(p.VotingPeriodKind),
+		BakerId:          p.BakerId,
+		ProposerId:       p.ProposerId,
+		NSlotsEndorsed:   int(p.NEndorsedSlots),
+		NOpsApplied:      int(p.NOpsApplied),
+		NOpsFailed:       int(p.NOpsFailed),
+		NContractCalls:   int(p.NCalls),
+		NEvents:          int(p.NEvents),
+		Volume:           p.Volume,
+		Fee:              p.Fee,
+		Reward:           p.Reward,
+		Deposit:          p.Deposit,
+		ActivatedSupply:  p.ActivatedSupply,
+		MintedSupply:     p.MintedSupply,
+		BurnedSupply:     p.BurnedSupply,
+		SeenAccounts:     int(p.NAccounts),
+		NewAccounts:      int(p.NNewAccounts),
+		NewContracts:     int(p.NNewContracts),
+		ClearedAccounts:  int(p.NClearedAccounts),
+		FundedAccounts:   int(p.NFundedAccounts),
+		GasLimit:         p.GasLimit,
+		GasUsed:          p.GasUsed,
+		StoragePaid:      p.StoragePaid,
+		PctAccountReuse:  p.PctAccountReuse,
+		LbEscapeVote:     p.LbEscVote,
+		LbEscapeEma:      p.LbEscEma,
+	}
+	var err error
+	if b.Hash, err = parseBlockHashOpt(p.Hash); err != nil {
+		return nil, err
+	}
+	if p.Predecessor != "" {
+		h, err := tezos.ParseBlockHash(p.Predecessor)
+		if err != nil {
+			return nil, err
+		}
+		b.ParentHash = &h
+	}
+	if p.Successor != "" {
+		h, err := tezos.ParseBlockHash(p.Successor)
+		if err != nil {
+			return nil, err
+		}
+		b.FollowerHash = &h
+	}
+	if p.Protocol != "" {
+		if b.Protocol, err = tezos.ParseProtocolHash(p.Protocol); err != nil {
+			return nil, err
+		}
+	}
+	if b.Baker, err = parseAddressOpt(p.Baker); err != nil {
+		return nil, err
+	}
+	if b.Proposer, err = parseAddressOpt(p.Proposer); err != nil {
+		return nil, err
+	}
+	for _, v := range p.Ops {
+		o, err := NewOpFromProto(v)
+		if err != nil {
+			return nil, err
+		}
+		b.Ops = append(b.Ops, o)
+	}
+	return b, nil
+}
+
+func (a *Account) Proto() *tzstatspb.Account {
+	p := &tzstatspb.Account{
+		RowId:              a.RowId,
+		Address:            a.Address.String(),
+		AddressType:        a.AddressType.String(),
+		Counter:            a.Counter,
+		BakerId:            a.BakerId,
+		CreatorId:          a.CreatorId,
+		FirstIn:            a.FirstIn,
+		FirstOut:           a.FirstOut,
+		FirstSeen:          a.FirstSeen,
+		LastIn:             a.LastIn,
+		LastOut:            a.LastOut,
+		LastSeen:           a.LastSeen,
+		FirstSeenTime:      protoTime(a.FirstSeenTime),
+		LastSeenTime:       protoTime(a.LastSeenTime),
+		FirstInTime:        protoTime(a.FirstInTime),
+		LastInTime:         protoTime(a.LastInTime),
+		FirstOutTime:       protoTime(a.FirstOutTime),
+		LastOutTime:        protoTime(a.LastOutTime),
+		DelegatedSince:     a.DelegatedSince,
+		DelegatedSinceTime: protoTime(a.DelegatedSinceTime),
+		TotalReceived:      a.TotalReceived,
+		TotalSent:          a.TotalSent,
+		TotalBurned:        a.TotalBurned,
+		TotalFeesPaid:      a.TotalFeesPaid,
+		UnclaimedBalance:   a.UnclaimedBalance,
+		SpendableBalance:   a.SpendableBalance,
+		IsFunded:           a.IsFunded,
+		IsActivated:        a.IsActivated,
+		IsDelegated:        a.IsDelegated,
+		IsRevealed:         a.IsRevealed,
+		IsBaker:            a.IsBaker,
+		IsContract:         a.IsContract,
+		NOps:               int32(a.NOps),
+		NOpsFailed:         int32(a.NOpsFailed),
+		NTx:                int32(a.NTx),
+		NDelegation:        int32(a.NDelegation),
+		NOrigination:       int32(a.NOrigination),
+		NConstants:         int32(a.NConstants),
+		TokenGenMin:        a.TokenGenMin,
+		TokenGenMax:        a.TokenGenMax,
+		LifetimeRewards:    a.LifetimeRewards,
+		PendingRewards:     a.PendingRewards,
+	}
+	if a.Pubkey.IsValid() {
+		p.Pubkey = a.Pubkey.String()
+	}
+	if a.Baker != nil {
+		p.Baker = a.Baker.String()
+	}
+	if a.Creator != nil {
+		p.Creator = a.Creator.String()
+	}
+	return p
+}
+
+func NewAccountFromProto(p *tzstatspb.Account) (*Account, error) {
+	a := &Account{
+		RowId:              p.RowId,
+		AddressType:        tezos.ParseAddressType(p.AddressType),
+		Counter:            p.Counter,
+		BakerId:            p.BakerId,
+		CreatorId:          p.CreatorId,
+		FirstIn:            p.FirstIn,
+		FirstOut:           p.FirstOut,
+		FirstSeen:          p.FirstSeen,
+		LastIn:             p.LastIn,
+		LastOut:            p.LastOut,
+		LastSeen:           p.LastSeen,
+		FirstSeenTime:      fromProtoTime(p.FirstSeenTime),
+		LastSeenTime:       fromProtoTime(p.LastSeenTime),
+		FirstInTime:        fromProtoTime(p.FirstInTime),
+		LastInTime:         fromProtoTime(p.LastInTime),
+		FirstOutTime:       fromProtoTime(p.FirstOutTime),
+		LastOutTime:        fromProtoTime(p.LastOutTime),
+		DelegatedSince:     p.DelegatedSince,
+		DelegatedSinceTime: fromProtoTime(p.DelegatedSinceTime),
+		TotalReceived:      p.TotalReceived,
+		TotalSent:          p.TotalSent,
+		TotalBurned:        p.TotalBurned,
+		TotalFeesPaid:      p.TotalFeesPaid,
+		UnclaimedBalance:   p.UnclaimedBalance,
+		SpendableBalance:   p.SpendableBalance,
+		IsFunded:           p.IsFunded,
+		IsActivated:        p.IsActivated,
+		IsDelegated:        p.IsDelegated,
+		IsRevealed:         p.IsRevealed,
+		IsBaker:            p.IsBaker,
+		IsContract:         p.IsContract,
+		NOps:               int(p.NOps),
+		NOpsFailed:         int(p.NOpsFailed),
+		NTx:                int(p.NTx),
+		NDelegation:        int(p.NDelegation),
+		NOrigination:       int(p.NOrigination),
+		NConstants:         int(p.NConstants),
+		TokenGenMin:        p.TokenGenMin,
+		TokenGenMax:        p.TokenGenMax,
+		LifetimeRewards:    p.LifetimeRewards,
+		PendingRewards:     p.PendingRewards,
+	}
+	var err error
+	if a.Address, err = parseAddressOpt(p.Address); err != nil {
+		return nil, err
+	}
+	if p.Pubkey != "" {
+		if a.Pubkey, err = tezos.ParseKey(p.Pubkey); err != nil {
+			return nil, err
+		}
+	}
+	if p.Baker != "" {
+		addr, err := tezos.ParseAddress(p.Baker)
+		if err != nil {
+			return nil, err
+		}
+		a.Baker = &addr
+	}
+	if p.Creator != "" {
+		addr, err := tezos.ParseAddress(p.Creator)
+		if err != nil {
+			return nil, err
+		}
+		a.Creator = &addr
+	}
+	return a, nil
+}
+
+func (u BigmapUpdate) Proto() *tzstatspb.BigmapUpdate {
+	p := &tzstatspb.BigmapUpdate{
+		Action:            u.Action.String(),
+		BigmapId:          u.BigmapId,
+		SourceBigMap:      u.SourceId,
+		DestinationBigMap: u.DestId,
+		Height:            u.Height,
+		Time:              protoTime(u.Time),
+	}
+	if u.Hash.IsValid() {
+		p.Hash = u.Hash.String()
+	}
+	if u.Key.Len() > 0 {
+		p.Key, _ = json.Marshal(u.Key)
+	}
+	if u.Value != nil {
+		p.Value, _ = json.Marshal(u.Value)
+	}
+	if u.KeyType != nil {
+		p.KeyType, _ = json.Marshal(u.KeyType)
+	}
+	if u.ValueType != nil {
+		p.ValueType, _ = json.Marshal(u.ValueType)
+	}
+	return p
+}
+
+func NewBigmapUpdateFromProto(p *tzstatspb.BigmapUpdate) (*BigmapUpdate, error) {
+	u := &BigmapUpdate{
+		BigmapId: p.BigmapId,
+		SourceId: p.SourceBigMap,
+		DestId:   p.DestinationBigMap,
+	}
+	u.Height = p.Height
+	u.Time = fromProtoTime(p.Time)
+	var err error
+	if u.Action, err = micheline.ParseDiffAction(p.Action); err != nil {
+		return nil, err
+	}
+	if p.Hash != "" {
+		if u.Hash, err = tezos.ParseExprHash(p.Hash); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Key) > 0 {
+		if err := json.Unmarshal(p.Key, &u.Key); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Value) > 0 {
+		if err := json.Unmarshal(p.Value, &u.Value); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.KeyType) > 0 {
+		u.KeyType = &micheline.Typedef{}
+		if err := json.Unmarshal(p.KeyType, u.KeyType); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.ValueType) > 0 {
+		u.ValueType = &micheline.Typedef{}
+		if err := json.Unmarshal(p.ValueType, u.ValueType); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+func protoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromProtoTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
+
+func parseAddressOpt(s string) (tezos.Address, error) {
+	if s == "" {
+		return tezos.Address{}, nil
+	}
+	return tezos.ParseAddress(s)
+}
+
+func parseOpHashOpt(s string) (tezos.OpHash, error) {
+	if s == "" {
+		return tezos.OpHash{}, nil
+	}
+	return tezos.ParseOpHash(s)
+}
+
+func parseBlockHashOpt(s string) (tezos.BlockHash, error) {
+	if s == "" {
+		return tezos.BlockHash{}, nil
+	}
+	return tezos.ParseBlockHash(s)
+}
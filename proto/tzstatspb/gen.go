@@ -0,0 +1,10 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package tzstatspb contains Go types generated from tzstats.proto with
+// protoc-gen-go v1.28.1. Run `go generate` in this directory after changing
+// tzstats.proto. Build the tzstats package with `-tags proto` to enable
+// model converters.
+package tzstatspb
+
+//go:generate protoc -I .. --go_out=. --go_opt=paths=source_relative ../tzstats.proto
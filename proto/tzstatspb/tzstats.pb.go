@@ -0,0 +1,1801 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Protobuf representations of core TzStats models. Field names and
+// semantics follow the JSON API. Amounts are in tez as float, addresses and
+// hashes are base58 strings. Micheline values which have no fixed schema
+// are carried as JSON encoded bytes.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        (unknown)
+// source: tzstats.proto
+
+package tzstatspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Op struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Block         string                 `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	Height        int64                  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	Cycle         int64                  `protobuf:"varint,7,opt,name=cycle,proto3" json:"cycle,omitempty"`
+	Counter       int64                  `protobuf:"varint,8,opt,name=counter,proto3" json:"counter,omitempty"`
+	OpN           int32                  `protobuf:"varint,9,opt,name=op_n,json=opN,proto3" json:"op_n,omitempty"`
+	OpP           int32                  `protobuf:"varint,10,opt,name=op_p,json=opP,proto3" json:"op_p,omitempty"`
+	Status        string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`
+	IsSuccess     bool                   `protobuf:"varint,12,opt,name=is_success,json=isSuccess,proto3" json:"is_success,omitempty"`
+	IsContract    bool                   `protobuf:"varint,13,opt,name=is_contract,json=isContract,proto3" json:"is_contract,omitempty"`
+	IsBatch       bool                   `protobuf:"varint,14,opt,name=is_batch,json=isBatch,proto3" json:"is_batch,omitempty"`
+	IsEvent       bool                   `protobuf:"varint,15,opt,name=is_event,json=isEvent,proto3" json:"is_event,omitempty"`
+	IsInternal    bool                   `protobuf:"varint,16,opt,name=is_internal,json=isInternal,proto3" json:"is_internal,omitempty"`
+	GasLimit      int64                  `protobuf:"varint,17,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	GasUsed       int64                  `protobuf:"varint,18,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	StorageLimit  int64                  `protobuf:"varint,19,opt,name=storage_limit,json=storageLimit,proto3" json:"storage_limit,omitempty"`
+	StoragePaid   int64                  `protobuf:"varint,20,opt,name=storage_paid,json=storagePaid,proto3" json:"storage_paid,omitempty"`
+	Volume        float64                `protobuf:"fixed64,21,opt,name=volume,proto3" json:"volume,omitempty"`
+	Fee           float64                `protobuf:"fixed64,22,opt,name=fee,proto3" json:"fee,omitempty"`
+	Reward        float64                `protobuf:"fixed64,23,opt,name=reward,proto3" json:"reward,omitempty"`
+	Deposit       float64                `protobuf:"fixed64,24,opt,name=deposit,proto3" json:"deposit,omitempty"`
+	Burned        float64                `protobuf:"fixed64,25,opt,name=burned,proto3" json:"burned,omitempty"`
+	DaysDestroyed float64                `protobuf:"fixed64,26,opt,name=days_destroyed,json=daysDestroyed,proto3" json:"days_destroyed,omitempty"`
+	SenderId      uint64                 `protobuf:"varint,27,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    uint64                 `protobuf:"varint,28,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	CreatorId     uint64                 `protobuf:"varint,29,opt,name=creator_id,json=creatorId,proto3" json:"creator_id,omitempty"`
+	BakerId       uint64                 `protobuf:"varint,30,opt,name=baker_id,json=bakerId,proto3" json:"baker_id,omitempty"`
+	Sender        string                 `protobuf:"bytes,31,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver      string                 `protobuf:"bytes,32,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Creator       string                 `protobuf:"bytes,33,opt,name=creator,proto3" json:"creator,omitempty"`
+	Baker         string                 `protobuf:"bytes,34,opt,name=baker,proto3" json:"baker,omitempty"`
+	PreviousBaker string                 `protobuf:"bytes,35,opt,name=previous_baker,json=previousBaker,proto3" json:"previous_baker,omitempty"`
+	Source        string                 `protobuf:"bytes,36,opt,name=source,proto3" json:"source,omitempty"`
+	Offender      string                 `protobuf:"bytes,37,opt,name=offender,proto3" json:"offender,omitempty"`
+	Accuser       string                 `protobuf:"bytes,38,opt,name=accuser,proto3" json:"accuser,omitempty"`
+	Entrypoint    string                 `protobuf:"bytes,39,opt,name=entrypoint,proto3" json:"entrypoint,omitempty"`
+	Data          []byte                 `protobuf:"bytes,40,opt,name=data,proto3" json:"data,omitempty"`             // JSON
+	Errors        []byte                 `protobuf:"bytes,41,opt,name=errors,proto3" json:"errors,omitempty"`         // JSON
+	Parameters    []byte                 `protobuf:"bytes,42,opt,name=parameters,proto3" json:"parameters,omitempty"` // JSON
+	Storage       []byte                 `protobuf:"bytes,43,opt,name=storage,proto3" json:"storage,omitempty"`       // JSON
+	Value         []byte                 `protobuf:"bytes,44,opt,name=value,proto3" json:"value,omitempty"`           // JSON
+	BigMapDiff    []*BigmapUpdate        `protobuf:"bytes,45,rep,name=big_map_diff,json=bigMapDiff,proto3" json:"big_map_diff,omitempty"`
+	Power         int32                  `protobuf:"varint,46,opt,name=power,proto3" json:"power,omitempty"`
+	Limit         *float64               `protobuf:"fixed64,47,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	Confirmations int64                  `protobuf:"varint,48,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	Batch         []*Op                  `protobuf:"bytes,49,rep,name=batch,proto3" json:"batch,omitempty"`
+	Internal      []*Op                  `protobuf:"bytes,50,rep,name=internal,proto3" json:"internal,omitempty"`
+}
+
+func (x *Op) Reset() {
+	*x = Op{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tzstats_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Op) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Op) ProtoMessage() {}
+
+func (x *Op) ProtoReflect() protoreflect.Message {
+	mi := &file_tzstats_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Op.ProtoReflect.Descriptor instead.
+func (*Op) Descriptor() ([]byte, []int) {
+	return file_tzstats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Op) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Op) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Op) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Op) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *Op) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Op) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Op) GetCycle() int64 {
+	if x != nil {
+		return x.Cycle
+	}
+	return 0
+}
+
+func (x *Op) GetCounter() int64 {
+	if x != nil {
+		return x.Counter
+	}
+	return 0
+}
+
+func (x *Op) GetOpN() int32 {
+	if x != nil {
+		return x.OpN
+	}
+	return 0
+}
+
+func (x *Op) GetOpP() int32 {
+	if x != nil {
+		return x.OpP
+	}
+	return 0
+}
+
+func (x *Op) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Op) GetIsSuccess() bool {
+	if x != nil {
+		return x.IsSuccess
+	}
+	return false
+}
+
+func (x *Op) GetIsContract() bool {
+	if x != nil {
+		return x.IsContract
+	}
+	return false
+}
+
+func (x *Op) GetIsBatch() bool {
+	if x != nil {
+		return x.IsBatch
+	}
+	return false
+}
+
+func (x *Op) GetIsEvent() bool {
+	if x != nil {
+		return x.IsEvent
+	}
+	return false
+}
+
+func (x *Op) GetIsInternal() bool {
+	if x != nil {
+		return x.IsInternal
+	}
+	return false
+}
+
+func (x *Op) GetGasLimit() int64 {
+	if x != nil {
+		return x.GasLimit
+	}
+	return 0
+}
+
+func (x *Op) GetGasUsed() int64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *Op) GetStorageLimit() int64 {
+	if x != nil {
+		return x.StorageLimit
+	}
+	return 0
+}
+
+func (x *Op) GetStoragePaid() int64 {
+	if x != nil {
+		return x.StoragePaid
+	}
+	return 0
+}
+
+func (x *Op) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Op) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *Op) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *Op) GetDeposit() float64 {
+	if x != nil {
+		return x.Deposit
+	}
+	return 0
+}
+
+func (x *Op) GetBurned() float64 {
+	if x != nil {
+		return x.Burned
+	}
+	return 0
+}
+
+func (x *Op) GetDaysDestroyed() float64 {
+	if x != nil {
+		return x.DaysDestroyed
+	}
+	return 0
+}
+
+func (x *Op) GetSenderId() uint64 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *Op) GetReceiverId() uint64 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *Op) GetCreatorId() uint64 {
+	if x != nil {
+		return x.CreatorId
+	}
+	return 0
+}
+
+func (x *Op) GetBakerId() uint64 {
+	if x != nil {
+		return x.BakerId
+	}
+	return 0
+}
+
+func (x *Op) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *Op) GetReceiver() string {
+	if x != nil {
+		return x.Receiver
+	}
+	return ""
+}
+
+func (x *Op) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *Op) GetBaker() string {
+	if x != nil {
+		return x.Baker
+	}
+	return ""
+}
+
+func (x *Op) GetPreviousBaker() string {
+	if x != nil {
+		return x.PreviousBaker
+	}
+	return ""
+}
+
+func (x *Op) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Op) GetOffender() string {
+	if x != nil {
+		return x.Offender
+	}
+	return ""
+}
+
+func (x *Op) GetAccuser() string {
+	if x != nil {
+		return x.Accuser
+	}
+	return ""
+}
+
+func (x *Op) GetEntrypoint() string {
+	if x != nil {
+		return x.Entrypoint
+	}
+	return ""
+}
+
+func (x *Op) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Op) GetErrors() []byte {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Op) GetParameters() []byte {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *Op) GetStorage() []byte {
+	if x != nil {
+		return x.Storage
+	}
+	return nil
+}
+
+func (x *Op) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Op) GetBigMapDiff() []*BigmapUpdate {
+	if x != nil {
+		return x.BigMapDiff
+	}
+	return nil
+}
+
+func (x *Op) GetPower() int32 {
+	if x != nil {
+		return x.Power
+	}
+	return 0
+}
+
+func (x *Op) GetLimit() float64 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+func (x *Op) GetConfirmations() int64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *Op) GetBatch() []*Op {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *Op) GetInternal() []*Op {
+	if x != nil {
+		return x.Internal
+	}
+	return nil
+}
+
+type Block struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RowId            uint64                 `protobuf:"varint,1,opt,name=row_id,json=rowId,proto3" json:"row_id,omitempty"`
+	Hash             string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Predecessor      string                 `protobuf:"bytes,3,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	Successor        string                 `protobuf:"bytes,4,opt,name=successor,proto3" json:"successor,omitempty"`
+	Time             *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	Height           int64                  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	Cycle            int64                  `protobuf:"varint,7,opt,name=cycle,proto3" json:"cycle,omitempty"`
+	IsCycleSnapshot  bool                   `protobuf:"varint,8,opt,name=is_cycle_snapshot,json=isCycleSnapshot,proto3" json:"is_cycle_snapshot,omitempty"`
+	Solvetime        int32                  `protobuf:"varint,9,opt,name=solvetime,proto3" json:"solvetime,omitempty"`
+	Version          int32                  `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+	Round            int32                  `protobuf:"varint,11,opt,name=round,proto3" json:"round,omitempty"`
+	Nonce            string                 `protobuf:"bytes,12,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	VotingPeriodKind string                 `protobuf:"bytes,13,opt,name=voting_period_kind,json=votingPeriodKind,proto3" json:"voting_period_kind,omitempty"`
+	BakerId          uint64                 `protobuf:"varint,14,opt,name=baker_id,json=bakerId,proto3" json:"baker_id,omitempty"`
+	Baker            string                 `protobuf:"bytes,15,opt,name=baker,proto3" json:"baker,omitempty"`
+	ProposerId       uint64                 `protobuf:"varint,16,opt,name=proposer_id,json=proposerId,proto3" json:"proposer_id,omitempty"`
+	Proposer         string                 `protobuf:"bytes,17,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	NEndorsedSlots   int32                  `protobuf:"varint,18,opt,name=n_endorsed_slots,json=nEndorsedSlots,proto3" json:"n_endorsed_slots,omitempty"`
+	NOpsApplied      int32                  `protobuf:"varint,19,opt,name=n_ops_applied,json=nOpsApplied,proto3" json:"n_ops_applied,omitempty"`
+	NOpsFailed       int32                  `protobuf:"varint,20,opt,name=n_ops_failed,json=nOpsFailed,proto3" json:"n_ops_failed,omitempty"`
+	NCalls           int32                  `protobuf:"varint,21,opt,name=n_calls,json=nCalls,proto3" json:"n_calls,omitempty"`
+	NEvents          int32                  `protobuf:"varint,22,opt,name=n_events,json=nEvents,proto3" json:"n_events,omitempty"`
+	Volume           float64                `protobuf:"fixed64,23,opt,name=volume,proto3" json:"volume,omitempty"`
+	Fee              float64                `protobuf:"fixed64,24,opt,name=fee,proto3" json:"fee,omitempty"`
+	Reward           float64                `protobuf:"fixed64,25,opt,name=reward,proto3" json:"reward,omitempty"`
+	Deposit          float64                `protobuf:"fixed64,26,opt,name=deposit,proto3" json:"deposit,omitempty"`
+	ActivatedSupply  float64                `protobuf:"fixed64,27,opt,name=activated_supply,json=activatedSupply,proto3" json:"activated_supply,omitempty"`
+	MintedSupply     float64                `protobuf:"fixed64,28,opt,name=minted_supply,json=mintedSupply,proto3" json:"minted_supply,omitempty"`
+	BurnedSupply     float64                `protobuf:"fixed64,29,opt,name=burned_supply,json=burnedSupply,proto3" json:"burned_supply,omitempty"`
+	NAccounts        int32                  `protobuf:"varint,30,opt,name=n_accounts,json=nAccounts,proto3" json:"n_accounts,omitempty"`
+	NNewAccounts     int32                  `protobuf:"varint,31,opt,name=n_new_accounts,json=nNewAccounts,proto3" json:"n_new_accounts,omitempty"`
+	NNewContracts    int32                  `protobuf:"varint,32,opt,name=n_new_contracts,json=nNewContracts,proto3" json:"n_new_contracts,omitempty"`
+	NClearedAccounts int32                  `protobuf:"varint,33,opt,name=n_cleared_accounts,json=nClearedAccounts,proto3" json:"n_cleared_accounts,omitempty"`
+	NFundedAccounts  int32                  `protobuf:"varint,34,opt,name=n_funded_accounts,json=nFundedAccounts,proto3" json:"n_funded_accounts,omitempty"`
+	GasLimit         int64                  `protobuf:"varint,35,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	GasUsed          int64                  `protobuf:"varint,36,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	StoragePaid      int64                  `protobuf:"varint,37,opt,name=storage_paid,json=storagePaid,proto3" json:"storage_paid,omitempty"`
+	PctAccountReuse  float64                `protobuf:"fixed64,38,opt,name=pct_account_reuse,json=pctAccountReuse,proto3" json:"pct_account_reuse,omitempty"`
+	LbEscVote        bool                   `protobuf:"varint,39,opt,name=lb_esc_vote,json=lbEscVote,proto3" json:"lb_esc_vote,omitempty"`
+	LbEscEma         int64                  `protobuf:"varint,40,opt,name=lb_esc_ema,json=lbEscEma,proto3" json:"lb_esc_ema,omitempty"`
+	Protocol         string                 `protobuf:"bytes,41,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Ops              []*Op                  `protobuf:"bytes,42,rep,name=ops,proto3" json:"ops,omitempty"`
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tzstats_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_tzstats_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_tzstats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Block) GetRowId() uint64 {
+	if x != nil {
+		return x.RowId
+	}
+	return 0
+}
+
+func (x *Block) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Block) GetPredecessor() string {
+	if x != nil {
+		return x.Predecessor
+	}
+	return ""
+}
+
+func (x *Block) GetSuccessor() string {
+	if x != nil {
+		return x.Successor
+	}
+	return ""
+}
+
+func (x *Block) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Block) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Block) GetCycle() int64 {
+	if x != nil {
+		return x.Cycle
+	}
+	return 0
+}
+
+func (x *Block) GetIsCycleSnapshot() bool {
+	if x != nil {
+		return x.IsCycleSnapshot
+	}
+	return false
+}
+
+func (x *Block) GetSolvetime() int32 {
+	if x != nil {
+		return x.Solvetime
+	}
+	return 0
+}
+
+func (x *Block) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Block) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Block) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *Block) GetVotingPeriodKind() string {
+	if x != nil {
+		return x.VotingPeriodKind
+	}
+	return ""
+}
+
+func (x *Block) GetBakerId() uint64 {
+	if x != nil {
+		return x.BakerId
+	}
+	return 0
+}
+
+func (x *Block) GetBaker() string {
+	if x != nil {
+		return x.Baker
+	}
+	return ""
+}
+
+func (x *Block) GetProposerId() uint64 {
+	if x != nil {
+		return x.ProposerId
+	}
+	return 0
+}
+
+func (x *Block) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *Block) GetNEndorsedSlots() int32 {
+	if x != nil {
+		return x.NEndorsedSlots
+	}
+	return 0
+}
+
+func (x *Block) GetNOpsApplied() int32 {
+	if x != nil {
+		return x.NOpsApplied
+	}
+	return 0
+}
+
+func (x *Block) GetNOpsFailed() int32 {
+	if x != nil {
+		return x.NOpsFailed
+	}
+	return 0
+}
+
+func (x *Block) GetNCalls() int32 {
+	if x != nil {
+		return x.NCalls
+	}
+	return 0
+}
+
+func (x *Block) GetNEvents() int32 {
+	if x != nil {
+		return x.NEvents
+	}
+	return 0
+}
+
+func (x *Block) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Block) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *Block) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *Block) GetDeposit() float64 {
+	if x != nil {
+		return x.Deposit
+	}
+	return 0
+}
+
+func (x *Block) GetActivatedSupply() float64 {
+	if x != nil {
+		return x.ActivatedSupply
+	}
+	return 0
+}
+
+func (x *Block) GetMintedSupply() float64 {
+	if x != nil {
+		return x.MintedSupply
+	}
+	return 0
+}
+
+func (x *Block) GetBurnedSupply() float64 {
+	if x != nil {
+		return x.BurnedSupply
+	}
+	return 0
+}
+
+func (x *Block) GetNAccounts() int32 {
+	if x != nil {
+		return x.NAccounts
+	}
+	return 0
+}
+
+func (x *Block) GetNNewAccounts() int32 {
+	if x != nil {
+		return x.NNewAccounts
+	}
+	return 0
+}
+
+func (x *Block) GetNNewContracts() int32 {
+	if x != nil {
+		return x.NNewContracts
+	}
+	return 0
+}
+
+func (x *Block) GetNClearedAccounts() int32 {
+	if x != nil {
+		return x.NClearedAccounts
+	}
+	return 0
+}
+
+func (x *Block) GetNFundedAccounts() int32 {
+	if x != nil {
+		return x.NFundedAccounts
+	}
+	return 0
+}
+
+func (x *Block) GetGasLimit() int64 {
+	if x != nil {
+		return x.GasLimit
+	}
+	return 0
+}
+
+func (x *Block) GetGasUsed() int64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *Block) GetStoragePaid() int64 {
+	if x != nil {
+		return x.StoragePaid
+	}
+	return 0
+}
+
+func (x *Block) GetPctAccountReuse() float64 {
+	if x != nil {
+		return x.PctAccountReuse
+	}
+	return 0
+}
+
+func (x *Block) GetLbEscVote() bool {
+	if x != nil {
+		return x.LbEscVote
+	}
+	return false
+}
+
+func (x *Block) GetLbEscEma() int64 {
+	if x != nil {
+		return x.LbEscEma
+	}
+	return 0
+}
+
+func (x *Block) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Block) GetOps() []*Op {
+	if x != nil {
+		return x.Ops
+	}
+	return nil
+}
+
+type Account struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RowId              uint64                 `protobuf:"varint,1,opt,name=row_id,json=rowId,proto3" json:"row_id,omitempty"`
+	Address            string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	AddressType        string                 `protobuf:"bytes,3,opt,name=address_type,json=addressType,proto3" json:"address_type,omitempty"`
+	Pubkey             string                 `protobuf:"bytes,4,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+	Counter            int64                  `protobuf:"varint,5,opt,name=counter,proto3" json:"counter,omitempty"`
+	BakerId            uint64                 `protobuf:"varint,6,opt,name=baker_id,json=bakerId,proto3" json:"baker_id,omitempty"`
+	Baker              string                 `protobuf:"bytes,7,opt,name=baker,proto3" json:"baker,omitempty"`
+	CreatorId          uint64                 `protobuf:"varint,8,opt,name=creator_id,json=creatorId,proto3" json:"creator_id,omitempty"`
+	Creator            string                 `protobuf:"bytes,9,opt,name=creator,proto3" json:"creator,omitempty"`
+	FirstIn            int64                  `protobuf:"varint,10,opt,name=first_in,json=firstIn,proto3" json:"first_in,omitempty"`
+	FirstOut           int64                  `protobuf:"varint,11,opt,name=first_out,json=firstOut,proto3" json:"first_out,omitempty"`
+	FirstSeen          int64                  `protobuf:"varint,12,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastIn             int64                  `protobuf:"varint,13,opt,name=last_in,json=lastIn,proto3" json:"last_in,omitempty"`
+	LastOut            int64                  `protobuf:"varint,14,opt,name=last_out,json=lastOut,proto3" json:"last_out,omitempty"`
+	LastSeen           int64                  `protobuf:"varint,15,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	FirstSeenTime      *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=first_seen_time,json=firstSeenTime,proto3" json:"first_seen_time,omitempty"`
+	LastSeenTime       *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=last_seen_time,json=lastSeenTime,proto3" json:"last_seen_time,omitempty"`
+	FirstInTime        *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=first_in_time,json=firstInTime,proto3" json:"first_in_time,omitempty"`
+	LastInTime         *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=last_in_time,json=lastInTime,proto3" json:"last_in_time,omitempty"`
+	FirstOutTime       *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=first_out_time,json=firstOutTime,proto3" json:"first_out_time,omitempty"`
+	LastOutTime        *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=last_out_time,json=lastOutTime,proto3" json:"last_out_time,omitempty"`
+	DelegatedSince     int64                  `protobuf:"varint,22,opt,name=delegated_since,json=delegatedSince,proto3" json:"delegated_since,omitempty"`
+	DelegatedSinceTime *timestamppb.Timestamp `protobuf:"bytes,23,opt,name=delegated_since_time,json=delegatedSinceTime,proto3" json:"delegated_since_time,omitempty"`
+	TotalReceived      float64                `protobuf:"fixed64,24,opt,name=total_received,json=totalReceived,proto3" json:"total_received,omitempty"`
+	TotalSent          float64                `protobuf:"fixed64,25,opt,name=total_sent,json=totalSent,proto3" json:"total_sent,omitempty"`
+	TotalBurned        float64                `protobuf:"fixed64,26,opt,name=total_burned,json=totalBurned,proto3" json:"total_burned,omitempty"`
+	TotalFeesPaid      float64                `protobuf:"fixed64,27,opt,name=total_fees_paid,json=totalFeesPaid,proto3" json:"total_fees_paid,omitempty"`
+	UnclaimedBalance   float64                `protobuf:"fixed64,28,opt,name=unclaimed_balance,json=unclaimedBalance,proto3" json:"unclaimed_balance,omitempty"`
+	SpendableBalance   float64                `protobuf:"fixed64,29,opt,name=spendable_balance,json=spendableBalance,proto3" json:"spendable_balance,omitempty"`
+	IsFunded           bool                   `protobuf:"varint,30,opt,name=is_funded,json=isFunded,proto3" json:"is_funded,omitempty"`
+	IsActivated        bool                   `protobuf:"varint,31,opt,name=is_activated,json=isActivated,proto3" json:"is_activated,omitempty"`
+	IsDelegated        bool                   `protobuf:"varint,32,opt,name=is_delegated,json=isDelegated,proto3" json:"is_delegated,omitempty"`
+	IsRevealed         bool                   `protobuf:"varint,33,opt,name=is_revealed,json=isRevealed,proto3" json:"is_revealed,omitempty"`
+	IsBaker            bool                   `protobuf:"varint,34,opt,name=is_baker,json=isBaker,proto3" json:"is_baker,omitempty"`
+	IsContract         bool                   `protobuf:"varint,35,opt,name=is_contract,json=isContract,proto3" json:"is_contract,omitempty"`
+	NOps               int32                  `protobuf:"varint,36,opt,name=n_ops,json=nOps,proto3" json:"n_ops,omitempty"`
+	NOpsFailed         int32                  `protobuf:"varint,37,opt,name=n_ops_failed,json=nOpsFailed,proto3" json:"n_ops_failed,omitempty"`
+	NTx                int32                  `protobuf:"varint,38,opt,name=n_tx,json=nTx,proto3" json:"n_tx,omitempty"`
+	NDelegation        int32                  `protobuf:"varint,39,opt,name=n_delegation,json=nDelegation,proto3" json:"n_delegation,omitempty"`
+	NOrigination       int32                  `protobuf:"varint,40,opt,name=n_origination,json=nOrigination,proto3" json:"n_origination,omitempty"`
+	NConstants         int32                  `protobuf:"varint,41,opt,name=n_constants,json=nConstants,proto3" json:"n_constants,omitempty"`
+	TokenGenMin        int64                  `protobuf:"varint,42,opt,name=token_gen_min,json=tokenGenMin,proto3" json:"token_gen_min,omitempty"`
+	TokenGenMax        int64                  `protobuf:"varint,43,opt,name=token_gen_max,json=tokenGenMax,proto3" json:"token_gen_max,omitempty"`
+	LifetimeRewards    float64                `protobuf:"fixed64,44,opt,name=lifetime_rewards,json=lifetimeRewards,proto3" json:"lifetime_rewards,omitempty"`
+	PendingRewards     float64                `protobuf:"fixed64,45,opt,name=pending_rewards,json=pendingRewards,proto3" json:"pending_rewards,omitempty"`
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tzstats_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_tzstats_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_tzstats_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Account) GetRowId() uint64 {
+	if x != nil {
+		return x.RowId
+	}
+	return 0
+}
+
+func (x *Account) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Account) GetAddressType() string {
+	if x != nil {
+		return x.AddressType
+	}
+	return ""
+}
+
+func (x *Account) GetPubkey() string {
+	if x != nil {
+		return x.Pubkey
+	}
+	return ""
+}
+
+func (x *Account) GetCounter() int64 {
+	if x != nil {
+		return x.Counter
+	}
+	return 0
+}
+
+func (x *Account) GetBakerId() uint64 {
+	if x != nil {
+		return x.BakerId
+	}
+	return 0
+}
+
+func (x *Account) GetBaker() string {
+	if x != nil {
+		return x.Baker
+	}
+	return ""
+}
+
+func (x *Account) GetCreatorId() uint64 {
+	if x != nil {
+		return x.CreatorId
+	}
+	return 0
+}
+
+func (x *Account) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *Account) GetFirstIn() int64 {
+	if x != nil {
+		return x.FirstIn
+	}
+	return 0
+}
+
+func (x *Account) GetFirstOut() int64 {
+	if x != nil {
+		return x.FirstOut
+	}
+	return 0
+}
+
+func (x *Account) GetFirstSeen() int64 {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return 0
+}
+
+func (x *Account) GetLastIn() int64 {
+	if x != nil {
+		return x.LastIn
+	}
+	return 0
+}
+
+func (x *Account) GetLastOut() int64 {
+	if x != nil {
+		return x.LastOut
+	}
+	return 0
+}
+
+func (x *Account) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+func (x *Account) GetFirstSeenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeenTime
+	}
+	return nil
+}
+
+func (x *Account) GetLastSeenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeenTime
+	}
+	return nil
+}
+
+func (x *Account) GetFirstInTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstInTime
+	}
+	return nil
+}
+
+func (x *Account) GetLastInTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastInTime
+	}
+	return nil
+}
+
+func (x *Account) GetFirstOutTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstOutTime
+	}
+	return nil
+}
+
+func (x *Account) GetLastOutTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastOutTime
+	}
+	return nil
+}
+
+func (x *Account) GetDelegatedSince() int64 {
+	if x != nil {
+		return x.DelegatedSince
+	}
+	return 0
+}
+
+func (x *Account) GetDelegatedSinceTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DelegatedSinceTime
+	}
+	return nil
+}
+
+func (x *Account) GetTotalReceived() float64 {
+	if x != nil {
+		return x.TotalReceived
+	}
+	return 0
+}
+
+func (x *Account) GetTotalSent() float64 {
+	if x != nil {
+		return x.TotalSent
+	}
+	return 0
+}
+
+func (x *Account) GetTotalBurned() float64 {
+	if x != nil {
+		return x.TotalBurned
+	}
+	return 0
+}
+
+func (x *Account) GetTotalFeesPaid() float64 {
+	if x != nil {
+		return x.TotalFeesPaid
+	}
+	return 0
+}
+
+func (x *Account) GetUnclaimedBalance() float64 {
+	if x != nil {
+		return x.UnclaimedBalance
+	}
+	return 0
+}
+
+func (x *Account) GetSpendableBalance() float64 {
+	if x != nil {
+		return x.SpendableBalance
+	}
+	return 0
+}
+
+func (x *Account) GetIsFunded() bool {
+	if x != nil {
+		return x.IsFunded
+	}
+	return false
+}
+
+func (x *Account) GetIsActivated() bool {
+	if x != nil {
+		return x.IsActivated
+	}
+	return false
+}
+
+func (x *Account) GetIsDelegated() bool {
+	if x != nil {
+		return x.IsDelegated
+	}
+	return false
+}
+
+func (x *Account) GetIsRevealed() bool {
+	if x != nil {
+		return x.IsRevealed
+	}
+	return false
+}
+
+func (x *Account) GetIsBaker() bool {
+	if x != nil {
+		return x.IsBaker
+	}
+	return false
+}
+
+func (x *Account) GetIsContract() bool {
+	if x != nil {
+		return x.IsContract
+	}
+	return false
+}
+
+func (x *Account) GetNOps() int32 {
+	if x != nil {
+		return x.NOps
+	}
+	return 0
+}
+
+func (x *Account) GetNOpsFailed() int32 {
+	if x != nil {
+		return x.NOpsFailed
+	}
+	return 0
+}
+
+func (x *Account) GetNTx() int32 {
+	if x != nil {
+		return x.NTx
+	}
+	return 0
+}
+
+func (x *Account) GetNDelegation() int32 {
+	if x != nil {
+		return x.NDelegation
+	}
+	return 0
+}
+
+func (x *Account) GetNOrigination() int32 {
+	if x != nil {
+		return x.NOrigination
+	}
+	return 0
+}
+
+func (x *Account) GetNConstants() int32 {
+	if x != nil {
+		return x.NConstants
+	}
+	return 0
+}
+
+func (x *Account) GetTokenGenMin() int64 {
+	if x != nil {
+		return x.TokenGenMin
+	}
+	return 0
+}
+
+func (x *Account) GetTokenGenMax() int64 {
+	if x != nil {
+		return x.TokenGenMax
+	}
+	return 0
+}
+
+func (x *Account) GetLifetimeRewards() float64 {
+	if x != nil {
+		return x.LifetimeRewards
+	}
+	return 0
+}
+
+func (x *Account) GetPendingRewards() float64 {
+	if x != nil {
+		return x.PendingRewards
+	}
+	return 0
+}
+
+type BigmapUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Action            string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	BigmapId          int64                  `protobuf:"varint,2,opt,name=bigmap_id,json=bigmapId,proto3" json:"bigmap_id,omitempty"`
+	SourceBigMap      int64                  `protobuf:"varint,3,opt,name=source_big_map,json=sourceBigMap,proto3" json:"source_big_map,omitempty"`
+	DestinationBigMap int64                  `protobuf:"varint,4,opt,name=destination_big_map,json=destinationBigMap,proto3" json:"destination_big_map,omitempty"`
+	Hash              string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	Key               []byte                 `protobuf:"bytes,6,opt,name=key,proto3" json:"key,omitempty"`                              // JSON
+	Value             []byte                 `protobuf:"bytes,7,opt,name=value,proto3" json:"value,omitempty"`                          // JSON
+	KeyType           []byte                 `protobuf:"bytes,8,opt,name=key_type,json=keyType,proto3" json:"key_type,omitempty"`       // JSON
+	ValueType         []byte                 `protobuf:"bytes,9,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"` // JSON
+	Height            int64                  `protobuf:"varint,10,opt,name=height,proto3" json:"height,omitempty"`
+	Time              *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *BigmapUpdate) Reset() {
+	*x = BigmapUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tzstats_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BigmapUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BigmapUpdate) ProtoMessage() {}
+
+func (x *BigmapUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_tzstats_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BigmapUpdate.ProtoReflect.Descriptor instead.
+func (*BigmapUpdate) Descriptor() ([]byte, []int) {
+	return file_tzstats_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BigmapUpdate) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *BigmapUpdate) GetBigmapId() int64 {
+	if x != nil {
+		return x.BigmapId
+	}
+	return 0
+}
+
+func (x *BigmapUpdate) GetSourceBigMap() int64 {
+	if x != nil {
+		return x.SourceBigMap
+	}
+	return 0
+}
+
+func (x *BigmapUpdate) GetDestinationBigMap() int64 {
+	if x != nil {
+		return x.DestinationBigMap
+	}
+	return 0
+}
+
+func (x *BigmapUpdate) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *BigmapUpdate) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *BigmapUpdate) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *BigmapUpdate) GetKeyType() []byte {
+	if x != nil {
+		return x.KeyType
+	}
+	return nil
+}
+
+func (x *BigmapUpdate) GetValueType() []byte {
+	if x != nil {
+		return x.ValueType
+	}
+	return nil
+}
+
+func (x *BigmapUpdate) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BigmapUpdate) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_tzstats_proto protoreflect.FileDescriptor
+
+var file_tzstats_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x74, 0x7a, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0a, 0x74, 0x7a, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x96, 0x0b, 0x0a,
+	0x02, 0x4f, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x79, 0x63,
+	0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x11, 0x0a, 0x04, 0x6f, 0x70, 0x5f,
+	0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6f, 0x70, 0x4e, 0x12, 0x11, 0x0a, 0x04,
+	0x6f, 0x70, 0x5f, 0x70, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6f, 0x70, 0x50, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x73, 0x75,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x53,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x63, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x43,
+	0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x62, 0x61,
+	0x74, 0x63, 0x68, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x69, 0x73, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x10, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x1b,
+	0x0a, 0x09, 0x67, 0x61, 0x73, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x67, 0x61, 0x73, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x67,
+	0x61, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x67,
+	0x61, 0x73, 0x55, 0x73, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x70, 0x61, 0x69, 0x64, 0x18, 0x14, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x61, 0x69, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18, 0x16, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61,
+	0x72, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x07, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75,
+	0x72, 0x6e, 0x65, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x75, 0x72, 0x6e,
+	0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x61, 0x79, 0x73, 0x5f, 0x64, 0x65, 0x73, 0x74, 0x72,
+	0x6f, 0x79, 0x65, 0x64, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x61, 0x79, 0x73,
+	0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x6f, 0x72, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x1f, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x18, 0x20, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x21, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x22, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x62, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
+	0x73, 0x5f, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x23, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x24, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x66, 0x66, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x18, 0x25, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x66, 0x66, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x18, 0x26, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e,
+	0x74, 0x72, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x27, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x65, 0x6e, 0x74, 0x72, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x28, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16,
+	0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x29, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x2a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61,
+	0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x2c, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x3a, 0x0a, 0x0c, 0x62, 0x69, 0x67, 0x5f, 0x6d, 0x61,
+	0x70, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x18, 0x2d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74,
+	0x7a, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x69, 0x67, 0x6d, 0x61, 0x70,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x62, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x44, 0x69,
+	0x66, 0x66, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x18, 0x2e, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x2f, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x30, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x24, 0x0a, 0x05, 0x62, 0x61, 0x74,
+	0x63, 0x68, 0x18, 0x31, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x7a, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x52, 0x05, 0x62, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x2a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x32, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x7a, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x70, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x42, 0x08, 0x0a, 0x06, 0x5f,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xbb, 0x0a, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x15, 0x0a, 0x06, 0x72, 0x6f, 0x77, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x72, 0x6f, 0x77, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72,
+	0x65, 0x64, 0x65, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x70, 0x72, 0x65, 0x64, 0x65, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x63,
+	0x79, 0x63, 0x6c, 0x65, 0x5f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x43, 0x79, 0x63, 0x6c, 0x65, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x74, 0x69,
+	0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x76, 0x6f, 0x74, 0x69,
+	0x6e, 0x67, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x5f, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x65, 0x72, 0x69,
+	0x6f, 0x64, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x70, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x10, 0x6e, 0x5f, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x73,
+	0x65, 0x64, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e,
+	0x6e, 0x45, 0x6e, 0x64, 0x6f, 0x72, 0x73, 0x65, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x22,
+	0x0a, 0x0d, 0x6e, 0x5f, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6e, 0x4f, 0x70, 0x73, 0x41, 0x70, 0x70, 0x6c, 0x69,
+	0x65, 0x64, 0x12, 0x20, 0x0a, 0x0c, 0x6e, 0x5f, 0x6f, 0x70, 0x73, 0x5f, 0x66, 0x61, 0x69, 0x6c,
+	0x65, 0x64, 0x18, 0x14, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6e, 0x4f, 0x70, 0x73, 0x46, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x18,
+	0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x43, 0x61, 0x6c, 0x6c, 0x73, 0x12, 0x19, 0x0a,
+	0x08, 0x6e, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x07, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x66,
+	0x65, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x19, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x64, 0x65, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f,
+	0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x12,
+	0x23, 0x0a, 0x0d, 0x6d, 0x69, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79,
+	0x18, 0x1c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x6d, 0x69, 0x6e, 0x74, 0x65, 0x64, 0x53, 0x75,
+	0x70, 0x70, 0x6c, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x5f, 0x73,
+	0x75, 0x70, 0x70, 0x6c, 0x79, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x62, 0x75, 0x72,
+	0x6e, 0x65, 0x64, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x5f, 0x61,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6e,
+	0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x6e, 0x5f, 0x6e, 0x65,
+	0x77, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0c, 0x6e, 0x4e, 0x65, 0x77, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x26,
+	0x0a, 0x0f, 0x6e, 0x5f, 0x6e, 0x65, 0x77, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74,
+	0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6e, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6e, 0x5f, 0x63, 0x6c, 0x65, 0x61,
+	0x72, 0x65, 0x64, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x21, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x10, 0x6e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6e, 0x5f, 0x66, 0x75, 0x6e, 0x64, 0x65, 0x64,
+	0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x22, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0f, 0x6e, 0x46, 0x75, 0x6e, 0x64, 0x65, 0x64, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x67, 0x61, 0x73, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x23, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x67, 0x61, 0x73, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x19, 0x0a,
+	0x08, 0x67, 0x61, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x24, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x67, 0x61, 0x73, 0x55, 0x73, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x5f, 0x70, 0x61, 0x69, 0x64, 0x18, 0x25, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x61, 0x69, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x70,
+	0x63, 0x74, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x72, 0x65, 0x75, 0x73, 0x65,
+	0x18, 0x26, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x70, 0x63, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x52, 0x65, 0x75, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x6c, 0x62, 0x5f, 0x65, 0x73,
+	0x63, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x18, 0x27, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6c, 0x62,
+	0x45, 0x73, 0x63, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x0a, 0x6c, 0x62, 0x5f, 0x65, 0x73,
+	0x63, 0x5f, 0x65, 0x6d, 0x61, 0x18, 0x28, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x62, 0x45,
+	0x73, 0x63, 0x45, 0x6d, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x18, 0x29, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x12, 0x20, 0x0a, 0x03, 0x6f, 0x70, 0x73, 0x18, 0x2a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x74, 0x7a, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x52, 0x03,
+	0x6f, 0x70, 0x73, 0x22, 0x98, 0x0d, 0x0a, 0x07, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x15, 0x0a, 0x06, 0x72, 0x6f, 0x77, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x72, 0x6f, 0x77, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x5f, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x49, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x6f, 0x72, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12,
+	0x19, 0x0a, 0x08, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x66, 0x69, 0x72, 0x73, 0x74, 0x49, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66,
+	0x69, 0x72, 0x73, 0x74, 0x4f, 0x75, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69,
+	0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x12,
+	0x19, 0x0a, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x6c, 0x61, 0x73, 0x74, 0x4f, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c,
+	0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x42, 0x0a, 0x0f, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x5f, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x40, 0x0a, 0x0e, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0c, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3e, 0x0a,
+	0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x12,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x49, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3c, 0x0a,
+	0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0a, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x40, 0x0a, 0x0e, 0x66,
+	0x69, 0x72, 0x73, 0x74, 0x5f, 0x6f, 0x75, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x14, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0c, 0x66, 0x69, 0x72, 0x73, 0x74, 0x4f, 0x75, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3e, 0x0a,
+	0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6f, 0x75, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x15,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x4f, 0x75, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a,
+	0x0f, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65,
+	0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x65,
+	0x64, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x4c, 0x0a, 0x14, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x17,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x12, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x53, 0x69, 0x6e, 0x63, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x18, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x5f, 0x62, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x12, 0x26, 0x0a,
+	0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x66, 0x65, 0x65, 0x73, 0x5f, 0x70, 0x61, 0x69, 0x64,
+	0x18, 0x1b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x46, 0x65, 0x65,
+	0x73, 0x50, 0x61, 0x69, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x75, 0x6e, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x65, 0x64, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x10, 0x75, 0x6e, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x70, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x5f,
+	0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x73,
+	0x70, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x66, 0x75, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x1e, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x46, 0x75, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x69, 0x73, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x18, 0x1f, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x69, 0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x18,
+	0x20, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74,
+	0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x65, 0x61, 0x6c, 0x65,
+	0x64, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x76, 0x65, 0x61,
+	0x6c, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x62, 0x61, 0x6b, 0x65, 0x72, 0x18,
+	0x22, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x42, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x1f,
+	0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x18, 0x23, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12,
+	0x13, 0x0a, 0x05, 0x6e, 0x5f, 0x6f, 0x70, 0x73, 0x18, 0x24, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x6e, 0x4f, 0x70, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x6e, 0x5f, 0x6f, 0x70, 0x73, 0x5f, 0x66, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x18, 0x25, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6e, 0x4f, 0x70, 0x73,
+	0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x11, 0x0a, 0x04, 0x6e, 0x5f, 0x74, 0x78, 0x18, 0x26,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6e, 0x54, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x5f, 0x64,
+	0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x27, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d,
+	0x6e, 0x5f, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x28, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0c, 0x6e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x73,
+	0x18, 0x29, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6e, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x74, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x67, 0x65, 0x6e, 0x5f,
+	0x6d, 0x69, 0x6e, 0x18, 0x2a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x47, 0x65, 0x6e, 0x4d, 0x69, 0x6e, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f,
+	0x67, 0x65, 0x6e, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x47, 0x65, 0x6e, 0x4d, 0x61, 0x78, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x69,
+	0x66, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x2c,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6c, 0x69, 0x66, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65,
+	0x77, 0x61, 0x72, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
+	0x5f, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x2d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e,
+	0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x22, 0xd7,
+	0x02, 0x0a, 0x0c, 0x42, 0x69, 0x67, 0x6d, 0x61, 0x70, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x69, 0x67, 0x6d, 0x61,
+	0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x62, 0x69, 0x67, 0x6d,
+	0x61, 0x70, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x62,
+	0x69, 0x67, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x42, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65,
+	0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x69, 0x67, 0x5f, 0x6d, 0x61,
+	0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x42, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x2a, 0x5a, 0x28, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x63, 0x63, 0x2f, 0x74, 0x7a, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x74, 0x7a, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tzstats_proto_rawDescOnce sync.Once
+	file_tzstats_proto_rawDescData = file_tzstats_proto_rawDesc
+)
+
+func file_tzstats_proto_rawDescGZIP() []byte {
+	file_tzstats_proto_rawDescOnce.Do(func() {
+		file_tzstats_proto_rawDescData = protoimpl.X.CompressGZIP(file_tzstats_proto_rawDescData)
+	})
+	return file_tzstats_proto_rawDescData
+}
+
+var file_tzstats_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_tzstats_proto_goTypes = []interface{}{
+	(*Op)(nil),                    // 0: tzstats.v1.Op
+	(*Block)(nil),                 // 1: tzstats.v1.Block
+	(*Account)(nil),               // 2: tzstats.v1.Account
+	(*BigmapUpdate)(nil),          // 3: tzstats.v1.BigmapUpdate
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_tzstats_proto_depIdxs = []int32{
+	4,  // 0: tzstats.v1.Op.time:type_name -> google.protobuf.Timestamp
+	3,  // 1: tzstats.v1.Op.big_map_diff:type_name -> tzstats.v1.BigmapUpdate
+	0,  // 2: tzstats.v1.Op.batch:type_name -> tzstats.v1.Op
+	0,  // 3: tzstats.v1.Op.internal:type_name -> tzstats.v1.Op
+	4,  // 4: tzstats.v1.Block.time:type_name -> google.protobuf.Timestamp
+	0,  // 5: tzstats.v1.Block.ops:type_name -> tzstats.v1.Op
+	4,  // 6: tzstats.v1.Account.first_seen_time:type_name -> google.protobuf.Timestamp
+	4,  // 7: tzstats.v1.Account.last_seen_time:type_name -> google.protobuf.Timestamp
+	4,  // 8: tzstats.v1.Account.first_in_time:type_name -> google.protobuf.Timestamp
+	4,  // 9: tzstats.v1.Account.last_in_time:type_name -> google.protobuf.Timestamp
+	4,  // 10: tzstats.v1.Account.first_out_time:type_name -> google.protobuf.Timestamp
+	4,  // 11: tzstats.v1.Account.last_out_time:type_name -> google.protobuf.Timestamp
+	4,  // 12: tzstats.v1.Account.delegated_since_time:type_name -> google.protobuf.Timestamp
+	4,  // 13: tzstats.v1.BigmapUpdate.time:type_name -> google.protobuf.Timestamp
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_tzstats_proto_init() }
+func file_tzstats_proto_init() {
+	if File_tzstats_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_tzstats_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Op); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tzstats_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Block); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tzstats_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Account); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tzstats_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BigmapUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_tzstats_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tzstats_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tzstats_proto_goTypes,
+		DependencyIndexes: file_tzstats_proto_depIdxs,
+		MessageInfos:      file_tzstats_proto_msgTypes,
+	}.Build()
+	File_tzstats_proto = out.File
+	file_tzstats_proto_rawDesc = nil
+	file_tzstats_proto_goTypes = nil
+	file_tzstats_proto_depIdxs = nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Publisher sends messages to a topic on a message broker. Publish must only
+// return after the broker has acknowledged the message.
+//
+// Adapters for NATS JetStream and Kafka are available with build tags
+// `nats` and `kafka`.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+	Close() error
+}
+
+var (
+	DefaultOpTopic    = "tzstats.ops"
+	DefaultBlockTopic = "tzstats.blocks"
+)
+
+// PublishSink is a Sink that encodes ops and blocks as JSON and publishes
+// them to a message broker. Combined with a Follower and a persistent
+// CheckpointStore it provides at-least-once delivery. Consumers should
+// deduplicate on the message key (op id or block hash).
+type PublishSink struct {
+	pub        Publisher
+	OpTopic    string
+	BlockTopic string
+}
+
+func NewPublishSink(pub Publisher) *PublishSink {
+	return &PublishSink{
+		pub:        pub,
+		OpTopic:    DefaultOpTopic,
+		BlockTopic: DefaultBlockTopic,
+	}
+}
+
+func (s *PublishSink) WriteOps(ctx context.Context, ops []*Op) error {
+	for _, o := range ops {
+		buf, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if err := s.pub.Publish(ctx, s.OpTopic, []byte(ToString(o.Id)), buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PublishSink) WriteBlocks(ctx context.Context, blocks []*Block) error {
+	for _, b := range blocks {
+		buf, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		if err := s.pub.Publish(ctx, s.BlockTopic, []byte(b.Hash.String()), buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush is a no-op because Publish waits for broker acknowledgement.
+func (s *PublishSink) Flush(_ context.Context) error {
+	return nil
+}
+
+func (s *PublishSink) Close() error {
+	return s.pub.Close()
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build kafka
+// +build kafka
+
+package tzstats
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher publishes to Kafka. The writer must not have a default
+// topic set and should use RequiredAcks = kafka.RequireAll for
+// at-least-once delivery.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+func NewKafkaPublisher(w *kafka.Writer) *KafkaPublisher {
+	return &KafkaPublisher{w: w}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return p.w.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
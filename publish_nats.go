@@ -0,0 +1,43 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build nats
+// +build nats
+
+package tzstats
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NatsPublisher publishes to NATS JetStream and waits for stream
+// acknowledgements. The message key is sent as Nats-Msg-Id header so that
+// JetStream can drop duplicates within its deduplication window.
+type NatsPublisher struct {
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+func NewNatsPublisher(conn *nats.Conn, opts ...nats.JSOpt) (*NatsPublisher, error) {
+	js, err := conn.JetStream(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &NatsPublisher{conn: conn, js: js}, nil
+}
+
+func (p *NatsPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	msg := nats.NewMsg(topic)
+	msg.Data = value
+	if len(key) > 0 {
+		msg.Header.Set(nats.MsgIdHdr, string(key))
+	}
+	_, err := p.js.PublishMsg(msg, nats.Context(ctx))
+	return err
+}
+
+func (p *NatsPublisher) Close() error {
+	return p.conn.Drain()
+}
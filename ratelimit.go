@@ -0,0 +1,100 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the API quota reported by the server in response headers.
+type RateLimit struct {
+	Limit     int64     // requests allowed per window, -1 if unknown
+	Remaining int64     // requests left in the current window, -1 if unknown
+	Reset     time.Time // when the window resets, zero if unknown
+	Time      time.Time // when the headers were received
+}
+
+// IsValid returns true when the server reported any quota information.
+func (r RateLimit) IsValid() bool {
+	return !r.Time.IsZero()
+}
+
+// Wait returns the time until the quota resets, or zero when requests
+// remain or the reset time is unknown.
+func (r RateLimit) Wait() time.Duration {
+	if r.Remaining != 0 || r.Reset.IsZero() {
+		return 0
+	}
+	if d := time.Until(r.Reset); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// LastRateLimit returns the quota from the most recent response which
+// contained rate limit headers. Schedulers can use it to pace requests
+// before hitting the limit.
+func (c *Client) LastRateLimit() (RateLimit, bool) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return c.rateLimit, c.rateLimit.IsValid()
+}
+
+func (c *Client) updateRateLimit(h http.Header) {
+	r, ok := parseRateLimit(h, time.Now())
+	if !ok {
+		return
+	}
+	c.rateMu.Lock()
+	c.rateLimit = r
+	c.rateMu.Unlock()
+}
+
+// parseRateLimit reads X-RateLimit-* headers and the unprefixed variants
+// from the IETF draft. Reset values are either seconds from now or, when
+// large enough, a unix timestamp.
+func parseRateLimit(h http.Header, now time.Time) (RateLimit, bool) {
+	r := RateLimit{Limit: -1, Remaining: -1}
+	var found bool
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-", "X-Rate-Limit-"} {
+		if v, ok := headerInt(h, prefix+"Limit"); ok {
+			r.Limit, found = v, true
+		}
+		if v, ok := headerInt(h, prefix+"Remaining"); ok {
+			r.Remaining, found = v, true
+		}
+		if v, ok := headerInt(h, prefix+"Reset"); ok {
+			if v > 1e9 {
+				r.Reset = time.Unix(v, 0)
+			} else {
+				r.Reset = now.Add(time.Duration(v) * time.Second)
+			}
+			found = true
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return r, false
+	}
+	r.Time = now
+	return r, true
+}
+
+func headerInt(h http.Header, key string) (int64, bool) {
+	s := h.Get(key)
+	if s == "" {
+		return 0, false
+	}
+	// some servers send a policy suffix like "100;w=60"
+	if i := strings.IndexAny(s, ",;"); i >= 0 {
+		s = s[:i]
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	return v, err == nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// ReceiptMismatch describes a field which differs between the indexer and
+// a Tezos node.
+type ReceiptMismatch struct {
+	Field   string
+	Indexer string
+	Node    string
+}
+
+type ErrReceiptMismatch struct {
+	Hash       tezos.OpHash
+	Mismatches []ReceiptMismatch
+}
+
+func (e ErrReceiptMismatch) Error() string {
+	s := make([]string, len(e.Mismatches))
+	for i, v := range e.Mismatches {
+		s[i] = fmt.Sprintf("%s indexer=%s node=%s", v.Field, v.Indexer, v.Node)
+	}
+	return fmt.Sprintf("receipt mismatch for op %s: %s", e.Hash, strings.Join(s, ", "))
+}
+
+func IsErrReceiptMismatch(err error) (ErrReceiptMismatch, bool) {
+	e, ok := err.(ErrReceiptMismatch)
+	return e, ok
+}
+
+// VerifyOpReceipt cross-checks an operation from the indexer against the
+// receipt a Tezos node reports for the same block. It verifies block and
+// operation hash and, for manager operations, sender, fee, amount and
+// status. Internal operations are only checked for inclusion. Operations
+// without hash (implicit events like baking rewards) cannot be verified.
+func VerifyOpReceipt(ctx context.Context, node *rpc.Client, o *Op) error {
+	if !o.Hash.IsValid() {
+		return fmt.Errorf("verify: op %d has no hash", o.Id)
+	}
+	e := ErrReceiptMismatch{Hash: o.Hash}
+	id := rpc.BlockLevel(o.Height)
+	bh, err := node.GetBlockHash(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !bh.Equal(o.Block) {
+		e.add("block", o.Block.String(), bh.String())
+		return e
+	}
+	lists, err := node.GetBlockOperations(ctx, id)
+	if err != nil {
+		return err
+	}
+	var op *rpc.Operation
+	for _, l := range lists {
+		for i := range l {
+			if l[i].Hash.Equal(o.Hash) {
+				op = &l[i]
+				break
+			}
+		}
+	}
+	if op == nil {
+		e.add("hash", o.Hash.String(), "")
+		return e
+	}
+	if o.IsInternal {
+		return nil
+	}
+
+	// find the matching content, manager operations are identified by counter
+	var (
+		content rpc.TypedOperation
+		man     rpc.Manager
+		isMan   bool
+	)
+	for _, v := range op.Contents {
+		if m, ok := rpcManager(v); ok {
+			if m.Counter == o.Counter {
+				content, man, isMan = v, m, true
+				break
+			}
+			continue
+		}
+		if len(op.Contents) == 1 {
+			content = v
+		}
+	}
+	if content == nil {
+		e.add("counter", strconv.FormatInt(o.Counter, 10), "")
+		return e
+	}
+	if isMan {
+		if !man.Source.Equal(o.Sender) {
+			e.add("sender", o.Sender.String(), man.Source.String())
+		}
+		if fee := tezToMutez(o.Fee); fee != man.Fee {
+			e.add("fee", strconv.FormatInt(fee, 10), strconv.FormatInt(man.Fee, 10))
+		}
+		if tx, ok := content.(*rpc.Transaction); ok {
+			if vol := tezToMutez(o.Volume); vol != tx.Amount {
+				e.add("amount", strconv.FormatInt(vol, 10), strconv.FormatInt(tx.Amount, 10))
+			}
+		}
+		if s := content.Result().Status; s != o.Status {
+			e.add("status", o.Status.String(), s.String())
+		}
+	}
+	if len(e.Mismatches) > 0 {
+		return e
+	}
+	return nil
+}
+
+func (e *ErrReceiptMismatch) add(field, indexer, node string) {
+	e.Mismatches = append(e.Mismatches, ReceiptMismatch{
+		Field:   field,
+		Indexer: indexer,
+		Node:    node,
+	})
+}
+
+func rpcManager(op rpc.TypedOperation) (rpc.Manager, bool) {
+	switch v := op.(type) {
+	case *rpc.Transaction:
+		return v.Manager, true
+	case *rpc.Origination:
+		return v.Manager, true
+	case *rpc.Delegation:
+		return v.Manager, true
+	case *rpc.Reveal:
+		return v.Manager, true
+	case *rpc.ConstantRegistration:
+		return v.Manager, true
+	case *rpc.SetDepositsLimit:
+		return v.Manager, true
+	default:
+		return rpc.Manager{}, false
+	}
+}
+
+func tezToMutez(v float64) int64 {
+	return int64(math.Round(v * 1000000))
+}
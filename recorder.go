@@ -0,0 +1,201 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+type RecordMode byte
+
+const (
+	// ReplayOnly serves all requests from fixtures and fails requests
+	// without fixture. Use in CI.
+	ReplayOnly RecordMode = iota
+
+	// RecordAll sends all requests to the API and overwrites fixtures.
+	RecordAll
+
+	// ReplayOrRecord serves existing fixtures and records missing ones.
+	ReplayOrRecord
+)
+
+// ErrFixtureMissing is returned in replay mode for requests without fixture.
+type ErrFixtureMissing struct {
+	Method string
+	Url    string
+	File   string
+}
+
+func (e ErrFixtureMissing) Error() string {
+	return fmt.Sprintf("recorder: no fixture for %s %s (%s)", e.Method, e.Url, e.File)
+}
+
+func IsErrFixtureMissing(err error) (ErrFixtureMissing, bool) {
+	e, ok := err.(ErrFixtureMissing)
+	return e, ok
+}
+
+// Fixture is a recorded API exchange. Bodies which are not valid UTF-8
+// are stored base64 encoded.
+type Fixture struct {
+	Method   string      `json:"method"`
+	Url      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     string      `json:"body"`
+	Encoding string      `json:"encoding,omitempty"`
+}
+
+// Recorder is a http.RoundTripper that records API responses to fixture
+// files and replays them, so code using the client can be tested without
+// network access:
+//
+//	c.UseTransport(tzstats.NewRecorder("testdata/fixtures", tzstats.ReplayOrRecord, nil))
+//
+// Requests are matched by method, path, query and body. The host is
+// ignored so fixtures work with any API URL, api keys are redacted.
+type Recorder struct {
+	dir  string
+	mode RecordMode
+	next http.RoundTripper
+}
+
+// NewRecorder creates a recorder storing fixtures in dir. Next is used to
+// send requests in record mode and defaults to http.DefaultTransport.
+func NewRecorder(dir string, mode RecordMode, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		dir:  dir,
+		mode: mode,
+		next: next,
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		buf, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = buf
+		req.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	}
+	u := redactAuth(req.URL)
+	name := fixtureName(req.Method, u.Path, u.Query().Encode(), body)
+	file := filepath.Join(r.dir, name)
+
+	if r.mode != RecordAll {
+		f, err := readFixture(file)
+		switch {
+		case err == nil:
+			return f.response(req)
+		case !os.IsNotExist(err):
+			return nil, err
+		case r.mode == ReplayOnly:
+			return nil, ErrFixtureMissing{Method: req.Method, Url: u.String(), File: file}
+		}
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	f := Fixture{
+		Method: req.Method,
+		Url:    u.RequestURI(),
+		Status: resp.StatusCode,
+		Header: resp.Header.Clone(),
+	}
+	f.Header.Del("Set-Cookie")
+	if utf8.Valid(buf) {
+		f.Body = string(buf)
+	} else {
+		f.Body = base64.StdEncoding.EncodeToString(buf)
+		f.Encoding = "base64"
+	}
+	if err := writeFixture(file, f); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func fixtureName(method, path, query string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+path+"?"+query+"\n")
+	h.Write(body)
+	slug := strings.Trim(strings.NewReplacer("/", "_", ".", "_").Replace(path), "_")
+	if len(slug) > 64 {
+		slug = slug[:64]
+	}
+	return fmt.Sprintf("%s_%s.json", slug, hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+func readFixture(file string) (*Fixture, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fixture{}
+	if err := json.Unmarshal(buf, f); err != nil {
+		return nil, fmt.Errorf("recorder: %s: %w", file, err)
+	}
+	return f, nil
+}
+
+func writeFixture(file string, f Fixture) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, buf, 0644)
+}
+
+func (f *Fixture) response(req *http.Request) (*http.Response, error) {
+	body := []byte(f.Body)
+	if f.Encoding == "base64" {
+		buf, err := base64.StdEncoding.DecodeString(f.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = buf
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"blockwatch.cc/tzgo/tezos"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var (
+	DefaultResolverCacheSize = 1 << 16
+	DefaultResolverBatchSize = 1000
+
+	// DefaultResolverChunkSize is the number of streamed rows resolved
+	// together before they are passed on, see OpQuery.RunFunc.
+	DefaultResolverChunkSize = 250
+
+	// DefaultMaxUrlLength limits the request url length of batched in
+	// filters, common servers and proxies reject urls above 8kB.
+	DefaultMaxUrlLength = 8 << 10
+)
+
+// AddressStore persists account id to address mappings across process
+// restarts. Account ids are stable, so entries never expire. LoadIds
+// returns the mappings of known addresses.
+type AddressStore interface {
+	LoadAddresses(ctx context.Context, ids []uint64) (map[uint64]tezos.Address, error)
+	LoadIds(ctx context.Context, addrs []tezos.Address) (map[uint64]tezos.Address, error)
+	StoreAddresses(ctx context.Context, m map[uint64]tezos.Address) error
+}
+
+// nextBatch returns how many of the next n in filter values fit into one
+// request url, size returns the length of value i.
+func nextBatch(n int, size func(i int) int) int {
+	// keep room for server, path and other arguments
+	max := DefaultMaxUrlLength - 1024
+	k, l := 0, 0
+	for k < n && k < DefaultResolverBatchSize {
+		l += size(k) + 3 // url encoded comma
+		if k > 0 && l > max {
+			break
+		}
+		k++
+	}
+	return k
+}
+
+// AddressResolver translates numeric account ids to addresses and back.
+// Lookups are served from an in-memory LRU cache, then from an optional
+// persistent store and finally from the account table in batches.
+type AddressResolver struct {
+	client *Client
+	cache  *lru.TwoQueueCache
+	store  AddressStore
+}
+
+func NewAddressResolver(c *Client, store AddressStore) *AddressResolver {
+	sz := DefaultResolverCacheSize
+	if sz < 2 {
+		sz = 2
+	}
+	cache, _ := lru.New2Q(sz)
+	return &AddressResolver{
+		client: c,
+		cache:  cache,
+		store:  store,
+	}
+}
+
+// UseAddressResolver enables resolving sender, receiver, creator and baker
+// addresses from their account ids on op table queries which request ids
+// only. This allows smaller column sets while still decoding addresses.
+func (c *Client) UseAddressResolver(r *AddressResolver) {
+	c.resolver = r
+}
+
+func (c *Client) AddressResolver() *AddressResolver {
+	return c.resolver
+}
+
+// Resolve returns addresses for all known account ids. Zero ids are ignored.
+func (r *AddressResolver) Resolve(ctx context.Context, ids ...uint64) (map[uint64]tezos.Address, error) {
+	res := make(map[uint64]tezos.Address, len(ids))
+	missing := make([]uint64, 0)
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if _, ok := res[id]; ok {
+			continue
+		}
+		if v, ok := r.cache.Get(id); ok {
+			res[id] = v.(tezos.Address)
+			continue
+		}
+		res[id] = tezos.Address{}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	if r.store != nil {
+		m, err := r.store.LoadAddresses(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		missing = r.merge(res, missing, m)
+		if len(missing) == 0 {
+			return res, nil
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	found := make(map[uint64]tezos.Address, len(missing))
+	for len(missing) > 0 {
+		n := nextBatch(len(missing), func(i int) int {
+			return len(strconv.FormatUint(missing[i], 10))
+		})
+		q := r.client.NewAccountQuery()
+		q.WithColumns("row_id", "address").
+			WithFilter(FilterModeIn, "row_id", missing[:n]).
+			WithLimit(n)
+		accs, err := q.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accs.Rows {
+			found[a.RowId] = a.Address
+		}
+		missing = missing[n:]
+	}
+	r.merge(res, nil, found)
+	if r.store != nil && len(found) > 0 {
+		if err := r.store.StoreAddresses(ctx, found); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ResolveIds returns account ids for addresses in the same order. Unknown
+// addresses yield a zero id.
+func (r *AddressResolver) ResolveIds(ctx context.Context, addrs ...tezos.Address) ([]uint64, error) {
+	res := make([]uint64, len(addrs))
+	found := make(map[string]uint64)
+	missing := make([]tezos.Address, 0)
+	for i, a := range addrs {
+		key := a.String()
+		if v, ok := r.cache.Get(key); ok {
+			res[i] = v.(uint64)
+			continue
+		}
+		if _, ok := found[key]; ok {
+			continue
+		}
+		found[key] = 0
+		missing = append(missing, a)
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	if r.store != nil {
+		m, err := r.store.LoadIds(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for id, a := range m {
+			found[a.String()] = id
+			r.cache.Add(id, a)
+			r.cache.Add(a.String(), id)
+		}
+		n := 0
+		for _, a := range missing {
+			if found[a.String()] == 0 {
+				missing[n] = a
+				n++
+			}
+		}
+		missing = missing[:n]
+	}
+
+	loaded := make(map[uint64]tezos.Address, len(missing))
+	for len(missing) > 0 {
+		n := nextBatch(len(missing), func(i int) int {
+			return len(missing[i].String())
+		})
+		keys := make([]string, n)
+		for i, a := range missing[:n] {
+			keys[i] = a.String()
+		}
+		q := r.client.NewAccountQuery()
+		q.WithColumns("row_id", "address").
+			WithFilter(FilterModeIn, "address", keys).
+			WithLimit(n)
+		accs, err := q.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accs.Rows {
+			found[a.Address.String()] = a.RowId
+			loaded[a.RowId] = a.Address
+			r.cache.Add(a.RowId, a.Address)
+			r.cache.Add(a.Address.String(), a.RowId)
+		}
+		missing = missing[n:]
+	}
+	if r.store != nil && len(loaded) > 0 {
+		if err := r.store.StoreAddresses(ctx, loaded); err != nil {
+			return nil, err
+		}
+	}
+	for i, a := range addrs {
+		if res[i] == 0 {
+			res[i] = found[a.String()]
+		}
+	}
+	return res, nil
+}
+
+// merge copies found addresses into res and the cache and returns ids
+// which are still missing.
+func (r *AddressResolver) merge(res map[uint64]tezos.Address, ids []uint64, found map[uint64]tezos.Address) []uint64 {
+	for id, addr := range found {
+		res[id] = addr
+		r.cache.Add(id, addr)
+		r.cache.Add(addr.String(), id)
+	}
+	missing := ids[:0]
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// ResolveOps fills empty address fields from their account ids.
+func (r *AddressResolver) ResolveOps(ctx context.Context, ops []*Op) error {
+	ids := make([]uint64, 0, 2*len(ops))
+	add := func(a tezos.Address, id uint64) {
+		if id > 0 && !a.IsValid() {
+			ids = append(ids, id)
+		}
+	}
+	for _, o := range ops {
+		add(o.Sender, o.SenderId)
+		add(o.Receiver, o.ReceiverId)
+		add(o.Creator, o.CreatorId)
+		add(o.Baker, o.BakerId)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	m, err := r.Resolve(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	for _, o := range ops {
+		resolveAddress(&o.Sender, o.SenderId, m)
+		resolveAddress(&o.Receiver, o.ReceiverId, m)
+		resolveAddress(&o.Creator, o.CreatorId, m)
+		resolveAddress(&o.Baker, o.BakerId, m)
+	}
+	return nil
+}
+
+func resolveAddress(a *tezos.Address, id uint64, m map[uint64]tezos.Address) {
+	if id == 0 || a.IsValid() {
+		return
+	}
+	*a = m[id]
+}
@@ -10,21 +10,26 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	headerRuntime  = "X-Runtime"
-	trailerError   = "X-Streaming-Error"
-	trailerCursor  = "X-Streaming-Cursor"
-	trailerCount   = "X-Streaming-Count"
-	trailerRuntime = "X-Streaming-Runtime"
+	headerDeprecation = "Deprecation"
+	headerSunset      = "Sunset"
+	headerWarning     = "Warning"
+	headerRuntime     = "X-Runtime"
+	trailerError      = "X-Streaming-Error"
+	trailerCursor     = "X-Streaming-Cursor"
+	trailerCount      = "X-Streaming-Count"
+	trailerRuntime    = "X-Streaming-Runtime"
 )
 
 type StreamResponse struct {
-	Runtime time.Duration
-	Cursor  string
-	Count   int
+	Runtime  time.Duration
+	Cursor   string
+	Count    int
+	Warnings []Warning
 }
 
 func NewStreamResponse(header http.Header) (StreamResponse, error) {
@@ -32,6 +37,7 @@ func NewStreamResponse(header http.Header) (StreamResponse, error) {
 	if header == nil {
 		return r, nil
 	}
+	r.Warnings = parseWarnings(header)
 	if cur, ok := header[trailerCursor]; ok && len(cur) > 0 {
 		r.Cursor = cur[0]
 	}
@@ -52,6 +58,99 @@ func NewStreamResponse(header http.Header) (StreamResponse, error) {
 	return r, nil
 }
 
+// Warning is a deprecation notice or warning sent by the API server
+// in Deprecation, Sunset or Warning response headers.
+type Warning struct {
+	Code    int       `json:"code"`
+	Text    string    `json:"text"`
+	Request string    `json:"request,omitempty"`
+	Sunset  time.Time `json:"sunset,omitempty"`
+}
+
+func (w Warning) String() string {
+	s := strconv.Itoa(w.Code) + " " + w.Text
+	if !w.Sunset.IsZero() {
+		s += " (sunset " + w.Sunset.Format(time.RFC3339) + ")"
+	}
+	return s
+}
+
+type warningsContextKey struct{}
+
+// WarningList collects server warnings of calls made with a context from
+// WithWarnings. It is safe for concurrent use.
+type WarningList struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+// WithWarnings returns a context which collects server warnings of all
+// calls made with it into the returned list instead of the client-wide
+// buffer, so concurrent queries don't see each other's warnings.
+//
+//	ctx, w := tzstats.WithWarnings(ctx)
+//	ops, err := q.Run(ctx)
+//	for _, v := range w.Warnings() {
+//		log.Warn(v)
+//	}
+func WithWarnings(ctx context.Context) (context.Context, *WarningList) {
+	l := &WarningList{}
+	return context.WithValue(ctx, warningsContextKey{}, l), l
+}
+
+func warningsFrom(ctx context.Context) *WarningList {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(warningsContextKey{}).(*WarningList)
+	return l
+}
+
+func (l *WarningList) add(w Warning) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.list = append(l.list, w)
+}
+
+// Warnings returns all warnings collected so far.
+func (l *WarningList) Warnings() []Warning {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Warning(nil), l.list...)
+}
+
+func parseWarnings(header http.Header) []Warning {
+	var (
+		warnings []Warning
+		sunset   time.Time
+	)
+	if v := header.Get(headerSunset); v != "" {
+		sunset, _ = http.ParseTime(v)
+	}
+	// Warning: 299 - "deprecated parameter"
+	for _, v := range header.Values(headerWarning) {
+		w := Warning{Code: 299, Text: v, Sunset: sunset}
+		if f := strings.SplitN(v, " ", 3); len(f) == 3 {
+			if code, err := strconv.Atoi(f[0]); err == nil {
+				w.Code = code
+				w.Text = f[2]
+				if i := strings.LastIndexByte(w.Text, '"'); i > 0 && w.Text[0] == '"' {
+					w.Text = w.Text[1:i]
+				}
+			}
+		}
+		warnings = append(warnings, w)
+	}
+	if v := header.Get(headerDeprecation); v != "" && v != "false" && len(warnings) == 0 {
+		warnings = append(warnings, Warning{
+			Code:   299,
+			Text:   "deprecated",
+			Sunset: sunset,
+		})
+	}
+	return warnings
+}
+
 // request holds information about a request that is used to properly
 // detect, interpret, and deliver a reply to it.
 type request struct {
@@ -62,11 +161,13 @@ type request struct {
 	responseChan    chan *response
 }
 
+// String returns method, protocol and url of the request with API keys
+// redacted, so it is safe to use in errors and logs.
 func (r *request) String() string {
 	return strings.Join([]string{
 		r.httpRequest.Method,
 		r.httpRequest.Proto,
-		r.httpRequest.URL.String(),
+		redactAuth(r.httpRequest.URL).String(),
 	}, " ")
 }
 
@@ -83,13 +184,24 @@ type response struct {
 type FutureResult chan *response
 
 func (r FutureResult) Receive(ctx context.Context) error {
+	_, err := r.receiveStatus(ctx)
+	return err
+}
+
+// receiveStatus works like Receive and also returns the response status,
+// which is zero when the request failed before a response arrived.
+func (r FutureResult) receiveStatus(ctx context.Context) (int, error) {
 	resp, err := receiveFuture(ctx, r)
+	var status int
+	if resp != nil {
+		status = resp.status
+	}
 	if err != nil {
-		if herr, ok := IsHttpError(err); ok {
-			return herr
-		} else if rerr, ok := IsErrRateLimited(err); ok {
-			return rerr
-		} else if resp != nil {
+		switch err.(type) {
+		case HttpError, ApiErrors, ErrRateLimited, ErrPruned:
+			return status, err
+		}
+		if resp != nil {
 			buf := resp.result
 			if resp != nil && buf != nil && resp.status > 299 {
 				errs := ApiErrors{}
@@ -101,12 +213,12 @@ func (r FutureResult) Receive(ctx context.Context) error {
 						Detail:  err.Error(),
 					})
 				}
-				return errs
+				return status, errs
 			}
 		}
-		return err
+		return status, err
 	}
-	return nil
+	return status, nil
 }
 
 func (r FutureResult) Done() bool {
@@ -0,0 +1,245 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// BackoffStrategy selects how retry delays are computed and randomized.
+type BackoffStrategy byte
+
+const (
+	// BackoffExponential doubles the delay on each attempt and randomizes
+	// it by +/- Jitter.
+	BackoffExponential BackoffStrategy = iota
+
+	// BackoffFullJitter picks a random delay between zero and the
+	// exponential delay. It spreads retries of many clients best and suits
+	// bulk jobs.
+	BackoffFullJitter
+
+	// BackoffEqualJitter keeps half of the exponential delay and
+	// randomizes the other half.
+	BackoffEqualJitter
+
+	// BackoffDecorrelated picks a random delay between BaseDelay and three
+	// times the previous delay.
+	BackoffDecorrelated
+)
+
+// RetryPolicy controls how the client retries requests which failed with a
+// transient server error or a connection error. Delays grow from BaseDelay
+// up to MaxDelay as defined by Strategy. A Retry-After header sent by the
+// server takes precedence over the computed delay and is honored in full.
+// When it ends after the context deadline the request fails immediately.
+type RetryPolicy struct {
+	MaxAttempts int                 // total attempts including the first, <= 1 disables retries
+	BaseDelay   time.Duration       // delay before the first retry
+	MaxDelay    time.Duration       // upper bound for computed delays
+	Jitter      float64             // fraction of each delay to randomize (BackoffExponential only)
+	Strategy    BackoffStrategy     // delay computation
+	Statuses    []int               // HTTP status codes to retry
+	PerStatus   map[int]RetryPolicy // policies replacing this one for specific statuses
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+	Statuses:    []int{429, 502, 503, 504},
+}
+
+// UseRetryPolicy enables automatic retries. Only idempotent GET, HEAD and
+// OPTIONS requests which failed before the response headers arrived or
+// received a retryable error status are retried. Once a successful
+// response arrived, errors while reading its body are returned, so
+// streamed results are never written twice.
+func (c *Client) UseRetryPolicy(p RetryPolicy) {
+	c.retry = &p
+}
+
+func (c *Client) RetryPolicy() (RetryPolicy, bool) {
+	if c.retry == nil {
+		return RetryPolicy{}, false
+	}
+	return *c.retry, true
+}
+
+type retryContextKey struct{}
+
+// WithRetryPolicy returns a context which overrides the client's retry
+// policy for all requests made with it. This lets bulk downloads and
+// latency sensitive polling share a client but back off differently.
+func WithRetryPolicy(ctx context.Context, p RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, &p)
+}
+
+// retryPolicy returns the policy from ctx or the client's policy.
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	if p, ok := ctx.Value(retryContextKey{}).(*RetryPolicy); ok {
+		return p
+	}
+	return c.retry
+}
+
+func retryStatus(err error) int {
+	if _, ok := err.(ErrRateLimited); ok {
+		return http.StatusTooManyRequests
+	}
+	return ErrorStatus(err)
+}
+
+// forError returns the per-status policy matching err or p itself.
+func (p RetryPolicy) forError(err error) RetryPolicy {
+	if sp, ok := p.PerStatus[retryStatus(err)]; ok {
+		return sp
+	}
+	return p
+}
+
+func (p RetryPolicy) canRetry(method string, err error, attempt int) bool {
+	if err == nil || !isIdempotent(method) {
+		return false
+	}
+	status := retryStatus(err)
+	if sp, ok := p.PerStatus[status]; ok {
+		return attempt+1 < sp.MaxAttempts
+	}
+	if attempt+1 >= p.MaxAttempts {
+		return false
+	}
+	if status == 0 {
+		return isTransportError(err)
+	}
+	for _, v := range p.Statuses {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the wait time before retry attempt n (starting at 0). The
+// server's Retry-After value is used when present.
+func (p RetryPolicy) Delay(n int, err error) time.Duration {
+	return p.forError(err).delay(n, 0, err)
+}
+
+// delay computes the wait time for attempt n. Prev is the previous delay
+// used by decorrelated backoff, zero on the first retry.
+func (p RetryPolicy) delay(n int, prev time.Duration, err error) time.Duration {
+	if d, ok := retryAfter(err); ok {
+		return d
+	}
+	exp := float64(p.BaseDelay) * math.Pow(2, float64(n))
+	if p.MaxDelay > 0 && exp > float64(p.MaxDelay) {
+		exp = float64(p.MaxDelay)
+	}
+	var d float64
+	switch p.Strategy {
+	case BackoffFullJitter:
+		d = exp * rand.Float64()
+	case BackoffEqualJitter:
+		d = exp/2 + exp/2*rand.Float64()
+	case BackoffDecorrelated:
+		base := float64(p.BaseDelay)
+		hi := float64(prev) * 3
+		if hi < base {
+			hi = base
+		}
+		d = base + (hi-base)*rand.Float64()
+		if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+			d = float64(p.MaxDelay)
+		}
+	default:
+		d = exp
+		if p.Jitter > 0 {
+			d += d * p.Jitter * (2*rand.Float64() - 1)
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+func (p RetryPolicy) wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTransportError returns true when a request failed before a response
+// was received, e.g. on connection resets or per-request timeouts. Errors
+// while reading a successful response are not retried by callRetry.
+func isTransportError(err error) bool {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+func retryAfter(err error) (time.Duration, bool) {
+	var h http.Header
+	switch e := err.(type) {
+	case ErrRateLimited:
+		h = e.Header
+	case HttpError:
+		h = e.Header
+	case ApiError:
+		return e.RetryAfter, e.RetryAfter > 0
+	case ApiErrors:
+		if len(e.Errors) > 0 {
+			return e.Errors[0].RetryAfter, e.Errors[0].RetryAfter > 0
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+	return parseRetryAfter(h)
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or HTTP date format.
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
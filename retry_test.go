@@ -0,0 +1,114 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    time.Millisecond,
+	Statuses:    []int{503},
+}
+
+func TestRetryStatus(t *testing.T) {
+	var n int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"height":1}`))
+	})
+	c.UseRetryPolicy(testRetryPolicy)
+	var v struct{ Height int64 }
+	if err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || v.Height != 1 {
+		t.Errorf("got %d attempts and height %d, want 3 and 1", n, v.Height)
+	}
+}
+
+func TestRetryConnectionError(t *testing.T) {
+	var n int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) == 1 {
+			// close the connection before sending headers
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{"height":1}`))
+	})
+	c.UseRetryPolicy(testRetryPolicy)
+	var v struct{ Height int64 }
+	if err := c.GetJSON(context.Background(), "/explorer/tip", nil, &v); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d attempts, want 2", n)
+	}
+}
+
+func TestRetryBodyError(t *testing.T) {
+	var n int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		// the request times out while the body is read
+		w.Write([]byte(`[[1],[2],`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	c.UseRetryPolicy(testRetryPolicy)
+	ctx := WithRequestTimeout(context.Background(), 50*time.Millisecond)
+	var buf bytes.Buffer
+	if err := c.get(ctx, "/tables/op", nil, &buf); err == nil {
+		t.Fatal("expected body read error")
+	}
+	if n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+	if s := buf.String(); s != `[[1],[2],` {
+		t.Errorf("stream written more than once: %q", s)
+	}
+}
+
+func TestRetryAfterApiError(t *testing.T) {
+	var n int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"errors":[{"code":1,"message":"maintenance"}]}`))
+	})
+	c.UseRetryPolicy(testRetryPolicy)
+
+	// the Retry-After delay exceeds the deadline, fail without waiting
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var v struct{}
+	err := c.GetJSON(ctx, "/explorer/tip", nil, &v)
+	if _, ok := IsApiError(err); !ok {
+		t.Fatalf("expected api error, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+	if d := testRetryPolicy.Delay(0, err); d != 2*time.Second {
+		t.Errorf("delay: got %s, want 2s", d)
+	}
+}
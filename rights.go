@@ -6,10 +6,9 @@ package tzstats
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"time"
 
 	"blockwatch.cc/tzgo/tezos"
 )
@@ -17,6 +16,8 @@ import (
 type Right struct {
 	Type           tezos.RightType `json:"type"`
 	Address        tezos.Address   `json:"address"`
+	Height         int64           `json:"height"`
+	Cycle          int64           `json:"cycle"`
 	Round          int             `json:"round"`
 	IsUsed         bool            `json:"is_used"`
 	IsLost         bool            `json:"is_lost"`
@@ -26,6 +27,58 @@ type Right struct {
 	IsSeedRevealed bool            `json:"is_seed_revealed"`
 }
 
+// RightStatus is the outcome of a baking or endorsing right.
+type RightStatus string
+
+const (
+	RightStatusPending  RightStatus = "pending"  // block does not exist yet
+	RightStatusRealized RightStatus = "realized" // right was used
+	RightStatusMissed   RightStatus = "missed"   // endorsement was not included
+	RightStatusLost     RightStatus = "lost"     // block was baked by someone else
+	RightStatusStolen   RightStatus = "stolen"   // block was baked without own right
+)
+
+func (r Right) IsBaking() bool {
+	return r.Type == tezos.RightTypeBaking
+}
+
+func (r Right) IsEndorsing() bool {
+	return r.Type == tezos.RightTypeEndorsing
+}
+
+// Status returns the outcome of the right. Rights above the tip height are
+// still pending, as are all unused rights when tip is unknown (zero).
+func (r Right) Status(tip int64) RightStatus {
+	switch {
+	case r.IsUsed:
+		return RightStatusRealized
+	case r.IsStolen:
+		return RightStatusStolen
+	case tip <= 0 || r.Height > tip:
+		return RightStatusPending
+	case r.IsLost:
+		return RightStatusLost
+	case r.IsMissed:
+		return RightStatusMissed
+	default:
+		return RightStatusPending
+	}
+}
+
+// EstimateTime estimates when the right's block is (or was) produced
+// assuming all blocks between tip and the right's height are baked at
+// round 0. Baking rights at later rounds add the duration of all earlier
+// rounds.
+func (r Right) EstimateTime(cfg *BlockchainConfig, tip *Tip) time.Time {
+	t := tip.Timestamp.Add(time.Duration(r.Height-tip.Height) * RoundDuration(cfg, 0))
+	if r.IsBaking() {
+		for i := 0; i < r.Round; i++ {
+			t = t.Add(RoundDuration(cfg, i))
+		}
+	}
+	return t
+}
+
 type CycleRights struct {
 	RowId     uint64         `json:"row_id"`
 	Cycle     int64          `json:"cycle"`
@@ -82,6 +135,8 @@ func (r CycleRights) RightAt(height int64, typ tezos.RightType) (Right, bool) {
 		return Right{
 			Type:           typ,
 			Address:        r.Address,
+			Height:         height,
+			Cycle:          r.Cycle,
 			IsUsed:         isSet(r.Bake, pos) && isSet(r.Baked, pos),
 			IsLost:         isSet(r.Bake, pos) && !isSet(r.Baked, pos),
 			IsStolen:       !isSet(r.Bake, pos) && isSet(r.Baked, pos),
@@ -93,6 +148,8 @@ func (r CycleRights) RightAt(height int64, typ tezos.RightType) (Right, bool) {
 		return Right{
 			Type:     typ,
 			Address:  r.Address,
+			Height:   height,
+			Cycle:    r.Cycle,
 			IsUsed:   isSet(r.Endorse, pos) && isSet(r.Endorsed, pos),
 			IsMissed: isSet(r.Endorse, pos) && !isSet(r.Endorsed, pos),
 		}, true
@@ -151,8 +208,8 @@ func (r *CycleRights) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return r.UnmarshalJSONBrief(data)
 	}
-	type Alias *CycleRights
-	return json.Unmarshal(data, Alias(r))
+	type Alias CycleRights
+	return json.Unmarshal(data, (*Alias)(r))
 }
 
 func (r *CycleRights) UnmarshalJSONBrief(data []byte) error {
@@ -164,36 +221,15 @@ func (r *CycleRights) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, r.columns); err != nil {
+		return err
+	}
 	for i, v := range r.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			right.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "height":
-			right.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "cycle":
-			right.Cycle, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "account_id":
-			right.AccountId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "address":
-			right.Address, err = tezos.ParseAddress(f.(string))
-		case "baking_rights":
-			right.Bake, err = hex.DecodeString(f.(string))
-		case "endorsing_rights":
-			right.Endorse, err = hex.DecodeString(f.(string))
-		case "blocks_baked":
-			right.Baked, err = hex.DecodeString(f.(string))
-		case "blocks_endorsed":
-			right.Endorsed, err = hex.DecodeString(f.(string))
-		case "seeds_required":
-			right.Seed, err = hex.DecodeString(f.(string))
-		case "seeds_revealed":
-			right.Seeded, err = hex.DecodeString(f.(string))
-		}
-		if err != nil {
+		if _, err := right.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
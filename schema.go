@@ -0,0 +1,190 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// tableModels maps table names to the models used for decoding table rows.
+var tableModels = map[string]interface{}{
+	"account":        &Account{},
+	"bigmaps":        &BigmapRow{},
+	"bigmap_updates": &BigmapUpdateRow{},
+	"bigmap_values":  &BigmapValueRow{},
+	"block":          &Block{},
+	"chain":          &Chain{},
+	"constant":       &Constant{},
+	"contract":       &Contract{},
+	"op":             &Op{},
+	"rights":         &CycleRights{},
+	"snapshot":       &Snapshot{},
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	jsonMarshaler  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// JSONSchema is a JSON Schema document.
+type JSONSchema map[string]interface{}
+
+// Schema returns a JSON Schema describing rows of table in the object
+// form the SDK uses when exporting models. All columns are optional since
+// queries may select any subset.
+func Schema(table string) (JSONSchema, error) {
+	m, ok := tableModels[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %q", table)
+	}
+	tinfo, err := GetTypeInfo(m, "")
+	if err != nil {
+		return nil, err
+	}
+	typ := reflect.Indirect(reflect.ValueOf(m)).Type()
+	props := make(map[string]interface{})
+	for _, f := range tinfo.Fields {
+		if f.ContainsFlag("notable") {
+			continue
+		}
+		props[f.Alias] = typeSchema(typ.FieldByIndex(f.Idx).Type, map[reflect.Type]bool{typ: true})
+	}
+	return JSONSchema{
+		"$schema":    jsonSchemaDraft,
+		"title":      table,
+		"type":       "object",
+		"properties": props,
+	}, nil
+}
+
+// ValueSchema returns a JSON Schema for Micheline values of type typ as
+// they appear in unfolded API responses (e.g. contract storage, call
+// parameters or bigmap values). Use a contract's script type definitions
+// to describe its decoded data.
+func ValueSchema(typ micheline.Typedef) JSONSchema {
+	s := typedefSchema(typ)
+	s["$schema"] = jsonSchemaDraft
+	if typ.Name != "" {
+		s["title"] = typ.Name
+	}
+	return s
+}
+
+func typeSchema(typ reflect.Type, seen map[reflect.Type]bool) JSONSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		return JSONSchema{"type": "string", "format": "date-time"}
+	case typ == rawMessageType:
+		return JSONSchema{}
+	case typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType):
+		return JSONSchema{"type": "string"}
+	case typ.Implements(jsonMarshaler) || reflect.PtrTo(typ).Implements(jsonMarshaler):
+		return JSONSchema{}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return JSONSchema{"type": "string"}
+		}
+		return JSONSchema{"type": "array", "items": typeSchema(typ.Elem(), seen)}
+	case reflect.Map:
+		return JSONSchema{"type": "object", "additionalProperties": typeSchema(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return JSONSchema{"type": "object"}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+		tinfo, err := getReflectTypeInfo(typ, tagName)
+		if err != nil {
+			return JSONSchema{"type": "object"}
+		}
+		props := make(map[string]interface{})
+		for _, f := range tinfo.Fields {
+			name := f.Alias
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = typeSchema(typ.FieldByIndex(f.Idx).Type, seen)
+		}
+		return JSONSchema{"type": "object", "properties": props}
+	default:
+		return JSONSchema{}
+	}
+}
+
+func typedefSchema(typ micheline.Typedef) JSONSchema {
+	var s JSONSchema
+	switch typ.Type {
+	case "int", "nat", "mutez", "bls12_381_fr":
+		// large numbers are rendered as strings
+		s = JSONSchema{"type": "string", "pattern": "^-?[0-9]+$"}
+	case "bool":
+		s = JSONSchema{"type": "boolean"}
+	case "timestamp":
+		s = JSONSchema{"type": "string", "format": "date-time"}
+	case "unit":
+		s = JSONSchema{"type": "null"}
+	case "list", "set":
+		s = JSONSchema{"type": "array"}
+		if len(typ.Args) > 0 {
+			s["items"] = typedefSchema(typ.Args[0])
+		}
+	case "map", "big_map":
+		s = JSONSchema{"type": "object"}
+		if len(typ.Args) > 1 {
+			s["additionalProperties"] = typedefSchema(typ.Args[1])
+		}
+		if typ.Type == "big_map" {
+			// bigmaps are rendered as numeric id unless unfolded
+			s = JSONSchema{"oneOf": []interface{}{s, JSONSchema{"type": []string{"integer", "string"}}}}
+		}
+	case "struct":
+		props := make(map[string]interface{})
+		for _, v := range typ.Args {
+			props[v.Name] = typedefSchema(v)
+		}
+		s = JSONSchema{"type": "object", "properties": props}
+	case "union":
+		alts := make([]interface{}, 0, len(typ.Args))
+		for _, v := range typ.Args {
+			alts = append(alts, JSONSchema{
+				"type":                 "object",
+				"properties":           map[string]interface{}{v.Name: typedefSchema(v)},
+				"required":             []string{v.Name},
+				"additionalProperties": false,
+			})
+		}
+		s = JSONSchema{"oneOf": alts}
+	case "lambda", "operation", "contract", "ticket", "sapling_state", "sapling_transaction":
+		s = JSONSchema{}
+	default:
+		// address, key, key_hash, signature, chain_id, string, bytes, ...
+		s = JSONSchema{"type": "string"}
+	}
+	if typ.Optional {
+		s = JSONSchema{"oneOf": []interface{}{s, JSONSchema{"type": "null"}}}
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+//
+// Decoder benchmark harness for TzStats-Go
+//
+// runs the tzstatsbench suite, optionally stores results as baseline and
+// compares them against a previous baseline
+//
+//	go run ./scripts/bench -out v0.12.json
+//	go run ./scripts/bench -base v0.12.json -tolerance 0.1
+//
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"blockwatch.cc/tzstats-go/tzstatsbench"
+)
+
+var (
+	out       string
+	base      string
+	pattern   string
+	tolerance float64
+)
+
+func init() {
+	flag.StringVar(&out, "out", "", "write results to file")
+	flag.StringVar(&base, "base", "", "compare against baseline results file")
+	flag.StringVar(&pattern, "run", "", "run only benchmarks matching regexp")
+	flag.Float64Var(&tolerance, "tolerance", 0.1, "allowed relative regression")
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	res, err := tzstatsbench.Run(pattern)
+	if err != nil {
+		return err
+	}
+	for _, v := range res {
+		fmt.Println(v)
+	}
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := tzstatsbench.WriteResults(f, res); err != nil {
+			return err
+		}
+	}
+	if base == "" {
+		return nil
+	}
+	f, err := os.Open(base)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	baseline, err := tzstatsbench.ReadResults(f)
+	if err != nil {
+		return err
+	}
+	regs := tzstatsbench.Compare(baseline, res, tolerance)
+	for _, r := range regs {
+		fmt.Println("REGRESSION", r)
+	}
+	if len(regs) > 0 {
+		return fmt.Errorf("%d regressions above %.0f%%", len(regs), tolerance*100)
+	}
+	return nil
+}
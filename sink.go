@@ -0,0 +1,156 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sink is a downstream destination for decoded data like a database
+// or message queue.
+type Sink interface {
+	WriteOps(ctx context.Context, ops []*Op) error
+	WriteBlocks(ctx context.Context, blocks []*Block) error
+	Flush(ctx context.Context) error
+}
+
+var (
+	DefaultBatchSize  = 1000
+	DefaultBatchDelay = 5 * time.Second
+)
+
+// BatchSink buffers writes and forwards them to the wrapped sink once the
+// batch size is reached or the oldest buffered item exceeds the batch delay.
+// Writers that fill the buffer block until the flush completes, which
+// propagates backpressure from a slow sink to the producer.
+type BatchSink struct {
+	sink    Sink
+	size    int
+	delay   time.Duration
+	mu      sync.Mutex
+	flushMu sync.Mutex
+	ops     []*Op
+	blocks  []*Block
+	timer   *time.Timer
+	err     error
+}
+
+func NewBatchSink(s Sink, size int, delay time.Duration) *BatchSink {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if delay <= 0 {
+		delay = DefaultBatchDelay
+	}
+	return &BatchSink{
+		sink:  s,
+		size:  size,
+		delay: delay,
+	}
+}
+
+func (b *BatchSink) WriteOps(ctx context.Context, ops []*Op) error {
+	b.mu.Lock()
+	if err := b.err; err != nil {
+		b.err = nil
+		b.mu.Unlock()
+		return err
+	}
+	b.ops = append(b.ops, ops...)
+	full := b.lenLocked() >= b.size
+	b.startTimerLocked()
+	b.mu.Unlock()
+	if full {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+func (b *BatchSink) WriteBlocks(ctx context.Context, blocks []*Block) error {
+	b.mu.Lock()
+	if err := b.err; err != nil {
+		b.err = nil
+		b.mu.Unlock()
+		return err
+	}
+	b.blocks = append(b.blocks, blocks...)
+	full := b.lenLocked() >= b.size
+	b.startTimerLocked()
+	b.mu.Unlock()
+	if full {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes all buffered blocks and ops to the wrapped sink and flushes it.
+// Blocks are written before ops so that referential consumers see parents first.
+// Data the wrapped sink failed to write stays buffered for the next flush.
+func (b *BatchSink) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+	b.mu.Lock()
+	ops, blocks := b.ops, b.blocks
+	b.ops, b.blocks = nil, nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if len(blocks) > 0 {
+		if err := b.sink.WriteBlocks(ctx, blocks); err != nil {
+			b.requeue(ops, blocks)
+			return err
+		}
+	}
+	if len(ops) > 0 {
+		if err := b.sink.WriteOps(ctx, ops); err != nil {
+			b.requeue(ops, nil)
+			return err
+		}
+	}
+	return b.sink.Flush(ctx)
+}
+
+// requeue puts unwritten data back in front of data buffered meanwhile.
+func (b *BatchSink) requeue(ops []*Op, blocks []*Block) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ops = append(ops, b.ops...)
+	b.blocks = append(blocks, b.blocks...)
+	b.startTimerLocked()
+}
+
+// Close flushes remaining data and returns any pending error from
+// a background flush.
+func (b *BatchSink) Close(ctx context.Context) error {
+	if err := b.Flush(ctx); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+func (b *BatchSink) lenLocked() int {
+	return len(b.ops) + len(b.blocks)
+}
+
+func (b *BatchSink) startTimerLocked() {
+	if b.timer != nil || b.lenLocked() == 0 {
+		return
+	}
+	b.timer = time.AfterFunc(b.delay, func() {
+		if err := b.Flush(context.Background()); err != nil {
+			log.Errorf("batch sink: %v", err)
+			b.mu.Lock()
+			b.err = err
+			b.mu.Unlock()
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxResponseSize is the response size limit of new clients in
+// bytes, 0 = unlimited.
+var DefaultMaxResponseSize int64 = 0
+
+// ErrResponseTooLarge is returned when a response body exceeds the
+// client's size limit. Reading stops at the limit.
+type ErrResponseTooLarge struct {
+	Limit   int64
+	Request string
+}
+
+func (e ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response exceeds %d bytes: %s", e.Limit, e.Request)
+}
+
+func IsErrResponseTooLarge(err error) (ErrResponseTooLarge, bool) {
+	var e ErrResponseTooLarge
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// UseMaxResponseSize limits the size of decompressed response bodies.
+// Larger responses are aborted with ErrResponseTooLarge instead of being
+// read into memory, which protects small containers from mistaken queries
+// with huge limits. Zero or negative values disable the limit.
+func (c *Client) UseMaxResponseSize(n int64) {
+	if n < 0 {
+		n = 0
+	}
+	c.maxRespSize = n
+}
+
+func (c *Client) MaxResponseSize() int64 {
+	return c.maxRespSize
+}
+
+// limitResponse fails early when the announced content length exceeds the
+// limit and otherwise wraps the body to abort reading at the limit.
+func (c *Client) limitResponse(req *request, resp *http.Response) error {
+	if c.maxRespSize <= 0 {
+		return nil
+	}
+	e := ErrResponseTooLarge{Limit: c.maxRespSize, Request: req.String()}
+	if resp.ContentLength > c.maxRespSize {
+		resp.Body.Close()
+		return e
+	}
+	resp.Body = &limitBody{body: resp.Body, n: c.maxRespSize, err: e}
+	return nil
+}
+
+type limitBody struct {
+	body io.ReadCloser
+	n    int64
+	err  ErrResponseTooLarge
+}
+
+func (b *limitBody) Read(p []byte) (int, error) {
+	if b.n <= 0 {
+		// probe for more data to tell a body of exactly n bytes from a
+		// larger one
+		var buf [1]byte
+		if n, _ := b.body.Read(buf[:]); n > 0 {
+			return 0, b.err
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.n {
+		p = p[:b.n]
+	}
+	n, err := b.body.Read(p)
+	b.n -= int64(n)
+	return n, err
+}
+
+func (b *limitBody) Close() error {
+	return b.body.Close()
+}
@@ -8,7 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"sort"
 	"time"
 
 	"blockwatch.cc/tzgo/tezos"
@@ -87,8 +87,8 @@ func (s *Snapshot) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
 		return s.UnmarshalJSONBrief(data)
 	}
-	type Alias *Snapshot
-	return json.Unmarshal(data, Alias(s))
+	type Alias Snapshot
+	return json.Unmarshal(data, (*Alias)(s))
 }
 
 func (s *Snapshot) UnmarshalJSONBrief(data []byte) error {
@@ -100,58 +100,15 @@ func (s *Snapshot) UnmarshalJSONBrief(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRowLen(unpacked, s.columns); err != nil {
+		return err
+	}
 	for i, v := range s.columns {
 		f := unpacked[i]
 		if f == nil {
 			continue
 		}
-		switch v {
-		case "row_id":
-			snap.RowId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "height":
-			snap.Height, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "cycle":
-			snap.Cycle, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "is_selected":
-			snap.IsSelected, err = strconv.ParseBool(f.(json.Number).String())
-		case "time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				snap.Timestamp = time.Unix(0, ts*1000000).UTC()
-			}
-		case "index":
-			snap.Index, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "rolls":
-			snap.Rolls, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "address":
-			snap.Address, err = tezos.ParseAddress(f.(string))
-		case "account_id":
-			snap.AccountId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "baker":
-			snap.Baker, err = tezos.ParseAddress(f.(string))
-		case "baker_id":
-			snap.BakerId, err = strconv.ParseUint(f.(json.Number).String(), 10, 64)
-		case "is_baker":
-			snap.IsBaker, err = strconv.ParseBool(f.(json.Number).String())
-		case "is_active":
-			snap.IsActive, err = strconv.ParseBool(f.(json.Number).String())
-		case "balance":
-			snap.Balance, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "delegated":
-			snap.Delegated, err = strconv.ParseFloat(f.(json.Number).String(), 64)
-		case "n_delegations":
-			snap.NDelegations, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "since":
-			snap.Since, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-		case "since_time":
-			var ts int64
-			ts, err = strconv.ParseInt(f.(json.Number).String(), 10, 64)
-			if err == nil {
-				snap.SinceTime = time.Unix(0, ts*1000000).UTC()
-			}
-		}
-		if err != nil {
+		if _, err := snap.decodeBriefColumn(v, f); err != nil {
 			return err
 		}
 	}
@@ -201,3 +158,77 @@ func (c *Client) QuerySnapshots(ctx context.Context, filter FilterList, cols []s
 	}
 	return q.Run(ctx)
 }
+
+// DelegatorStake is a single delegator's contribution to a baker's
+// staking balance.
+type DelegatorStake struct {
+	Address tezos.Address `json:"address"`
+	Balance float64       `json:"balance"`
+	Share   float64       `json:"share"`
+}
+
+// StakeComposition splits a baker's staking balance at a snapshot into the
+// baker's own funds and delegated funds per delegator. Delegators are
+// sorted by balance, largest first.
+type StakeComposition struct {
+	Baker      tezos.Address    `json:"baker"`
+	Height     int64            `json:"height"`
+	Cycle      int64            `json:"cycle"`
+	Own        float64          `json:"own"`
+	Delegated  float64          `json:"delegated"`
+	Total      float64          `json:"total"`
+	Delegators []DelegatorStake `json:"delegators"`
+}
+
+// GetStakeComposition loads a baker's staking balance composition from the
+// most recent snapshot.
+func (c *Client) GetStakeComposition(ctx context.Context, baker tezos.Address) (*StakeComposition, error) {
+	q := c.NewSnapshotQuery()
+	q.WithColumns("row_id", "height", "cycle", "balance", "delegated").
+		WithFilter(FilterModeEqual, "address", baker).
+		WithDesc().
+		WithLimit(1)
+	snaps, err := q.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snaps.Len() == 0 {
+		return nil, fmt.Errorf("no snapshot for baker %s", baker)
+	}
+	s := snaps.Rows[0]
+	res := &StakeComposition{
+		Baker:      baker,
+		Height:     s.Height,
+		Cycle:      s.Cycle,
+		Own:        s.Balance,
+		Delegated:  s.Delegated,
+		Total:      s.Balance + s.Delegated,
+		Delegators: make([]DelegatorStake, 0),
+	}
+
+	q = c.NewSnapshotQuery()
+	q.WithColumns("row_id", "address", "balance").
+		WithFilter(FilterModeEqual, "height", s.Height).
+		WithFilter(FilterModeEqual, "baker", baker).
+		WithFilter(FilterModeNotEqual, "address", baker)
+	err = q.Each(ctx, func(list *SnapshotList) error {
+		for _, v := range list.Rows {
+			d := DelegatorStake{
+				Address: v.Address,
+				Balance: v.Balance,
+			}
+			if res.Total > 0 {
+				d.Share = v.Balance / res.Total
+			}
+			res.Delegators = append(res.Delegators, d)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res.Delegators, func(i, j int) bool {
+		return res.Delegators[i].Balance > res.Delegators[j].Balance
+	})
+	return res, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+const (
+	SpikeNewAccounts  = "new_accounts"
+	SpikeNewContracts = "new_contracts"
+)
+
+// SpikeThresholds controls when a value counts as spike. A value is a spike
+// when it reaches Factor times the average of the previous Window periods
+// and at least MinCount.
+type SpikeThresholds struct {
+	Window   int
+	Factor   float64
+	MinCount int
+}
+
+var (
+	DefaultBlockSpikeThresholds = SpikeThresholds{Window: 120, Factor: 5, MinCount: 50}
+	DefaultCycleSpikeThresholds = SpikeThresholds{Window: 10, Factor: 3, MinCount: 1000}
+)
+
+// Spike is an unusual number of account or contract creations in a block
+// or cycle. Block hash and height refer to the last block of the period.
+type Spike struct {
+	Metric    string          `json:"metric"`
+	IsCycle   bool            `json:"is_cycle"`
+	Block     tezos.BlockHash `json:"block"`
+	Height    int64           `json:"height"`
+	Cycle     int64           `json:"cycle"`
+	Timestamp time.Time       `json:"time"`
+	Value     int             `json:"value"`
+	Baseline  float64         `json:"baseline"`
+}
+
+// Ratio returns value divided by baseline.
+func (s *Spike) Ratio() float64 {
+	if s.Baseline == 0 {
+		return 0
+	}
+	return float64(s.Value) / s.Baseline
+}
+
+type SpikeHandler func(*Spike) error
+
+// SpikeMonitor polls the block table and reports spikes in new accounts and
+// new contracts per block and per completed cycle. Baselines are built from
+// the blocks the monitor has seen, so the first Window periods never report.
+type SpikeMonitor struct {
+	client   *Client
+	interval time.Duration
+	height   int64
+	started  bool
+	block    SpikeThresholds
+	cycle    SpikeThresholds
+	throttle *Throttle
+	history  map[string]*spikeSeries
+	current  *cycleSum
+	pending  []*Spike // spikes from the one the Run handler failed on
+	failed   *Spike   // failed spike, already passed the throttle
+}
+
+type spikeSeries struct {
+	values []int
+}
+
+type cycleSum struct {
+	last      *Block
+	accounts  int
+	contracts int
+}
+
+var SpikeColumns = []string{
+	"hash",
+	"height",
+	"cycle",
+	"time",
+	"n_new_accounts",
+	"n_new_contracts",
+}
+
+func (c *Client) NewSpikeMonitor() *SpikeMonitor {
+	return &SpikeMonitor{
+		client:   c,
+		interval: DefaultMonitorInterval,
+		block:    DefaultBlockSpikeThresholds,
+		cycle:    DefaultCycleSpikeThresholds,
+		history:  make(map[string]*spikeSeries),
+	}
+}
+
+func (m *SpikeMonitor) WithInterval(d time.Duration) *SpikeMonitor {
+	m.interval = d
+	return m
+}
+
+// WithStartHeight sets the block height after which the monitor starts.
+// Without it the monitor starts at the current chain tip.
+func (m *SpikeMonitor) WithStartHeight(h int64) *SpikeMonitor {
+	m.height = h
+	m.started = true
+	return m
+}
+
+// WithBlockThresholds sets per block thresholds. A zero Factor disables
+// per block detection.
+func (m *SpikeMonitor) WithBlockThresholds(t SpikeThresholds) *SpikeMonitor {
+	m.block = t
+	return m
+}
+
+// WithCycleThresholds sets per cycle thresholds. A zero Factor disables
+// per cycle detection.
+func (m *SpikeMonitor) WithCycleThresholds(t SpikeThresholds) *SpikeMonitor {
+	m.cycle = t
+	return m
+}
+
+// WithThrottle suppresses repeated notifications in Run. The rule is the
+// metric name and the key is "block" or "cycle".
+func (m *SpikeMonitor) WithThrottle(t *Throttle) *SpikeMonitor {
+	m.throttle = t
+	return m
+}
+
+func (m *SpikeMonitor) Height() int64 {
+	return m.height
+}
+
+// Poll fetches all blocks after the current height and returns detected
+// spikes. When a request fails, spikes of blocks loaded before the failure
+// are returned with the error.
+func (m *SpikeMonitor) Poll(ctx context.Context) ([]*Spike, error) {
+	res := m.pending
+	m.pending = nil
+	if !m.started {
+		tip, err := m.client.GetTip(ctx)
+		if err != nil {
+			return res, err
+		}
+		m.height = tip.Height
+		m.started = true
+	}
+	q := m.client.NewBlockQuery()
+	q.WithColumns(SpikeColumns...).
+		WithFilter(FilterModeGt, "height", m.height)
+	err := q.Each(ctx, func(blocks *BlockList) error {
+		for _, b := range blocks.Rows {
+			res = append(res, m.add(b)...)
+			m.height = b.Height
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (m *SpikeMonitor) add(b *Block) []*Spike {
+	var res []*Spike
+	if s := m.check("block/"+SpikeNewAccounts, m.block, b.NewAccounts); s != nil {
+		res = append(res, s.fill(SpikeNewAccounts, false, b))
+	}
+	if s := m.check("block/"+SpikeNewContracts, m.block, b.NewContracts); s != nil {
+		res = append(res, s.fill(SpikeNewContracts, false, b))
+	}
+
+	// close the running cycle when a new one starts
+	if m.current != nil && m.current.last.Cycle != b.Cycle {
+		last := m.current.last
+		if s := m.check("cycle/"+SpikeNewAccounts, m.cycle, m.current.accounts); s != nil {
+			res = append(res, s.fill(SpikeNewAccounts, true, last))
+		}
+		if s := m.check("cycle/"+SpikeNewContracts, m.cycle, m.current.contracts); s != nil {
+			res = append(res, s.fill(SpikeNewContracts, true, last))
+		}
+		m.current = nil
+	}
+	if m.current == nil {
+		m.current = &cycleSum{}
+	}
+	m.current.last = b
+	m.current.accounts += b.NewAccounts
+	m.current.contracts += b.NewContracts
+	return res
+}
+
+// check compares v against the series baseline and appends v to the series.
+func (m *SpikeMonitor) check(key string, t SpikeThresholds, v int) *Spike {
+	if t.Factor <= 0 || t.Window <= 0 {
+		return nil
+	}
+	s, ok := m.history[key]
+	if !ok {
+		s = &spikeSeries{}
+		m.history[key] = s
+	}
+	var spike *Spike
+	if len(s.values) >= t.Window {
+		var sum int
+		for _, x := range s.values {
+			sum += x
+		}
+		avg := float64(sum) / float64(len(s.values))
+		if v >= t.MinCount && float64(v) >= avg*t.Factor {
+			spike = &Spike{Value: v, Baseline: avg}
+		}
+		s.values = s.values[1:]
+	}
+	s.values = append(s.values, v)
+	return spike
+}
+
+func (s *Spike) fill(metric string, isCycle bool, b *Block) *Spike {
+	s.Metric = metric
+	s.IsCycle = isCycle
+	s.Block = b.Hash
+	s.Height = b.Height
+	s.Cycle = b.Cycle
+	s.Timestamp = b.Timestamp
+	return s
+}
+
+// Run polls for new spikes until the context is canceled or the handler
+// returns an error. Spikes from the one the handler failed on are kept
+// and returned first by the next Poll or Run.
+func (m *SpikeMonitor) Run(ctx context.Context, fn SpikeHandler) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		list, err := m.Poll(ctx)
+		for i, v := range list {
+			scope := "block"
+			if v.IsCycle {
+				scope = "cycle"
+			}
+			if v != m.failed && m.throttle != nil && !m.throttle.Allow(v.Metric, scope) {
+				continue
+			}
+			if err := fn(v); err != nil {
+				m.pending, m.failed = list[i:], v
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
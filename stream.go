@@ -0,0 +1,654 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Each and Stream follow result cursors until all matching rows are
+// processed or the callback returns an error, which is then returned.
+// Each calls fn once per result page, Stream once per row. The query is
+// not modified.
+
+// When ctx is cancelled or times out, Each and Stream return an
+// ErrPartialResult with the cursor to resume from. Op and block streams
+// resume after the last row fn accepted, other queries after the last
+// completed page.
+//
+// With WithCheckpoint the resume cursor is also saved to a checkpoint
+// store after every page and on interruption. A query without cursor
+// starts from the stored checkpoint, so an interrupted export continues
+// where it stopped when run again.
+//
+// Before each page the loops check the remaining time until the context
+// deadline. When less time is left than the previous page took, they stop
+// with an ErrPartialResult wrapping ErrNoBudget instead of failing in the
+// middle of a request.
+//
+// WithDedup and WithGapHook guard against duplicate and missing rows
+// when the table changes between pages, see RowGap.
+
+// WithCheckpoint makes Each and Stream save progress under name in s.
+func (q *tableQuery) WithCheckpoint(s CheckpointStore, name string) TableQuery {
+	q.ckpt = s
+	q.ckptName = name
+	return q
+}
+
+// pager tracks progress of a cursor loop.
+type pager struct {
+	cursor uint64
+	rows   int
+	store  CheckpointStore
+	name   string
+	guard  *rowGuard     // optional dedup and gap checks
+	start  time.Time     // start of the current page
+	took   time.Duration // duration of the previous page
+}
+
+// startPager loads the query cursor from a checkpoint if configured.
+func (q *tableQuery) startPager(ctx context.Context) (*pager, error) {
+	p := &pager{
+		store: q.ckpt,
+		name:  q.ckptName,
+	}
+	if p.store != nil && q.Cursor == 0 {
+		c, err := p.store.LoadCheckpoint(ctx, p.name)
+		if err != nil {
+			return nil, fmt.Errorf("loading checkpoint %s: %w", p.name, err)
+		}
+		q.Cursor = c
+	}
+	p.cursor = q.Cursor
+	p.guard = q.newRowGuard(ctx)
+	return p, nil
+}
+
+// budget stops the loop when the context deadline leaves less time than
+// the previous page took, then starts timing the next page.
+func (p *pager) budget(ctx context.Context) error {
+	if p.took > 0 && !HasBudget(ctx, p.took) {
+		err := ctx.Err()
+		if err == nil {
+			err = ErrNoBudget
+		}
+		return p.partial(err)
+	}
+	p.start = time.Now()
+	return nil
+}
+
+// next records a processed page.
+func (p *pager) next(ctx context.Context, n int, cursor uint64) error {
+	if !p.start.IsZero() {
+		p.took = time.Since(p.start)
+	}
+	if n == 0 {
+		return nil
+	}
+	p.rows += n
+	p.cursor = cursor
+	if p.guard != nil {
+		p.guard.page(cursor)
+	}
+	if p.store == nil {
+		return nil
+	}
+	if err := p.store.SaveCheckpoint(ctx, p.name, cursor); err != nil {
+		return fmt.Errorf("saving checkpoint %s: %w", p.name, err)
+	}
+	return nil
+}
+
+// filter drops duplicate rows from list l.
+func (p *pager) filter(l interface{}) {
+	if p.guard != nil {
+		p.guard.filter(l)
+	}
+}
+
+// keep returns false for duplicate rows.
+func (p *pager) keep(id uint64) bool {
+	return p.guard == nil || p.guard.keep(id)
+}
+
+// fail returns err, wrapped into ErrPartialResult when ctx is done.
+func (p *pager) fail(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return p.partial(err)
+}
+
+// partial saves the checkpoint and returns an ErrPartialResult wrapping err.
+func (p *pager) partial(err error) error {
+	if p.store != nil {
+		// ctx may be done, save with a fresh context
+		_ = p.store.SaveCheckpoint(context.Background(), p.name, p.cursor)
+	}
+	return ErrPartialResult{
+		Cursor: p.cursor,
+		Rows:   p.rows,
+		Err:    err,
+	}
+}
+
+// isLastPage returns true when a page ends the cursor loop.
+func isLastPage(n, limit int) bool {
+	return n == 0 || n < limit
+}
+
+func (q OpQuery) Each(ctx context.Context, fn func(*OpList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+// Stream decodes rows while reading each response, so memory use does not
+// grow with the page size.
+func (q OpQuery) Stream(ctx context.Context, fn func(*Op) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	if p.guard != nil {
+		next := fn
+		fn = func(o *Op) error {
+			if !p.keep(o.Id) {
+				return nil
+			}
+			return next(o)
+		}
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		n, cursor, err := q.RunFunc(ctx, fn)
+		if err != nil {
+			p.rows += n
+			if n > 0 {
+				p.cursor = cursor
+			}
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q BlockQuery) Each(ctx context.Context, fn func(*BlockList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+// Stream decodes rows while reading each response, so memory use does not
+// grow with the page size.
+func (q BlockQuery) Stream(ctx context.Context, fn func(*Block) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	if p.guard != nil {
+		next := fn
+		fn = func(b *Block) error {
+			if !p.keep(b.RowId) {
+				return nil
+			}
+			return next(b)
+		}
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		n, cursor, err := q.RunFunc(ctx, fn)
+		if err != nil {
+			p.rows += n
+			if n > 0 {
+				p.cursor = cursor
+			}
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q AccountQuery) Each(ctx context.Context, fn func(*AccountList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q AccountQuery) Stream(ctx context.Context, fn func(*Account) error) error {
+	return q.Each(ctx, func(l *AccountList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q ContractQuery) Each(ctx context.Context, fn func(*ContractList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q ContractQuery) Stream(ctx context.Context, fn func(*Contract) error) error {
+	return q.Each(ctx, func(l *ContractList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q BigmapQuery) Each(ctx context.Context, fn func(*BigmapRowList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q BigmapQuery) Stream(ctx context.Context, fn func(*BigmapRow) error) error {
+	return q.Each(ctx, func(l *BigmapRowList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q BigmapUpdateQuery) Each(ctx context.Context, fn func(*BigmapUpdateRowList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q BigmapUpdateQuery) Stream(ctx context.Context, fn func(*BigmapUpdateRow) error) error {
+	return q.Each(ctx, func(l *BigmapUpdateRowList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q BigmapValueQuery) Each(ctx context.Context, fn func(*BigmapValueRowList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q BigmapValueQuery) Stream(ctx context.Context, fn func(*BigmapValueRow) error) error {
+	return q.Each(ctx, func(l *BigmapValueRowList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q ChainQuery) Each(ctx context.Context, fn func(*ChainList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q ChainQuery) Stream(ctx context.Context, fn func(*Chain) error) error {
+	return q.Each(ctx, func(l *ChainList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q ConstantQuery) Each(ctx context.Context, fn func(*ConstantList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q ConstantQuery) Stream(ctx context.Context, fn func(*Constant) error) error {
+	return q.Each(ctx, func(l *ConstantList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q CycleRightsQuery) Each(ctx context.Context, fn func(*CycleRightsList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q CycleRightsQuery) Stream(ctx context.Context, fn func(*CycleRights) error) error {
+	return q.Each(ctx, func(l *CycleRightsList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q SnapshotQuery) Each(ctx context.Context, fn func(*SnapshotList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+func (q SnapshotQuery) Stream(ctx context.Context, fn func(*Snapshot) error) error {
+	return q.Each(ctx, func(l *SnapshotList) error {
+		for _, v := range l.Rows {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q RawQuery) Each(ctx context.Context, fn func(*RawList) error) error {
+	p, err := q.startPager(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := p.budget(ctx); err != nil {
+			return err
+		}
+		l, err := q.Run(ctx)
+		if err != nil {
+			return p.fail(ctx, err)
+		}
+		n, cursor := l.Len(), l.Cursor()
+		p.filter(l)
+		if err := fn(l); err != nil {
+			return p.fail(ctx, err)
+		}
+		if err := p.next(ctx, n, cursor); err != nil {
+			return err
+		}
+		if isLastPage(n, q.Limit) {
+			return nil
+		}
+		q.Cursor = cursor
+	}
+}
@@ -4,12 +4,16 @@
 package tzstats
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Filter struct {
@@ -24,10 +28,15 @@ func (l *FilterList) Add(mode FilterMode, col string, val ...interface{}) {
 	*l = append(*l, Filter{
 		Mode:   mode,
 		Column: col,
-		Value:  ToString(val),
+		Value:  filterValue(val),
 	})
 }
 
+// key returns the url query key of the filter, e.g. sender.eq.
+func (f Filter) key(version ApiVersion) string {
+	return version.ColumnName(f.Column) + "." + string(f.Mode)
+}
+
 type FilterMode string
 
 const (
@@ -53,8 +62,9 @@ const (
 type FormatType string
 
 const (
-	FormatJSON FormatType = "json"
-	FormatCSV  FormatType = "csv"
+	FormatJSON   FormatType = "json"
+	FormatCSV    FormatType = "csv"
+	FormatNDJSON FormatType = "ndjson" // client-side only, see RunToWriter
 )
 
 type TableQuery interface {
@@ -63,28 +73,62 @@ type TableQuery interface {
 	ResetFilter() TableQuery
 	WithLimit(limit int) TableQuery
 	WithColumns(cols ...string) TableQuery
+	WithColumnsPreset(name string) TableQuery
 	WithOrder(order OrderType) TableQuery
 	WithDesc() TableQuery
 	WithVerbose() TableQuery
 	WithQuiet() TableQuery
 	WithFormat(format FormatType) TableQuery
 	WithPrim() TableQuery
+	WithRawParam(key, value string) TableQuery
+	WithCheckpoint(s CheckpointStore, name string) TableQuery
+	WithDedup(n int) TableQuery
+	WithGapHook(fn func(RowGap)) TableQuery
+	And(mode FilterMode, col string, vals ...interface{}) TableQuery
+	AndEq(col string, val interface{}) TableQuery
+	AndNe(col string, val interface{}) TableQuery
+	AndGt(col string, val interface{}) TableQuery
+	AndGte(col string, val interface{}) TableQuery
+	AndLt(col string, val interface{}) TableQuery
+	AndLte(col string, val interface{}) TableQuery
+	AndIn(col string, vals ...interface{}) TableQuery
+	AndNotIn(col string, vals ...interface{}) TableQuery
+	AndRange(col string, from, to interface{}) TableQuery
+	AndRegexp(col string, expr string) TableQuery
+	WithExpr(e FilterExpr) TableQuery
+	WithTimeRange(from, to time.Time) TableQuery
+	WithHeightRange(from, to int64) TableQuery
+	WithCycleRange(from, to int64) TableQuery
 	Check() error
 	Url() string
+	Urls() ([]string, error)
+	String() string
+	Estimate(ctx context.Context) (QueryEstimate, error)
 }
 
 type tableQuery struct {
 	Params
-	client  *Client
-	Table   string     // "op", "block", "chain", "flow"
-	Format  FormatType // "json", "csv"
-	Columns []string
-	Limit   int
-	Cursor  uint64
-	Verbose bool
-	Prim    bool
-	Filter  FilterList
-	Order   OrderType // asc, desc
+	client     *Client
+	Table      string     // "op", "block", "chain", "flow"
+	Format     FormatType // "json", "csv"
+	Columns    []string
+	Limit      int
+	Cursor     uint64
+	Verbose    bool
+	Prim       bool
+	Filter     FilterList
+	Order      OrderType       // asc, desc
+	auth       string          // API key override
+	timeout    time.Duration   // per page request
+	err        error           // first filter builder error
+	expr       FilterExpr      // optional filter expression
+	anyColumns bool            // skip column validation
+	prep       *preparedQuery  // set by Prepare
+	args       QueryArgs       // prepared query arguments
+	ckpt       CheckpointStore // optional, progress of Each and Stream
+	ckptName   string
+	dedup      int          // row_id dedup window for Each and Stream
+	onGap      func(RowGap) // called on missing rows between pages
 	// OrderBy string // column name
 	// Sort string // asc/desc
 }
@@ -107,7 +151,7 @@ func (q *tableQuery) ReplaceFilter(mode FilterMode, col string, val ...interface
 	for i, v := range q.Filter {
 		if v.Column == col {
 			q.Filter[i].Mode = mode
-			q.Filter[i].Value = ToString(val)
+			q.Filter[i].Value = filterValue(val)
 			return q
 		}
 	}
@@ -117,6 +161,7 @@ func (q *tableQuery) ReplaceFilter(mode FilterMode, col string, val ...interface
 
 func (q *tableQuery) ResetFilter() TableQuery {
 	q.Filter = q.Filter[:0]
+	q.err = nil
 	return q
 }
 
@@ -160,18 +205,57 @@ func (q *tableQuery) WithPrim() TableQuery {
 	return q
 }
 
+// WithRawParam sets a query argument the SDK does not model, e.g. a new or
+// experimental server parameter. Arguments set by the query itself like
+// cursor, order and filters take precedence.
+func (q *tableQuery) WithRawParam(key, value string) TableQuery {
+	q.Params = q.Params.With(key, value)
+	return q
+}
+
 func (q *tableQuery) WithCursor(c uint64) TableQuery {
 	q.Cursor = c
 	return q
 }
 
+// WithAccountIds filters an account id column such as sender_id, receiver_id
+// or baker_id. Id filters are cheaper to evaluate than address filters.
+// Use an AddressResolver to translate addresses into ids. An empty id list
+// matches no rows, so the query fails in Check instead of returning the
+// whole table.
+func (q *tableQuery) WithAccountIds(col string, ids ...uint64) TableQuery {
+	switch len(ids) {
+	case 0:
+		return q.fail(fmt.Errorf("filter %s: empty account id list", col))
+	case 1:
+		q.Filter.Add(FilterModeEqual, col, ids[0])
+	default:
+		q.Filter.Add(FilterModeIn, col, ids)
+	}
+	return q
+}
+
 func (p tableQuery) Check() error {
+	if p.prep != nil {
+		return p.prep.check(p.args)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if _, err := p.filterBranches(); err != nil {
+		return err
+	}
 	if err := p.Params.Check(); err != nil {
 		return err
 	}
 	if p.Table == "" {
 		return fmt.Errorf("empty table name")
 	}
+	if !p.anyColumns {
+		if err := checkColumns(p.Table, p.Columns); err != nil {
+			return err
+		}
+	}
 	for _, v := range p.Filter {
 		if v.Column == "" {
 			return fmt.Errorf("empty filter column name")
@@ -192,36 +276,117 @@ func (p tableQuery) Check() error {
 	return nil
 }
 
+// Url returns the request url of the query. Filter expressions which
+// expand into several requests have no single url, Url returns an empty
+// string for them and for invalid expressions. Use Urls in that case.
 func (p tableQuery) Url() string {
+	if p.prep != nil {
+		return p.prep.url(p.Cursor, p.args)
+	}
+	bs, err := p.filterBranches()
+	if err != nil || len(bs) != 1 {
+		return ""
+	}
+	return p.urlWith(bs[0])
+}
+
+// Urls returns one request url per filter expression branch.
+func (p tableQuery) Urls() ([]string, error) {
+	if p.prep != nil {
+		return []string{p.prep.url(p.Cursor, p.args)}, nil
+	}
+	bs, err := p.filterBranches()
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(bs))
+	for i, b := range bs {
+		urls[i] = p.urlWith(b)
+	}
+	return urls, nil
+}
+
+// urlWith returns the query url with additional filters.
+func (p tableQuery) urlWith(extra FilterList) string {
+	q := p.values(extra)
 	if p.Cursor > 0 {
-		p.Params.Query.Set("cursor", strconv.FormatUint(p.Cursor, 10))
+		q.Set("cursor", strconv.FormatUint(p.Cursor, 10))
 	}
-	if p.Limit > 0 && p.Params.Query.Get("limit") == "" {
-		p.Params.Query.Set("limit", strconv.Itoa(p.Limit))
+	return p.Params.WithValues(q).Url(p.path())
+}
+
+// values returns query arguments except the cursor.
+func (p tableQuery) values(extra FilterList) url.Values {
+	// build arguments on a private copy so repeated calls don't leak arguments
+	q := p.Params.Values()
+	if p.Limit > 0 && q.Get("limit") == "" {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	var version ApiVersion
+	if p.client != nil {
+		version = p.client.ApiVersion()
 	}
-	if len(p.Columns) > 0 && p.Params.Query.Get("columns") == "" {
-		p.Params.Query.Set("columns", strings.Join(p.Columns, ","))
+	if len(p.Columns) > 0 && q.Get("columns") == "" {
+		cols := make([]string, len(p.Columns))
+		for i, v := range p.Columns {
+			cols[i] = version.ColumnName(v)
+		}
+		q.Set("columns", strings.Join(cols, ","))
 	}
 	if p.Verbose {
-		p.Params.Query.Set("verbose", "true")
+		q.Set("verbose", "true")
 	}
-	for _, v := range p.Filter {
-		p.Params.Query.Set(v.Column+"."+string(v.Mode), ToString(v.Value))
+	for _, v := range append(p.Filter[:len(p.Filter):len(p.Filter)], extra...) {
+		q.Set(v.key(version), ToString(v.Value))
 	}
-	p.Params.Query.Set("order", string(p.Order))
+	q.Set("order", string(p.Order))
+	return q
+}
+
+func (p tableQuery) path() string {
 	format := p.Format
 	if format == "" {
 		format = FormatJSON
 	}
-	return p.Params.Url("tables/" + p.Table + "." + string(format))
+	return "tables/" + p.Table + "." + string(format)
 }
 
 func (c *Client) QueryTable(ctx context.Context, q TableQuery, result interface{}) error {
 	if err := q.Check(); err != nil {
 		return err
 	}
-	err := c.get(ctx, q.Url(), nil, result)
-	return err
+	if tq, ok := q.(*tableQuery); ok {
+		ctx = tq.authContext(ctx)
+		if tq.timeout > 0 {
+			ctx = WithRequestTimeout(ctx, tq.timeout)
+		}
+		if bs, _ := tq.filterBranches(); len(bs) > 1 {
+			if err := c.queryBranches(ctx, tq, bs, result); err != nil {
+				return err
+			}
+			c.observeRows(q, result)
+			return nil
+		}
+	}
+	tq, _ := q.(*tableQuery)
+	switch {
+	case tq != nil && tq.Format == FormatCSV:
+		if err := c.get(ctx, q.Url(), nil, newCSVResult(tq, result)); err != nil {
+			return err
+		}
+	case c.msgpack && tq != nil && (tq.Format == FormatJSON || tq.Format == ""):
+		headers := make(http.Header)
+		headers.Set("Accept", msgpackAccept)
+		if err := c.get(ctx, q.Url(), headers, newMsgpackResult(result)); err != nil {
+			return err
+		}
+	default:
+		if err := c.get(ctx, q.Url(), nil, result); err != nil {
+			return err
+		}
+	}
+	c.observeRows(q, result)
+	return nil
 }
 
 func (c *Client) StreamTable(ctx context.Context, q TableQuery, w io.Writer) (StreamResponse, error) {
@@ -232,12 +397,42 @@ func (c *Client) StreamTable(ctx context.Context, q TableQuery, w io.Writer) (St
 	headers := make(http.Header)
 	// signal upstream we accept trailers (required for some proxies to forward)
 	headers.Add("TE", "trailers")
+	if tq, ok := q.(*tableQuery); ok {
+		if bs, _ := tq.filterBranches(); len(bs) > 1 {
+			return StreamResponse{}, fmt.Errorf("cannot stream filter expression with %d branches", len(bs))
+		}
+		ctx = tq.authContext(ctx)
+		if tq.timeout > 0 {
+			ctx = WithRequestTimeout(ctx, tq.timeout)
+		}
+	}
 	if err := c.get(ctx, q.Url(), headers, w); err != nil {
 		return StreamResponse{}, err
 	}
 	return NewStreamResponse(headers)
 }
 
+// RunToWriter writes the query result to w without decoding rows. JSON
+// and CSV results are copied as sent by the server, FormatNDJSON converts
+// JSON rows into one JSON object per line keyed by column name.
+func (q tableQuery) RunToWriter(ctx context.Context, w io.Writer, format FormatType) (StreamResponse, error) {
+	switch format {
+	case FormatJSON, FormatCSV:
+		q.Format = format
+		return q.client.StreamTable(ctx, &q, w)
+	case FormatNDJSON:
+		q.Format = FormatJSON
+		nw := newNDJSONWriter(w, q.Columns)
+		res, err := q.client.StreamTable(ctx, &q, nw)
+		if cerr := nw.Close(); err == nil {
+			err = cerr
+		}
+		return res, err
+	default:
+		return StreamResponse{}, fmt.Errorf("unsupported format '%s'", format)
+	}
+}
+
 func getTableColumn(data []byte, columns []string, name string) (string, bool) {
 	idx := colIndex(columns, name)
 	if idx < 0 {
@@ -291,3 +486,85 @@ func colIndex(columns []string, name string) int {
 	}
 	return -1
 }
+
+// RawList holds undecoded table rows. Each row contains one JSON value per
+// requested column in the same order as Columns.
+type RawList struct {
+	Columns []string
+	Rows    [][]json.RawMessage
+}
+
+func (l RawList) Len() int {
+	return len(l.Rows)
+}
+
+func (l RawList) Cursor() uint64 {
+	if len(l.Rows) == 0 {
+		return 0
+	}
+	idx := colIndex(l.Columns, "row_id")
+	if idx < 0 {
+		idx = colIndex(l.Columns, "id")
+	}
+	row := l.Rows[len(l.Rows)-1]
+	if idx < 0 || idx >= len(row) {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(row[idx]), 10, 64)
+	return id
+}
+
+func (l *RawList) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || bytes.Compare(data, []byte("null")) == 0 {
+		return nil
+	}
+	if data[0] != '[' {
+		return fmt.Errorf("RawList: expected JSON array")
+	}
+	return json.Unmarshal(data, &l.Rows)
+}
+
+type RawQuery struct {
+	tableQuery
+}
+
+func (c *Client) NewRawQuery(table string) RawQuery {
+	q := tableQuery{
+		client: c,
+		Params: c.params.Copy(),
+		Table:  table,
+		Format: FormatJSON,
+		Limit:  DefaultLimit,
+		Order:  OrderAsc,
+		Filter: make(FilterList, 0),
+		// raw rows are not decoded by position
+		anyColumns: true,
+	}
+	return RawQuery{q}
+}
+
+func (q RawQuery) Run(ctx context.Context) (*RawList, error) {
+	if len(q.Columns) == 0 {
+		return nil, fmt.Errorf("raw query on table %s requires columns", q.Table)
+	}
+	result := &RawList{
+		Columns: q.Columns,
+	}
+	if err := q.client.QueryTable(ctx, &q.tableQuery, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryRaw runs a table query without decoding rows into a Go model. Use it
+// for tables or columns the SDK does not model yet.
+func (c *Client) QueryRaw(ctx context.Context, table string, filter FilterList, cols []string) (*RawList, error) {
+	q := c.NewRawQuery(table)
+	if len(cols) > 0 {
+		q.Columns = cols
+	}
+	if len(filter) > 0 {
+		q.Filter = filter
+	}
+	return q.Run(ctx)
+}
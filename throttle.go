@@ -0,0 +1,91 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle suppresses repeated notifications. Each (rule, key) pair, e.g.
+// an alert type and an address, is allowed at most once per window. Rules
+// can have individual windows. It is safe for concurrent use.
+type Throttle struct {
+	mu      sync.Mutex
+	window  time.Duration
+	rules   map[string]time.Duration
+	last    map[throttleKey]time.Time
+	dropped map[throttleKey]int
+	now     func() time.Time
+}
+
+type throttleKey struct {
+	rule string
+	key  string
+}
+
+// NewThrottle creates a throttle with a default window for all rules.
+func NewThrottle(window time.Duration) *Throttle {
+	return &Throttle{
+		window:  window,
+		rules:   make(map[string]time.Duration),
+		last:    make(map[throttleKey]time.Time),
+		dropped: make(map[throttleKey]int),
+		now:     time.Now,
+	}
+}
+
+// WithRule sets the window for a single rule.
+func (t *Throttle) WithRule(rule string, window time.Duration) *Throttle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rules[rule] = window
+	return t
+}
+
+// Allow reports whether a notification for rule and key may be sent now
+// and records it. Suppressed notifications are counted.
+func (t *Throttle) Allow(rule, key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	window, ok := t.rules[rule]
+	if !ok {
+		window = t.window
+	}
+	k := throttleKey{rule, key}
+	if last, ok := t.last[k]; ok && now.Sub(last) < window {
+		t.dropped[k]++
+		return false
+	}
+	t.last[k] = now
+	delete(t.dropped, k)
+	t.gc(now)
+	return true
+}
+
+// Suppressed returns how many notifications for rule and key were dropped
+// since the last allowed one.
+func (t *Throttle) Suppressed(rule, key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped[throttleKey{rule, key}]
+}
+
+// gc removes expired entries once the table has grown.
+func (t *Throttle) gc(now time.Time) {
+	if len(t.last) < 1024 {
+		return
+	}
+	for k, v := range t.last {
+		window, ok := t.rules[k.rule]
+		if !ok {
+			window = t.window
+		}
+		if now.Sub(v) >= window {
+			delete(t.last, k)
+			delete(t.dropped, k)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoBudget stops pagination loops when the time left until the context
+// deadline is shorter than the previous page took.
+var ErrNoBudget = errors.New("deadline budget exhausted")
+
+type timeoutContextKey struct{}
+
+// WithRequestTimeout returns a context which limits each single HTTP
+// request made with it to d, independent of the deadline of the context
+// itself. Use it to prevent one slow page from consuming the budget of a
+// whole pagination loop.
+func WithRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutContextKey{}, d)
+}
+
+// WithTimeout limits each page request of this query to d.
+func (q *tableQuery) WithTimeout(d time.Duration) TableQuery {
+	q.timeout = d
+	return q
+}
+
+// requestContext applies a per-request timeout from ctx.
+func requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d, ok := ctx.Value(timeoutContextKey{}).(time.Duration); ok && d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// Budget returns the time left until the context deadline. It returns
+// false when the context has no deadline.
+func Budget(ctx context.Context) (time.Duration, bool) {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(dl), true
+}
+
+// HasBudget reports whether at least need time is left before the context
+// deadline. Pagination loops can check it before fetching the next page
+// to stop cleanly instead of failing with a deadline error mid-request.
+// Contexts without deadline always have budget unless canceled.
+func HasBudget(ctx context.Context, need time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	left, ok := Budget(ctx)
+	return !ok || left >= need
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RoundDuration returns the duration of a consensus round under Tenderbake
+// protocol constants. Round 0 lasts minimal_block_delay seconds and each
+// later round is longer by delay_increment_per_round.
+func RoundDuration(cfg *BlockchainConfig, round int) time.Duration {
+	secs := cfg.MinimalBlockDelay + round*cfg.DelayIncrementPerRound
+	return time.Duration(secs) * time.Second
+}
+
+// Distribution summarizes a set of samples.
+type Distribution struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+func newDistribution(vals []float64) Distribution {
+	d := Distribution{Count: len(vals)}
+	if len(vals) == 0 {
+		return d
+	}
+	sort.Float64s(vals)
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	d.Min = vals[0]
+	d.Max = vals[len(vals)-1]
+	d.Mean = sum / float64(len(vals))
+	d.P50 = percentile(vals, 0.5)
+	d.P90 = percentile(vals, 0.9)
+	d.P99 = percentile(vals, 0.99)
+	return d
+}
+
+// percentile uses nearest rank on sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// RoundStats describes consensus timing over a range of blocks. Solvetime
+// is the time between a block and its predecessor in seconds, Delay is the
+// part of it exceeding the round 0 duration. Endorsement is the share of
+// consensus slots endorsed in percent.
+type RoundStats struct {
+	From        int64         `json:"from"`
+	To          int64         `json:"to"`
+	Blocks      int           `json:"blocks"`
+	Rounds      map[int]int   `json:"rounds"`
+	PctRound0   float64       `json:"pct_round_0"`
+	Solvetime   Distribution  `json:"solvetime"`
+	Delay       Distribution  `json:"delay"`
+	Endorsement Distribution  `json:"endorsement"`
+	Round0      time.Duration `json:"round_0"`
+}
+
+// GetRoundStats analyzes block rounds, solvetimes and endorsement slots in
+// the height range [from, to] using the protocol constants of the current
+// configuration. Use it to monitor consensus health. When the context
+// deadline leaves no time for another page, statistics of the blocks
+// read so far are returned with an ErrPartialResult and To is set to the
+// last analyzed height.
+func (c *Client) GetRoundStats(ctx context.Context, from, to int64) (*RoundStats, error) {
+	if to < from {
+		return nil, fmt.Errorf("invalid height range %d..%d", from, to)
+	}
+	cfg, err := c.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &RoundStats{
+		From:   from,
+		To:     to,
+		Rounds: make(map[int]int),
+		Round0: RoundDuration(cfg, 0),
+	}
+	var (
+		solve, delay, endorse []float64
+		base                  = stats.Round0.Seconds()
+	)
+	q := c.NewBlockQuery()
+	q.WithColumns("row_id", "height", "solvetime", "round", "n_endorsed_slots").
+		WithFilter(FilterModeRange, "height", from, to)
+	var last int64
+	err = q.Each(ctx, func(blocks *BlockList) error {
+		for _, b := range blocks.Rows {
+			stats.Blocks++
+			stats.Rounds[b.Round]++
+			if b.Solvetime > 0 {
+				s := float64(b.Solvetime)
+				solve = append(solve, s)
+				if s > base {
+					delay = append(delay, s-base)
+				} else {
+					delay = append(delay, 0)
+				}
+			}
+			if cfg.ConsensusCommitteeSize > 0 {
+				endorse = append(endorse, float64(b.NSlotsEndorsed)*100/float64(cfg.ConsensusCommitteeSize))
+			}
+			last = b.Height
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := IsErrPartialResult(err); !ok {
+			return nil, err
+		}
+		stats.To = last
+	}
+	if stats.Blocks > 0 {
+		stats.PctRound0 = float64(stats.Rounds[0]) * 100 / float64(stats.Blocks)
+	}
+	stats.Solvetime = newDistribution(solve)
+	stats.Delay = newDistribution(delay)
+	stats.Endorsement = newDistribution(endorse)
+	return stats, err
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// TokenInfo describes how raw integer amounts of a FA1.2 or FA2 token are
+// scaled for display. FA1.2 tokens use token id 0.
+type TokenInfo struct {
+	Contract tezos.Address `json:"contract"`
+	TokenId  int64         `json:"token_id"`
+	Symbol   string        `json:"symbol"`
+	Decimals int           `json:"decimals"`
+}
+
+func (t TokenInfo) key() string {
+	return tokenKey(t.Contract, t.TokenId)
+}
+
+func tokenKey(addr tezos.Address, id int64) string {
+	return addr.String() + "/" + strconv.FormatInt(id, 10)
+}
+
+// Format renders a raw token amount as decimal string.
+func (t TokenInfo) Format(v *big.Int) string {
+	return FormatTokenAmount(v, t.Decimals)
+}
+
+// Float returns a raw token amount scaled to a float. Precision may be lost
+// for large amounts, use Format for exact values.
+func (t TokenInfo) Float(v *big.Int) float64 {
+	f, _ := strconv.ParseFloat(t.Format(v), 64)
+	return f
+}
+
+// Parse converts a decimal string into a raw token amount.
+func (t TokenInfo) Parse(s string) (*big.Int, error) {
+	return ParseTokenAmount(s, t.Decimals)
+}
+
+// TokenRegistry resolves token decimals and symbols. Tokens which are not
+// registered are loaded from token metadata on first use and cached.
+type TokenRegistry struct {
+	client *Client
+	mu     sync.RWMutex
+	tokens map[string]TokenInfo
+}
+
+// KnownTokens is a list of well-known mainnet tokens which new registries
+// are seeded with. Modify before creating a client or call Register on the
+// client's registry to override entries.
+var KnownTokens = []TokenInfo{
+	{tezos.MustParseAddress("KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"), 0, "tzBTC", 8},
+	{tezos.MustParseAddress("KT1XnTn74bUtxHfDtBmm2bGZAQfhPbvKWR8o"), 0, "USDt", 6},
+	{tezos.MustParseAddress("KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV"), 0, "kUSD", 18},
+	{tezos.MustParseAddress("KT1SjXiUX63QvdNMcM2m492f7kuf8JxXRLp4"), 0, "ctez", 6},
+	{tezos.MustParseAddress("KT1LN4LPSqTMS7Sd2CJw4bbDGRkMv2t68Fy9"), 0, "USDtz", 6},
+	{tezos.MustParseAddress("KT19at7rQUvyjxnZ2fBv7D9zc8rkyG7gAoU8"), 0, "ETHtz", 18},
+	{tezos.MustParseAddress("KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH"), 0, "wXTZ", 6},
+	{tezos.MustParseAddress("KT1XRPEPXbZK25r3Htzp2o1x7xdMMmfocKNW"), 0, "uUSD", 12},
+	{tezos.MustParseAddress("KT1AFA2mwNUMNd4SsujE1YYp29vd8BZejyKW"), 0, "hDAO", 6},
+	{tezos.MustParseAddress("KT1GRSvLoikDsXujKgZPsGLX8k8VvR2Tq95b"), 0, "PLENTY", 18},
+	{tezos.MustParseAddress("KT193D4vozYnhGJQVtw7CoxxqphqUEEwK6Vb"), 0, "QUIPU", 6},
+}
+
+// NewTokenRegistry creates a registry seeded with KnownTokens.
+func NewTokenRegistry(c *Client) *TokenRegistry {
+	r := &TokenRegistry{
+		client: c,
+		tokens: make(map[string]TokenInfo),
+	}
+	r.Register(KnownTokens...)
+	return r
+}
+
+func (c *Client) UseTokenRegistry(r *TokenRegistry) {
+	c.tokens = r
+}
+
+func (c *Client) TokenRegistry() *TokenRegistry {
+	return c.tokens
+}
+
+// Register adds or replaces token info.
+func (r *TokenRegistry) Register(infos ...TokenInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range infos {
+		r.tokens[v.key()] = v
+	}
+}
+
+// Lookup returns registered token info without loading metadata.
+func (r *TokenRegistry) Lookup(addr tezos.Address, id int64) (TokenInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tokens[tokenKey(addr, id)]
+	return t, ok
+}
+
+// Get returns token info from the registry or loads it from token metadata.
+// Tokens without metadata are registered with zero decimals.
+func (r *TokenRegistry) Get(ctx context.Context, addr tezos.Address, id int64) (TokenInfo, error) {
+	if t, ok := r.Lookup(addr, id); ok {
+		return t, nil
+	}
+	t := TokenInfo{
+		Contract: addr,
+		TokenId:  id,
+	}
+	md, err := r.client.GetAssetMetadata(ctx, addr, id)
+	if ErrorStatus(err) == 404 {
+		// FA1.2 tokens keep metadata on the contract
+		md, err = r.client.GetAccountMetadata(ctx, addr)
+	}
+	switch {
+	case err == nil:
+		if md.Asset != nil {
+			t.Symbol = md.Asset.Symbol
+			t.Decimals = md.Asset.Decimals
+		}
+	case ErrorStatus(err) != 404:
+		return t, err
+	}
+	r.Register(t)
+	return t, nil
+}
+
+// Format renders a raw token amount using registered or loaded decimals.
+func (r *TokenRegistry) Format(ctx context.Context, addr tezos.Address, id int64, v *big.Int) (string, error) {
+	t, err := r.Get(ctx, addr, id)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(v), nil
+}
+
+// FormatTokenAmount renders a raw integer amount with decimals as exact
+// decimal string without trailing zeros.
+func FormatTokenAmount(v *big.Int, decimals int) string {
+	if v == nil {
+		return "0"
+	}
+	s := new(big.Int).Abs(v).String()
+	if decimals > 0 {
+		if len(s) <= decimals {
+			s = strings.Repeat("0", decimals-len(s)+1) + s
+		}
+		i := len(s) - decimals
+		frac := strings.TrimRight(s[i:], "0")
+		s = s[:i]
+		if frac != "" {
+			s += "." + frac
+		}
+	}
+	if v.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// ParseTokenAmount converts a decimal string into a raw integer amount.
+func ParseTokenAmount(s string, decimals int) (*big.Int, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("token amount %q exceeds %d decimals", s, decimals)
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+	v, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount %q", s)
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// PoolOptions tunes connection reuse of the HTTP transport. Go's default of
+// two idle connections per host makes parallel table exports reconnect
+// frequently, so clients created without http.Client use DefaultPoolOptions.
+type PoolOptions struct {
+	MaxIdleConns        int           // idle connections across all hosts, 0 = unlimited
+	MaxIdleConnsPerHost int           // idle connections kept per host
+	MaxConnsPerHost     int           // total connections per host, 0 = unlimited
+	IdleConnTimeout     time.Duration // close idle connections after this time
+	DisableHTTP2        bool          // use HTTP/1.1 only
+}
+
+var DefaultPoolOptions = PoolOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 32,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// UsePoolOptions applies connection pool settings to the client's HTTP
+// transport.
+func (c *Client) UsePoolOptions(opts PoolOptions) {
+	t := c.httpTransport()
+	opts.apply(t)
+	c.UseTransport(t)
+}
+
+func (o PoolOptions) apply(t *http.Transport) {
+	t.MaxIdleConns = o.MaxIdleConns
+	t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = o.MaxConnsPerHost
+	t.IdleConnTimeout = o.IdleConnTimeout
+	if o.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	} else {
+		t.ForceAttemptHTTP2 = true
+		t.TLSNextProto = nil
+	}
+}
+
+// newPoolTransport returns a clone of the default transport configured
+// with pool options.
+func newPoolTransport(opts PoolOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	opts.apply(t)
+	return t
+}
+
+// UseTransport replaces the HTTP transport of the client. Middlewares
+// added with Use stay in place. The http.Client passed to NewClient is not
+// modified.
+func (c *Client) UseTransport(rt http.RoundTripper) {
+	c.transport = rt
+	c.applyTransport()
+}
+
+// UseProxy sends all requests through an HTTP, HTTPS or SOCKS5 proxy, e.g.
+// "socks5://localhost:1080".
+func (c *Client) UseProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	t := c.httpTransport()
+	t.Proxy = http.ProxyURL(u)
+	c.UseTransport(t)
+	return nil
+}
+
+// UseUnixSocket connects to the API through a unix domain socket, e.g. to
+// talk to an indexer sidecar. The host of the API URL is only used in
+// request headers.
+func (c *Client) UseUnixSocket(path string) {
+	t := c.httpTransport()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+	c.UseTransport(t)
+}
+
+// httpTransport returns a copy of the current transport or the default
+// transport for modification.
+func (c *Client) httpTransport() *http.Transport {
+	rt := c.transport
+	if rt == nil {
+		rt = c.httpClient.Transport
+	}
+	if t, ok := rt.(*http.Transport); ok {
+		return t.Clone()
+	}
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
+
+// applyTransport installs the base transport wrapped by all middlewares.
+func (c *Client) applyTransport() {
+	if c.transport == nil {
+		c.transport = c.httpClient.Transport
+		if c.transport == nil {
+			c.transport = http.DefaultTransport
+		}
+	}
+	rt := c.transport
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	hc := *c.httpClient
+	hc.Transport = rt
+	c.httpClient = &hc
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package tzstatsbench contains decoder benchmarks over synthetic table
+// payloads and a harness to compare results across releases. Payloads are
+// served from memory to a regular client, so each benchmark measures the
+// full table query path from response body to decoded list.
+//
+// The benchmarks are exported so they can be called from a _test.go file
+// in any package or run standalone with Run:
+//
+//	res := tzstatsbench.Run("")
+//	tzstatsbench.WriteResults(os.Stdout, res)
+//
+// See scripts/bench for a command line tool which stores baselines and
+// fails when a release regresses.
+package tzstatsbench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"blockwatch.cc/tzstats-go"
+)
+
+const (
+	PageRows       = 50000 // default table page size
+	BigmapPageRows = 5000
+	BigmapUpdates  = 20 // bigmap updates per op in bigmap-heavy pages
+)
+
+// Benchmark is a named benchmark which decodes Rows rows per iteration.
+type Benchmark struct {
+	Name string
+	Rows int
+	F    func(b *testing.B)
+}
+
+// Benchmarks lists all benchmarks run by Run.
+var Benchmarks = []Benchmark{
+	{"DecodeOpsPage", PageRows, BenchmarkDecodeOpsPage},
+	{"DecodeBlocksPage", PageRows, BenchmarkDecodeBlocksPage},
+	{"DecodeBigmapOpsPage", BigmapPageRows, BenchmarkDecodeBigmapOpsPage},
+}
+
+func BenchmarkDecodeOpsPage(b *testing.B) {
+	benchOps(b, OpsPage(PageRows, 0))
+}
+
+func BenchmarkDecodeBigmapOpsPage(b *testing.B) {
+	benchOps(b, OpsPage(BigmapPageRows, BigmapUpdates))
+}
+
+func BenchmarkDecodeBlocksPage(b *testing.B) {
+	page := BlocksPage(PageRows)
+	c := newClient(b, page)
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := c.NewBlockQuery()
+		q.Columns = BlockColumns
+		q.Limit = PageRows
+		l, err := q.Run(context.Background())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if l.Len() != PageRows {
+			b.Fatalf("decoded %d rows, want %d", l.Len(), PageRows)
+		}
+	}
+}
+
+// benchOps runs an op table query against page with OpQuery.Run.
+func benchOps(b *testing.B, page []byte) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(page, &rows); err != nil {
+		b.Fatal(err)
+	}
+	c := newClient(b, page)
+	run := func() {
+		q := c.NewOpQuery()
+		q.Columns = OpColumns
+		q.Limit = len(rows)
+		l, err := q.Run(context.Background())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if l.Len() != len(rows) {
+			b.Fatalf("decoded %d rows, want %d", l.Len(), len(rows))
+		}
+	}
+	// load contract scripts into the client cache
+	run()
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run()
+	}
+}
+
+// newClient returns a client which answers table queries with page and
+// contract script requests with ScriptResponse.
+func newClient(b *testing.B, page []byte) *tzstats.Client {
+	c, err := tzstats.NewClient("http://localhost", &http.Client{
+		Transport: fixture{page: page, script: ScriptResponse},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
+
+// fixture is a transport which serves recorded response bodies.
+type fixture struct {
+	page   []byte
+	script []byte
+}
+
+func (f fixture) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := f.page
+	if strings.HasPrefix(req.URL.Path, "/explorer/contract/") {
+		body = f.script
+	}
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Result is the outcome of one benchmark normalized per decoded row.
+type Result struct {
+	Name         string  `json:"name"`
+	Version      string  `json:"version"`
+	Rows         int     `json:"rows"`
+	N            int     `json:"n"`
+	NsPerOp      int64   `json:"ns_per_op"`
+	AllocsPerOp  int64   `json:"allocs_per_op"`
+	BytesPerOp   int64   `json:"bytes_per_op"`
+	NsPerRow     float64 `json:"ns_per_row"`
+	AllocsPerRow float64 `json:"allocs_per_row"`
+	BytesPerRow  float64 `json:"bytes_per_row"`
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%-24s %10.1f ns/row %8.2f allocs/row %10.1f B/row",
+		r.Name, r.NsPerRow, r.AllocsPerRow, r.BytesPerRow)
+}
+
+// Run executes all benchmarks whose name matches pattern. An empty
+// pattern runs all benchmarks.
+func Run(pattern string) ([]Result, error) {
+	var match *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if match, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	res := make([]Result, 0, len(Benchmarks))
+	for _, v := range Benchmarks {
+		if match != nil && !match.MatchString(v.Name) {
+			continue
+		}
+		br := testing.Benchmark(v.F)
+		if br.N == 0 {
+			return nil, fmt.Errorf("benchmark %s failed", v.Name)
+		}
+		rows := float64(v.Rows)
+		res = append(res, Result{
+			Name:         v.Name,
+			Version:      tzstats.ClientVersion,
+			Rows:         v.Rows,
+			N:            br.N,
+			NsPerOp:      br.NsPerOp(),
+			AllocsPerOp:  br.AllocsPerOp(),
+			BytesPerOp:   br.AllocedBytesPerOp(),
+			NsPerRow:     float64(br.NsPerOp()) / rows,
+			AllocsPerRow: float64(br.AllocsPerOp()) / rows,
+			BytesPerRow:  float64(br.AllocedBytesPerOp()) / rows,
+		})
+	}
+	return res, nil
+}
+
+func WriteResults(w io.Writer, res []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+func ReadResults(r io.Reader) ([]Result, error) {
+	var res []Result
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Regression describes a metric which got worse than allowed.
+type Regression struct {
+	Name   string
+	Metric string
+	Base   float64
+	Value  float64
+}
+
+// Change returns the relative change against the baseline, e.g. 0.1 for
+// 10% worse.
+func (r Regression) Change() float64 {
+	if r.Base == 0 {
+		return 0
+	}
+	return r.Value/r.Base - 1
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s: %.2f -> %.2f (%+.1f%%)", r.Name, r.Metric, r.Base, r.Value, r.Change()*100)
+}
+
+// Compare returns all per-row metrics in res which exceed the matching
+// baseline result by more than tolerance (0.1 = 10%). Benchmarks missing
+// from the baseline are ignored.
+func Compare(base, res []Result, tolerance float64) []Regression {
+	idx := make(map[string]Result, len(base))
+	for _, v := range base {
+		idx[v.Name] = v
+	}
+	var regs []Regression
+	for _, v := range res {
+		b, ok := idx[v.Name]
+		if !ok {
+			continue
+		}
+		for _, m := range []struct {
+			name      string
+			base, val float64
+		}{
+			{"ns/row", b.NsPerRow, v.NsPerRow},
+			{"allocs/row", b.AllocsPerRow, v.AllocsPerRow},
+			{"B/row", b.BytesPerRow, v.BytesPerRow},
+		} {
+			if m.base > 0 && m.val > m.base*(1+tolerance) {
+				regs = append(regs, Regression{v.Name, m.name, m.base, m.val})
+			}
+		}
+	}
+	return regs
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstatsbench
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// OpColumns are the op table columns used in synthetic op pages.
+var OpColumns = []string{
+	"id", "hash", "type", "block", "time", "height", "cycle", "counter",
+	"op_n", "op_p", "status", "is_success", "is_contract", "gas_limit",
+	"gas_used", "storage_limit", "storage_paid", "volume", "fee", "reward",
+	"deposit", "burned", "sender_id", "receiver_id", "sender", "receiver",
+	"entrypoint", "big_map_diff",
+}
+
+// BlockColumns are the block table columns used in synthetic block pages.
+var BlockColumns = []string{
+	"row_id", "hash", "predecessor", "time", "height", "cycle",
+	"is_cycle_snapshot", "solvetime", "version", "round", "nonce",
+	"voting_period_kind", "baker_id", "baker", "proposer_id", "proposer",
+	"n_endorsed_slots", "n_ops_applied", "n_ops_failed", "n_events",
+	"volume", "fee", "reward", "deposit", "activated_supply",
+	"minted_supply", "burned_supply", "n_accounts", "n_new_accounts",
+	"n_new_contracts", "n_cleared_accounts", "n_funded_accounts",
+	"gas_limit", "gas_used", "storage_paid", "pct_account_reuse",
+	"lb_esc_vote", "lb_esc_ema", "protocol",
+}
+
+// Payloads are deterministic for a seed so results are comparable across
+// releases.
+const Seed = 1
+
+// Contracts is the number of distinct receivers of contract calls in op
+// pages. Their scripts are loaded once and then served from the client
+// cache.
+const Contracts = 16
+
+// ScriptResponse is the contract script response served for all contract
+// calls in op pages.
+var ScriptResponse = []byte(`{"script":{"code":[` +
+	`{"prim":"parameter","args":[{"prim":"unit"}]},` +
+	`{"prim":"storage","args":[{"prim":"unit"}]},` +
+	`{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}],` +
+	`"storage":{"prim":"Unit"}}}`)
+
+var baseTime = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func randBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	return b
+}
+
+func randAddress(r *rand.Rand) tezos.Address {
+	return tezos.NewAddress(tezos.AddressTypeEd25519, randBytes(r, 20))
+}
+
+// OpsPage returns a JSON encoded op table page with n rows in brief
+// (array) format. Each contract call carries updates bigmap updates.
+func OpsPage(n, updates int) []byte {
+	r := rand.New(rand.NewSource(Seed))
+	contracts := make([]tezos.Address, Contracts)
+	for i := range contracts {
+		contracts[i] = tezos.NewAddress(tezos.AddressTypeContract, randBytes(r, 20))
+	}
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		height := int64(2500000 + i/50)
+		isContract := updates > 0
+		var diff string
+		receiver := randAddress(r)
+		if isContract {
+			diff = bigmapDiff(r, updates)
+			receiver = contracts[r.Intn(len(contracts))]
+		}
+		rows[i] = []interface{}{
+			uint64(i + 1),
+			tezos.NewOpHash(randBytes(r, 32)).String(),
+			"transaction",
+			tezos.NewBlockHash(randBytes(r, 32)).String(),
+			baseTime.Add(time.Duration(i)*time.Second).UnixNano() / 1000000,
+			height,
+			height / 8192,
+			r.Int63n(1 << 30),
+			i % 50,
+			0,
+			"applied",
+			1,
+			boolInt(isContract),
+			10600,
+			r.Int63n(10600),
+			300,
+			r.Int63n(300),
+			r.Float64() * 1000,
+			0.001,
+			0,
+			0,
+			0.0643,
+			r.Int63n(1 << 24),
+			r.Int63n(1 << 24),
+			randAddress(r).String(),
+			receiver.String(),
+			"transfer",
+			diff,
+		}
+	}
+	buf, _ := json.Marshal(rows)
+	return buf
+}
+
+func bigmapDiff(r *rand.Rand, n int) string {
+	events := make(micheline.BigmapEvents, n)
+	for i := range events {
+		key := micheline.NewPair(
+			micheline.NewBytes(randBytes(r, 22)),
+			micheline.NewInt64(r.Int63n(1000)),
+		)
+		events[i] = micheline.BigmapEvent{
+			Action:  micheline.DiffActionUpdate,
+			Id:      int64(r.Intn(4)),
+			KeyHash: tezos.NewExprHash(randBytes(r, 32)),
+			Key:     key,
+			Value:   micheline.NewInt64(r.Int63n(1 << 40)),
+		}
+	}
+	buf, _ := events.MarshalBinary()
+	return hex.EncodeToString(buf)
+}
+
+// BlocksPage returns a JSON encoded block table page with n rows in brief
+// (array) format.
+func BlocksPage(n int) []byte {
+	r := rand.New(rand.NewSource(Seed))
+	rows := make([][]interface{}, n)
+	proto := tezos.NewProtocolHash(randBytes(r, 32)).String()
+	for i := range rows {
+		height := int64(2500000 + i)
+		nonce := make([]byte, 8)
+		binary.BigEndian.PutUint64(nonce, r.Uint64())
+		rows[i] = []interface{}{
+			uint64(height + 1),
+			tezos.NewBlockHash(randBytes(r, 32)).String(),
+			tezos.NewBlockHash(randBytes(r, 32)).String(),
+			baseTime.Add(time.Duration(i)*30*time.Second).UnixNano() / 1000000,
+			height,
+			height / 8192,
+			0,
+			30,
+			12,
+			r.Intn(2),
+			hex.EncodeToString(nonce),
+			"proposal",
+			r.Int63n(1 << 16),
+			randAddress(r).String(),
+			r.Int63n(1 << 16),
+			randAddress(r).String(),
+			6900 + r.Intn(100),
+			r.Intn(500),
+			r.Intn(10),
+			r.Intn(20),
+			r.Float64() * 1e6,
+			r.Float64(),
+			20,
+			0,
+			1e6,
+			1e9,
+			1e5,
+			r.Intn(1000),
+			r.Intn(50),
+			r.Intn(5),
+			r.Intn(5),
+			r.Intn(10),
+			5200000,
+			r.Int63n(5200000),
+			r.Int63n(100000),
+			r.Float64() * 100,
+			0,
+			r.Int63n(1 << 30),
+			proto,
+		}
+	}
+	buf, _ := json.Marshal(rows)
+	return buf
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,441 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package tzstatsmock provides a fake tzstats.API for unit tests.
+//
+// Canned blocks, operations, accounts, contracts and bakers are served by
+// the matching getters, list queries return all canned rows of their type.
+// Any method can be overridden by setting its Func field. Methods without
+// canned data or override fail with tzstats.ErrNotFound.
+//
+//	m := tzstatsmock.New()
+//	m.AddBlocks(&tzstats.Block{Height: 100, Hash: hash})
+//	m.GetOpFunc = func(ctx context.Context, h tezos.OpHash, p tzstats.OpParams) ([]*tzstats.Op, error) {
+//		return nil, tzstats.ErrUnavailable
+//	}
+//	app := NewApp(m)
+package tzstatsmock
+
+import (
+	"context"
+	"sync"
+
+	"blockwatch.cc/tzgo/tezos"
+	"blockwatch.cc/tzstats-go"
+)
+
+// Call records a method invocation.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+type Client struct {
+	mu        sync.Mutex
+	calls     []Call
+	tip       *tzstats.Tip
+	status    *tzstats.Status
+	config    *tzstats.BlockchainConfig
+	blocks    []*tzstats.Block
+	ops       []*tzstats.Op
+	accounts  []*tzstats.Account
+	contracts []*tzstats.Contract
+	bakers    []*tzstats.Baker
+
+	GetStatusFunc          func(ctx context.Context) (*tzstats.Status, error)
+	GetTipFunc             func(ctx context.Context) (*tzstats.Tip, error)
+	GetConfigFunc          func(ctx context.Context) (*tzstats.BlockchainConfig, error)
+	GetHeadFunc            func(ctx context.Context, params tzstats.BlockParams) (*tzstats.Block, error)
+	GetBlockFunc           func(ctx context.Context, hash tezos.BlockHash, params tzstats.BlockParams) (*tzstats.Block, error)
+	GetBlockHeightFunc     func(ctx context.Context, height int64, params tzstats.BlockParams) (*tzstats.Block, error)
+	GetBlockOpsFunc        func(ctx context.Context, hash tezos.BlockHash, params tzstats.OpParams) ([]*tzstats.Op, error)
+	GetOpFunc              func(ctx context.Context, hash tezos.OpHash, params tzstats.OpParams) ([]*tzstats.Op, error)
+	GetAccountFunc         func(ctx context.Context, addr tezos.Address, params tzstats.AccountParams) (*tzstats.Account, error)
+	GetAccountOpsFunc      func(ctx context.Context, addr tezos.Address, params tzstats.OpParams) ([]*tzstats.Op, error)
+	GetBakerFunc           func(ctx context.Context, addr tezos.Address, params tzstats.BakerParams) (*tzstats.Baker, error)
+	ListBakersFunc         func(ctx context.Context, params tzstats.BakerParams) ([]*tzstats.Baker, error)
+	GetContractFunc        func(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.Contract, error)
+	GetContractScriptFunc  func(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.ContractScript, error)
+	GetContractStorageFunc func(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.ContractValue, error)
+	GetContractCallsFunc   func(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) ([]*tzstats.Op, error)
+	GetBigmapFunc          func(ctx context.Context, id int64, params tzstats.ContractParams) (*tzstats.Bigmap, error)
+	GetBigmapValueFunc     func(ctx context.Context, id int64, key string, params tzstats.ContractParams) (*tzstats.BigmapValue, error)
+	ListBigmapValuesFunc   func(ctx context.Context, id int64, params tzstats.ContractParams) ([]tzstats.BigmapValue, error)
+	QueryOpsFunc           func(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.OpList, error)
+	QueryBlocksFunc        func(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.BlockList, error)
+	QueryAccountsFunc      func(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.AccountList, error)
+	QueryContractsFunc     func(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.ContractList, error)
+	QueryTableFunc         func(ctx context.Context, q tzstats.TableQuery, result interface{}) error
+}
+
+var _ tzstats.API = (*Client)(nil)
+
+func New() *Client {
+	return &Client{}
+}
+
+func (m *Client) SetTip(t *tzstats.Tip) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tip = t
+}
+
+func (m *Client) SetStatus(s *tzstats.Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = s
+}
+
+func (m *Client) SetConfig(c *tzstats.BlockchainConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = c
+}
+
+func (m *Client) AddBlocks(blocks ...*tzstats.Block) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocks = append(m.blocks, blocks...)
+}
+
+func (m *Client) AddOps(ops ...*tzstats.Op) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ops = append(m.ops, ops...)
+}
+
+func (m *Client) AddAccounts(accounts ...*tzstats.Account) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts = append(m.accounts, accounts...)
+}
+
+func (m *Client) AddContracts(contracts ...*tzstats.Contract) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contracts = append(m.contracts, contracts...)
+}
+
+func (m *Client) AddBakers(bakers ...*tzstats.Baker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bakers = append(m.bakers, bakers...)
+}
+
+// Calls returns all recorded method calls in order.
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how often a method was called.
+func (m *Client) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int
+	for _, c := range m.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset clears recorded calls.
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Client) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *Client) GetStatus(ctx context.Context) (*tzstats.Status, error) {
+	m.record("GetStatus")
+	if m.GetStatusFunc != nil {
+		return m.GetStatusFunc(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status == nil {
+		return nil, tzstats.ErrNotFound
+	}
+	return m.status, nil
+}
+
+func (m *Client) GetTip(ctx context.Context) (*tzstats.Tip, error) {
+	m.record("GetTip")
+	if m.GetTipFunc != nil {
+		return m.GetTipFunc(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tip == nil {
+		return nil, tzstats.ErrNotFound
+	}
+	return m.tip, nil
+}
+
+func (m *Client) GetConfig(ctx context.Context) (*tzstats.BlockchainConfig, error) {
+	m.record("GetConfig")
+	if m.GetConfigFunc != nil {
+		return m.GetConfigFunc(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.config == nil {
+		return nil, tzstats.ErrNotFound
+	}
+	return m.config, nil
+}
+
+func (m *Client) GetHead(ctx context.Context, params tzstats.BlockParams) (*tzstats.Block, error) {
+	m.record("GetHead", params)
+	if m.GetHeadFunc != nil {
+		return m.GetHeadFunc(ctx, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var head *tzstats.Block
+	for _, b := range m.blocks {
+		if head == nil || b.Height > head.Height {
+			head = b
+		}
+	}
+	if head == nil {
+		return nil, tzstats.ErrNotFound
+	}
+	return head, nil
+}
+
+func (m *Client) GetBlock(ctx context.Context, hash tezos.BlockHash, params tzstats.BlockParams) (*tzstats.Block, error) {
+	m.record("GetBlock", hash, params)
+	if m.GetBlockFunc != nil {
+		return m.GetBlockFunc(ctx, hash, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.blocks {
+		if b.Hash.Equal(hash) {
+			return b, nil
+		}
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetBlockHeight(ctx context.Context, height int64, params tzstats.BlockParams) (*tzstats.Block, error) {
+	m.record("GetBlockHeight", height, params)
+	if m.GetBlockHeightFunc != nil {
+		return m.GetBlockHeightFunc(ctx, height, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.blocks {
+		if b.Height == height {
+			return b, nil
+		}
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetBlockOps(ctx context.Context, hash tezos.BlockHash, params tzstats.OpParams) ([]*tzstats.Op, error) {
+	m.record("GetBlockOps", hash, params)
+	if m.GetBlockOpsFunc != nil {
+		return m.GetBlockOpsFunc(ctx, hash, params)
+	}
+	return m.findOps(func(o *tzstats.Op) bool { return o.Block.Equal(hash) }), nil
+}
+
+func (m *Client) GetOp(ctx context.Context, hash tezos.OpHash, params tzstats.OpParams) ([]*tzstats.Op, error) {
+	m.record("GetOp", hash, params)
+	if m.GetOpFunc != nil {
+		return m.GetOpFunc(ctx, hash, params)
+	}
+	ops := m.findOps(func(o *tzstats.Op) bool { return o.Hash.Equal(hash) })
+	if len(ops) == 0 {
+		return nil, tzstats.ErrNotFound
+	}
+	return ops, nil
+}
+
+func (m *Client) GetAccount(ctx context.Context, addr tezos.Address, params tzstats.AccountParams) (*tzstats.Account, error) {
+	m.record("GetAccount", addr, params)
+	if m.GetAccountFunc != nil {
+		return m.GetAccountFunc(ctx, addr, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.accounts {
+		if a.Address.Equal(addr) {
+			return a, nil
+		}
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetAccountOps(ctx context.Context, addr tezos.Address, params tzstats.OpParams) ([]*tzstats.Op, error) {
+	m.record("GetAccountOps", addr, params)
+	if m.GetAccountOpsFunc != nil {
+		return m.GetAccountOpsFunc(ctx, addr, params)
+	}
+	return m.findOps(func(o *tzstats.Op) bool {
+		return o.Sender.Equal(addr) || o.Receiver.Equal(addr)
+	}), nil
+}
+
+func (m *Client) GetBaker(ctx context.Context, addr tezos.Address, params tzstats.BakerParams) (*tzstats.Baker, error) {
+	m.record("GetBaker", addr, params)
+	if m.GetBakerFunc != nil {
+		return m.GetBakerFunc(ctx, addr, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.bakers {
+		if b.Address.Equal(addr) {
+			return b, nil
+		}
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) ListBakers(ctx context.Context, params tzstats.BakerParams) ([]*tzstats.Baker, error) {
+	m.record("ListBakers", params)
+	if m.ListBakersFunc != nil {
+		return m.ListBakersFunc(ctx, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*tzstats.Baker(nil), m.bakers...), nil
+}
+
+func (m *Client) GetContract(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.Contract, error) {
+	m.record("GetContract", addr, params)
+	if m.GetContractFunc != nil {
+		return m.GetContractFunc(ctx, addr, params)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.contracts {
+		if c.Address.Equal(addr) {
+			return c, nil
+		}
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetContractScript(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.ContractScript, error) {
+	m.record("GetContractScript", addr, params)
+	if m.GetContractScriptFunc != nil {
+		return m.GetContractScriptFunc(ctx, addr, params)
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetContractStorage(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) (*tzstats.ContractValue, error) {
+	m.record("GetContractStorage", addr, params)
+	if m.GetContractStorageFunc != nil {
+		return m.GetContractStorageFunc(ctx, addr, params)
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetContractCalls(ctx context.Context, addr tezos.Address, params tzstats.ContractParams) ([]*tzstats.Op, error) {
+	m.record("GetContractCalls", addr, params)
+	if m.GetContractCallsFunc != nil {
+		return m.GetContractCallsFunc(ctx, addr, params)
+	}
+	return m.findOps(func(o *tzstats.Op) bool {
+		return o.IsContract && o.Receiver.Equal(addr)
+	}), nil
+}
+
+func (m *Client) GetBigmap(ctx context.Context, id int64, params tzstats.ContractParams) (*tzstats.Bigmap, error) {
+	m.record("GetBigmap", id, params)
+	if m.GetBigmapFunc != nil {
+		return m.GetBigmapFunc(ctx, id, params)
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) GetBigmapValue(ctx context.Context, id int64, key string, params tzstats.ContractParams) (*tzstats.BigmapValue, error) {
+	m.record("GetBigmapValue", id, key, params)
+	if m.GetBigmapValueFunc != nil {
+		return m.GetBigmapValueFunc(ctx, id, key, params)
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+func (m *Client) ListBigmapValues(ctx context.Context, id int64, params tzstats.ContractParams) ([]tzstats.BigmapValue, error) {
+	m.record("ListBigmapValues", id, params)
+	if m.ListBigmapValuesFunc != nil {
+		return m.ListBigmapValuesFunc(ctx, id, params)
+	}
+	return nil, tzstats.ErrNotFound
+}
+
+// QueryOps returns all canned operations. Filters are not evaluated.
+func (m *Client) QueryOps(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.OpList, error) {
+	m.record("QueryOps", filter, cols)
+	if m.QueryOpsFunc != nil {
+		return m.QueryOpsFunc(ctx, filter, cols)
+	}
+	return &tzstats.OpList{Rows: m.findOps(nil)}, nil
+}
+
+// QueryBlocks returns all canned blocks. Filters are not evaluated.
+func (m *Client) QueryBlocks(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.BlockList, error) {
+	m.record("QueryBlocks", filter, cols)
+	if m.QueryBlocksFunc != nil {
+		return m.QueryBlocksFunc(ctx, filter, cols)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &tzstats.BlockList{Rows: append([]*tzstats.Block(nil), m.blocks...)}, nil
+}
+
+// QueryAccounts returns all canned accounts. Filters are not evaluated.
+func (m *Client) QueryAccounts(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.AccountList, error) {
+	m.record("QueryAccounts", filter, cols)
+	if m.QueryAccountsFunc != nil {
+		return m.QueryAccountsFunc(ctx, filter, cols)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &tzstats.AccountList{Rows: append([]*tzstats.Account(nil), m.accounts...)}, nil
+}
+
+// QueryContracts returns all canned contracts. Filters are not evaluated.
+func (m *Client) QueryContracts(ctx context.Context, filter tzstats.FilterList, cols []string) (*tzstats.ContractList, error) {
+	m.record("QueryContracts", filter, cols)
+	if m.QueryContractsFunc != nil {
+		return m.QueryContractsFunc(ctx, filter, cols)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &tzstats.ContractList{Rows: append([]*tzstats.Contract(nil), m.contracts...)}, nil
+}
+
+func (m *Client) QueryTable(ctx context.Context, q tzstats.TableQuery, result interface{}) error {
+	m.record("QueryTable", q, result)
+	if m.QueryTableFunc != nil {
+		return m.QueryTableFunc(ctx, q, result)
+	}
+	return tzstats.ErrNotFound
+}
+
+func (m *Client) findOps(fn func(*tzstats.Op) bool) []*tzstats.Op {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]*tzstats.Op, 0)
+	for _, o := range m.ops {
+		if fn == nil || fn(o) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
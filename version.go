@@ -0,0 +1,109 @@
+// Copyright (c) 2020-2022 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package tzstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const headerApiVersion = "X-Api-Version"
+
+// ApiVersion identifies a TzStats API release like v012-2022-03-25. Major
+// follows the Tezos protocol number the release was made for.
+type ApiVersion struct {
+	Major int
+	Date  string
+}
+
+// The API release this SDK version was built against.
+var CurrentApiVersion = ApiVersion{Major: 12, Date: "2022-03-25"}
+
+func ParseApiVersion(s string) (ApiVersion, error) {
+	var v ApiVersion
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	major, date := s, ""
+	if i := strings.IndexByte(s, '-'); i > 0 {
+		major, date = s[:i], s[i+1:]
+	}
+	m, err := strconv.Atoi(major)
+	if err != nil {
+		return v, fmt.Errorf("invalid api version %q", s)
+	}
+	v.Major = m
+	v.Date = date
+	return v, nil
+}
+
+func (v ApiVersion) IsValid() bool {
+	return v.Major > 0
+}
+
+func (v ApiVersion) String() string {
+	s := fmt.Sprintf("v%03d", v.Major)
+	if v.Date != "" {
+		s += "-" + v.Date
+	}
+	return s
+}
+
+// Table columns renamed in API v012 (Ithaca) mapped to their former names.
+var v011ColumnNames = map[string]string{
+	"baker":    "delegate",
+	"baker_id": "delegate_id",
+	"is_baker": "is_delegate",
+	"round":    "priority",
+}
+
+// ColumnName translates an SDK column name into the name used by a server
+// running API version v.
+func (v ApiVersion) ColumnName(name string) string {
+	if !v.IsValid() || v.Major >= 12 {
+		return name
+	}
+	if n, ok := v011ColumnNames[name]; ok {
+		return n
+	}
+	return name
+}
+
+// DetectApiVersion reads the server's API version from response headers
+// or, when absent, from the protocol version of the current chain config.
+// The result is stored on the client and used to translate column names in
+// table queries.
+//
+// Translation covers the names the SDK sends, i.e. table query columns and
+// filters. Brief table rows are decoded by position and CSV headers are
+// matched with both names, so their results need no mapping. Field names
+// in verbose rows and explorer responses are not translated back.
+func (c *Client) DetectApiVersion(ctx context.Context) (ApiVersion, error) {
+	h := make(http.Header)
+	config := &BlockchainConfig{}
+	if err := c.get(ctx, "/explorer/config/head", h, config); err != nil {
+		return ApiVersion{}, err
+	}
+	v, err := ParseApiVersion(h.Get(headerApiVersion))
+	if err != nil {
+		v = ApiVersion{Major: config.Version}
+	}
+	c.UseApiVersion(v)
+	return v, nil
+}
+
+// UseApiVersion sets the API version used for column name translation.
+// It is safe to call while other requests are running.
+func (c *Client) UseApiVersion(v ApiVersion) {
+	c.versionMu.Lock()
+	c.apiVersion = v
+	c.versionMu.Unlock()
+}
+
+func (c *Client) ApiVersion() ApiVersion {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.apiVersion
+}
@@ -141,3 +141,54 @@ func (c *Client) ListBallots(ctx context.Context, id int, stage int) ([]Ballot,
 	}
 	return ballots, nil
 }
+
+// VotingPower is a baker's weight in a single voting period as recorded in
+// the listings snapshot taken at the end of the previous period.
+type VotingPower struct {
+	Baker            tezos.Address          `json:"baker"`
+	ElectionId       int                    `json:"election_id"`
+	VotingPeriod     int64                  `json:"voting_period"`
+	VotingPeriodKind tezos.VotingPeriodKind `json:"voting_period_kind"`
+	SnapshotHeight   int64                  `json:"snapshot_height"`
+	Rolls            int64                  `json:"rolls"`
+	Stake            float64                `json:"stake"`
+}
+
+// GetVotingPower returns the baker's voting power for each started period
+// of an election. Periods where the baker was not listed are omitted.
+func (c *Client) GetVotingPower(ctx context.Context, baker tezos.Address, election int) ([]VotingPower, error) {
+	e, err := c.GetElection(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]VotingPower, 0)
+	for _, kind := range tezos.VotingPeriods {
+		p := e.Period(kind)
+		if p == nil || p.StartHeight <= 0 {
+			continue
+		}
+		q := c.NewSnapshotQuery()
+		q.WithColumns("height", "rolls", "balance", "delegated").
+			WithFilter(FilterModeEqual, "height", p.StartHeight-1).
+			WithFilter(FilterModeEqual, "address", baker).
+			WithLimit(1)
+		snaps, err := q.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if snaps.Len() == 0 {
+			continue
+		}
+		s := snaps.Rows[0]
+		res = append(res, VotingPower{
+			Baker:            baker,
+			ElectionId:       e.Id,
+			VotingPeriod:     p.VotingPeriod,
+			VotingPeriodKind: kind,
+			SnapshotHeight:   s.Height,
+			Rolls:            s.Rolls,
+			Stake:            s.Balance + s.Delegated,
+		})
+	}
+	return res, nil
+}